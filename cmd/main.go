@@ -1,32 +1,92 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	_ "github.com/joho/godotenv/autoload"
 	server "github.com/metoro-io/mcp-golang"
+	"github.com/metoro-io/mcp-golang/transport"
 	"github.com/metoro-io/mcp-golang/transport/stdio"
 
+	"github.com/thunderjr/openfinance-mcp-server/internal/cli"
+	"github.com/thunderjr/openfinance-mcp-server/internal/config"
+	"github.com/thunderjr/openfinance-mcp-server/internal/mcp/prompts"
+	"github.com/thunderjr/openfinance-mcp-server/internal/mcp/resources"
 	"github.com/thunderjr/openfinance-mcp-server/internal/mcp/tools"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/audit"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/connect"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/encryption"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/health"
 	"github.com/thunderjr/openfinance-mcp-server/internal/provider/logger"
 	"github.com/thunderjr/openfinance-mcp-server/internal/provider/mcp"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/metrics"
 	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pluggy"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pseudonym"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/redact"
 	"github.com/thunderjr/openfinance-mcp-server/internal/provider/redis"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/tracing"
 )
 
 func main() {
-	handleErr("logger.Init", logger.Init("openfinance-mcp.log"))
+	if args := os.Args[1:]; cli.IsCommand(args) {
+		// Commands send the log to its file, errors are for the terminal.
+		if err := cli.Run(args); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	cfg, err := config.Load(flag.CommandLine, os.Args[1:])
+	handleErr("Config", err)
+	handleErr("Config", cfg.Validate())
+
+	handleErr("logger.Init", logger.Init(cfg.LogOptions()))
 	defer logger.Close()
 
-	pluggyClient := pluggy.NewClient(pluggy.NewAuth(redis.Instance()))
+	if cfg.File != "" {
+		logger.Info("Loaded configuration", "file", cfg.File)
+	}
+
+	keyring, err := encryption.Load(cfg.Encryption.KeyFile, cfg.Encryption.Key)
+	handleErr("Encryption Keys", err)
+	if keyring == nil {
+		logger.Warn("No encryption key configured, cached credentials are stored in plaintext")
+	}
+
+	redactionPolicy, err := redact.NewPolicy(cfg.Redaction.Preset, cfg.Redaction.Rules, cfg.Redaction.Salt)
+	handleErr("Redaction Policy", err)
+	redact.SetPolicy(redactionPolicy)
 
+	auditLog := audit.Open(cfg.AuditOptions())
+	defer auditLog.Close()
+
+	tracing.Init(cfg.TracingOptions())
+	defer tracing.Shutdown()
+
+	if cfg.Pseudonyms {
+		pseudonym.Enable()
+		logger.Info("Identifier pseudonyms enabled")
+	}
+
+	redis.Configure(cfg.RedisOptions())
+	pluggyClient := pluggy.NewClient(pluggy.NewAuth(redis.Instance(), keyring), cfg.PluggyOptions())
+	if err := pluggyClient.StartAuthorization(context.Background()); err != nil {
+		logger.Warn("Starting without Pluggy, tools will fail until authorization succeeds", "error", err)
+	}
+
+	waitItemUpdatedTool := tools.NewPluggyWaitItemUpdatedTool(pluggyClient)
 	toolRegistry := mcp.NewToolRegistry(
 		tools.NewPluggyApiKeyTool(pluggyClient),
 		tools.NewPluggyConnectTokenTool(pluggyClient),
-		tools.NewPluggyWaitItemUpdatedTool(pluggyClient),
+		waitItemUpdatedTool,
 		tools.NewPluggyAccountsTool(pluggyClient),
 		tools.NewPluggyAccountTool(pluggyClient),
 		tools.NewPluggyTransactionsTool(pluggyClient),
@@ -34,12 +94,77 @@ func main() {
 		tools.NewPluggyItemTool(pluggyClient),
 		tools.NewPluggyBillsTool(pluggyClient),
 		tools.NewPluggyBillTool(pluggyClient),
+		tools.NewPluggyKnownItemsTool(pluggyClient),
+		tools.NewServerHealthTool(health.NewChecker(cfg.PluggyOptions(), redis.Instance(), pluggyClient)),
+	)
+
+	if cfg.Connect.Addr != "" {
+		connectHost := connect.NewServer(pluggyClient, cfg.Connect.Addr, cfg.Connect.PublicURL)
+		handleErr("Connect Widget Host", connectHost.Start())
+
+		toolRegistry.Add(
+			tools.NewPluggyConnectURLTool(connectHost),
+			tools.NewPluggyConnectStatusTool(connectHost),
+		)
+	}
+
+	toolPolicy, err := mcp.NewToolPolicy(cfg.Tools.Profile, cfg.Tools.Allow, cfg.Tools.Deny)
+	handleErr("Tool Policy", err)
+	toolRegistry.SetPolicy(toolPolicy)
+	waitItemUpdatedTool.SetConnectURL(toolRegistry.Enabled("pluggy_connect_url"))
+
+	toolMetrics := mcp.NewToolMetrics()
+	toolRegistry.Use(
+		mcp.Trace(),
+		mcp.LogCalls(),
+		mcp.Metrics(toolMetrics),
+		mcp.Timeout(time.Duration(cfg.Tools.Timeout)),
+		mcp.Recover(),
+	)
+
+	if cfg.Metrics.Addr != "" {
+		handleErr("Metrics Endpoint", metrics.Serve(cfg.Metrics.Addr))
+	}
+
+	resourceRegistry := mcp.NewResourceRegistry(
+		resources.NewItemResource(pluggyClient),
+		resources.NewItemAccountsResource(pluggyClient),
+		resources.NewAccountResource(pluggyClient),
+		resources.NewAccountTransactionsResource(pluggyClient),
+		resources.NewAccountBillsResource(pluggyClient),
+	)
+	resourceRegistry.SetPolicy(toolRegistry.Enabled)
+
+	promptRegistry := mcp.NewPromptRegistry(
+		prompts.NewMonthlySpendingReviewPrompt(),
+		prompts.NewCreditCardBillAuditPrompt(),
+		prompts.NewInvestmentPortfolioCheckupPrompt(),
+		prompts.NewConnectNewBankPrompt(),
 	)
 
 	logger.Info("Starting OpenFinance MCP Server")
 
-	server := server.NewServer(stdio.NewStdioServerTransport())
+	var serverTransport transport.Transport
+	switch cfg.Transport.Mode {
+	case "stdio":
+		serverTransport = stdio.NewStdioServerTransport()
+	case "http":
+		httpOpts := cfg.HTTPOptions()
+		if httpOpts.AuthToken == "" {
+			logger.Warn("HTTP transport started without -auth-token, any client that can reach it can read your financial data", "addr", httpOpts.Addr)
+		}
+		serverTransport = mcp.NewHTTPTransport(httpOpts)
+	}
+
+	serverTransport = promptRegistry.Transport(serverTransport)
+	serverTransport = mcp.AuditTransport(serverTransport, auditLog)
+	serverTransport = resourceRegistry.Transport(serverTransport)
+	serverTransport = toolRegistry.Transport(serverTransport)
+
+	server := server.NewServer(serverTransport)
 	handleErr("Tools Registration", toolRegistry.Register(server))
+	handleErr("Resources Registration", resourceRegistry.Register(server))
+	handleErr("Prompts Registration", promptRegistry.Register(server))
 	handleErr("Server Startup", server.Serve())
 
 	sig := make(chan os.Signal, 1)
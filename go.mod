@@ -5,12 +5,16 @@ go 1.23.4
 toolchain go1.23.9
 
 require (
+	github.com/alicebob/miniredis/v2 v2.34.0
 	github.com/metoro-io/mcp-golang v0.12.0
 	github.com/redis/go-redis/v9 v9.8.0
 	github.com/shopspring/decimal v1.4.0
+	gopkg.in/natefinch/lumberjack.v2 v2.2.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
+	github.com/alicebob/gopher-json v0.0.0-20230218143504-906a9b012302 // indirect
 	github.com/bahlo/generic-list-go v0.2.0 // indirect
 	github.com/buger/jsonparser v1.1.1 // indirect
 	github.com/invopop/jsonschema v0.12.0 // indirect
@@ -21,7 +25,7 @@ require (
 	github.com/tidwall/pretty v1.2.1 // indirect
 	github.com/tidwall/sjson v1.2.5 // indirect
 	github.com/wk8/go-ordered-map/v2 v2.1.8 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	github.com/yuin/gopher-lua v1.1.1 // indirect
 )
 
 require (
@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/mcp/output"
+)
+
+func accountsCommand(args []string) error {
+	return subcommand("accounts", []command{
+		{"list", "List the accounts of an item", accountsList},
+	}, args)
+}
+
+func accountsList(args []string) error {
+	cmd := newPluggyCommand("accounts list")
+	itemID := cmd.fs.String("item", "", "ID of the item whose accounts are listed")
+
+	client, format, err := cmd.client(args)
+	if err != nil {
+		return err
+	}
+	if err := required(cmd.fs, map[string]string{"item": *itemID}); err != nil {
+		return err
+	}
+
+	accounts, err := client.GetAccounts(context.Background(), *itemID)
+	if err != nil {
+		return err
+	}
+	return printList(format, output.Accounts, accounts)
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/config"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/audit"
+)
+
+func auditLog(args []string) error {
+	fs := flag.NewFlagSet("audit", flag.ContinueOnError)
+	path := fs.String("file", "", "Audit log to read, rotated files included (default audit.file of the configuration)")
+	since := fs.String("since", "", "Only calls from this time on: a date (2006-01-02), an RFC 3339 time, a duration ago (24h) or today/yesterday")
+	until := fs.String("until", "", "Only calls before this time, in the same formats as -since")
+	tool := fs.String("tool", "", "Only calls to this tool")
+	session := fs.String("session", "", "Only calls from this MCP session")
+	outcome := fs.String("outcome", "", "Only calls with this outcome: ok or error")
+	asJSON := fs.Bool("json", false, "Print the matching entries as JSON lines")
+
+	cfg, err := config.Load(fs, args)
+	if err != nil {
+		return err
+	}
+	if *path == "" {
+		*path = cfg.Audit.File
+	}
+
+	q := audit.Query{Tool: *tool, Session: *session, Outcome: audit.Outcome(*outcome)}
+	if q.Outcome != "" && q.Outcome != audit.OutcomeOK && q.Outcome != audit.OutcomeError {
+		return fmt.Errorf("invalid -outcome %q, expected ok or error", *outcome)
+	}
+
+	if q.Since, err = parseTime(*since, false); err != nil {
+		return fmt.Errorf("invalid -since: %w", err)
+	}
+	if q.Until, err = parseTime(*until, true); err != nil {
+		return fmt.Errorf("invalid -until: %w", err)
+	}
+
+	entries, err := audit.Read(*path, q)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		for _, entry := range entries {
+			if err := enc.Encode(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tTOOL\tSESSION\tOUTCOME\tLATENCY\tBYTES\tENDPOINTS\tARGUMENTS")
+	for _, e := range entries {
+		result := string(e.Outcome)
+		if e.ErrorCode != "" {
+			result += " (" + e.ErrorCode + ")"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%dms\t%d\t%s\t%s\n",
+			e.Time.Local().Format(time.DateTime), e.Tool, e.Session, result,
+			e.LatencyMS, e.ResultBytes, strings.Join(e.Endpoints, ", "), e.Arguments)
+	}
+	return w.Flush()
+}
+
+// parseTime reads a point in time of the audit flags. Dates mean their start,
+// or their end when end is set, so -until 2006-01-02 includes that day.
+func parseTime(value string, end bool) (time.Time, error) {
+	y, m, d := time.Now().Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+
+	day := func(t time.Time) time.Time {
+		if end {
+			return t.AddDate(0, 0, 1)
+		}
+		return t
+	}
+
+	switch value {
+	case "":
+		return time.Time{}, nil
+	case "today":
+		return day(today), nil
+	case "yesterday":
+		return day(today.AddDate(0, 0, -1)), nil
+	}
+
+	if d, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return day(t), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not a date, an RFC 3339 time or a duration", value)
+}
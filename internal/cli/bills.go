@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/mcp/output"
+)
+
+func billsCommand(args []string) error {
+	return subcommand("bills", []command{
+		{"list", "List the bills of a credit card account", billsList},
+		{"show", "Show a bill", billsShow},
+	}, args)
+}
+
+func billsList(args []string) error {
+	cmd := newPluggyCommand("bills list")
+	accountID := cmd.fs.String("account", "", "ID of the credit card account whose bills are listed")
+
+	client, format, err := cmd.client(args)
+	if err != nil {
+		return err
+	}
+	if err := required(cmd.fs, map[string]string{"account": *accountID}); err != nil {
+		return err
+	}
+
+	bills, err := client.GetBills(context.Background(), *accountID)
+	if err != nil {
+		return err
+	}
+	return printList(format, output.Bills, bills)
+}
+
+func billsShow(args []string) error {
+	cmd := newPluggyCommand("bills show")
+	billID := cmd.fs.String("bill", "", "ID of the bill to show")
+
+	client, format, err := cmd.client(args)
+	if err != nil {
+		return err
+	}
+	if err := required(cmd.fs, map[string]string{"bill": *billID}); err != nil {
+		return err
+	}
+
+	bill, err := client.GetBill(context.Background(), *billID)
+	if err != nil {
+		return err
+	}
+	return printOne(format, output.Bills, bill)
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{"migrate-encryption", "Re-encrypt cached entries with the primary encryption key", migrateEncryption},
+	{"audit", "Query the audit log of tool calls", auditLog},
+	{"config", "Print the effective configuration (config print), secrets masked", configCommand},
+	{"doctor", "Check the configuration, Pluggy, Redis and the connected banks", doctor},
+	{"items", "List registered items (items list) or refresh one (items refresh)", itemsCommand},
+	{"accounts", "List the accounts of an item (accounts list)", accountsCommand},
+	{"transactions", "List the transactions of an account (transactions list)", transactionsCommand},
+	{"bills", "List the bills of an account (bills list) or show one (bills show)", billsCommand},
+	{"investments", "List the investments of an item (investments list)", investmentsCommand},
+}
+
+// IsCommand reports whether args select a subcommand instead of starting the
+// MCP server.
+func IsCommand(args []string) bool {
+	return len(args) > 0 && !strings.HasPrefix(args[0], "-")
+}
+
+func Run(args []string) error {
+	name := args[0]
+	if name == "help" {
+		printUsage()
+		return nil
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd.run(args[1:])
+		}
+	}
+
+	printUsage()
+	return fmt.Errorf("unknown command %q", name)
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: openfinance-mcp-server [command]")
+	fmt.Fprintln(os.Stderr, "\nWithout a command the MCP server is started.\n\nCommands:")
+
+	w := tabwriter.NewWriter(os.Stderr, 0, 0, 2, ' ', 0)
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %s\t%s\n", cmd.name, cmd.usage)
+	}
+	w.Flush()
+}
@@ -0,0 +1,263 @@
+package cli
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/cassette"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pluggy"
+)
+
+type pluggyResponses map[string]string // request URL -> JSON body
+
+func (r pluggyResponses) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, ok := r[req.URL.String()]
+	status := http.StatusOK
+	if !ok {
+		body, status = `{"code":404,"message":"not found"}`, http.StatusNotFound
+	}
+	return &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+}
+
+// replayConfig records responses into a cassette and returns the flags that
+// make the commands replay it instead of calling Pluggy.
+func replayConfig(t *testing.T, responses pluggyResponses) []string {
+	t.Helper()
+	dir := t.TempDir()
+	recorder := cassette.NewTransport(cassette.ModeRecord, filepath.Join(dir, "cassettes"), responses)
+	for url := range responses {
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		res, err := recorder.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	}
+
+	for _, name := range []string{"OPENFINANCE_CONFIG", "OPENFINANCE_TRANSPORT", "PLUGGY_CASSETTE_MODE", "PLUGGY_CASSETTE_DIR", "OPENFINANCE_LOG_FILE", "OPENFINANCE_ENCRYPTION_KEY", "OPENFINANCE_ENCRYPTION_KEY_FILE"} {
+		t.Setenv(name, "")
+	}
+
+	path := filepath.Join(dir, "config.yaml")
+	config := "pluggy:\n  cassette_mode: replay\n  cassette_dir: " + filepath.Join(dir, "cassettes") + "\nlog:\n  file: stderr\n  level: error\n"
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return []string{"-config", path}
+}
+
+// stdout returns what run writes to the standard output.
+func stdout(t *testing.T, run func() error) (string, error) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = previous }()
+
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+
+	err = run()
+	w.Close()
+	return <-done, err
+}
+
+func TestDataCommands(t *testing.T) {
+	config := replayConfig(t, pluggyResponses{
+		"https://api.pluggy.ai/accounts?itemId=item-1":              `{"page":1,"totalPages":1,"total":1,"results":[{"id":"acc-1","type":"BANK","subtype":"CHECKING_ACCOUNT","balance":1500.5,"currencyCode":"BRL"}]}`,
+		"https://api.pluggy.ai/transactions?accountId=acc-1&page=1": `{"page":1,"totalPages":2,"total":2,"results":[{"id":"tx-1","date":"2024-03-01T00:00:00Z","amount":-10,"currencyCode":"BRL","type":"DEBIT"}]}`,
+		"https://api.pluggy.ai/transactions?accountId=acc-1&page=2": `{"page":2,"totalPages":2,"total":2,"results":[{"id":"tx-2","date":"2024-03-02T00:00:00Z","amount":20,"currencyCode":"BRL","type":"CREDIT"}]}`,
+		"https://api.pluggy.ai/bills/bill-1":                        `{"id":"bill-1","dueDate":"2024-04-10T00:00:00Z","totalAmount":300,"totalAmountCurrencyCode":"BRL"}`,
+	})
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string // in the output
+		notWant []string
+		wantErr string
+	}{
+		{
+			name: "accounts list",
+			args: []string{"accounts", "list", "-item", "item-1", "-format", "csv"},
+			want: []string{"id,name,type,subtype,number,balance,currencyCode", "acc-1,,BANK,CHECKING_ACCOUNT,,1500.5,BRL"},
+		},
+		{
+			name:    "transactions first page",
+			args:    []string{"transactions", "list", "-account", "acc-1", "-format", "csv"},
+			want:    []string{"tx-1"},
+			notWant: []string{"tx-2"},
+		},
+		{
+			name: "transactions every page",
+			args: []string{"transactions", "list", "-account", "acc-1", "-all", "-format", "csv"},
+			want: []string{"tx-1", "tx-2"},
+		},
+		{
+			name: "bill as json",
+			args: []string{"bills", "show", "-bill", "bill-1"},
+			want: []string{`"id": "bill-1"`, `"totalAmount": 300`},
+		},
+		{
+			name:    "missing flag",
+			args:    []string{"accounts", "list"},
+			wantErr: "accounts list: -item is required",
+		},
+		{
+			name:    "invalid date",
+			args:    []string{"transactions", "list", "-account", "acc-1", "-from", "March"},
+			wantErr: "invalid -from",
+		},
+		{
+			name:    "unknown format",
+			args:    []string{"accounts", "list", "-item", "item-1", "-format", "xml"},
+			wantErr: "xml",
+		},
+		{
+			name:    "not recorded",
+			args:    []string{"bills", "show", "-bill", "bill-2"},
+			wantErr: "no recorded interaction",
+		},
+		{
+			name:    "unknown subcommand",
+			args:    []string{"bills", "pay"},
+			wantErr: "usage: bills list|show [flags]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append(append(tt.args[:2:2], config...), tt.args[2:]...)
+			out, err := stdout(t, func() error { return Run(args) })
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("output misses %q:\n%s", want, out)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(out, notWant) {
+					t.Errorf("output has %q:\n%s", notWant, out)
+				}
+			}
+		})
+	}
+}
+
+func TestIsCommand(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{nil, false},
+		{[]string{"-transport", "http"}, false},
+		{[]string{"doctor"}, true},
+		{[]string{"items", "list"}, true},
+	}
+	for _, tt := range tests {
+		if got := IsCommand(tt.args); got != tt.want {
+			t.Errorf("IsCommand(%v) = %t, want %t", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestRunUnknownCommand(t *testing.T) {
+	if err := Run([]string{"serve"}); err == nil || err.Error() != `unknown command "serve"` {
+		t.Errorf("Run(serve) = %v", err)
+	}
+}
+
+func TestFetchPages(t *testing.T) {
+	pages := map[int][]string{1: {"a", "b"}, 2: {"c"}, 3: {"d"}}
+	fetch := func(fail int) func(page int) (*pluggy.PaginatedResponse[string], error) {
+		return func(page int) (*pluggy.PaginatedResponse[string], error) {
+			if page == fail {
+				return nil, errors.New("page failed")
+			}
+			return &pluggy.PaginatedResponse[string]{Page: float64(page), TotalPages: 3, Results: pages[page]}, nil
+		}
+	}
+
+	tests := []struct {
+		name    string
+		all     bool
+		page    int
+		fail    int
+		want    string
+		wantErr bool
+	}{
+		{name: "one page", page: 2, want: "c"},
+		{name: "every page", all: true, page: 1, want: "a,b,c,d"},
+		{name: "every page from the second", all: true, page: 2, want: "c,d"},
+		{name: "failed page", all: true, page: 1, fail: 3, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fetchPages(tt.all, tt.page, fetch(tt.fail))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("fetchPages() = %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(got.Results, ",") != tt.want {
+				t.Errorf("results = %v, want %s", got.Results, tt.want)
+			}
+			if tt.all && (got.Page != 1 || got.TotalPages != 1) {
+				t.Errorf("merged page = %v of %v, want 1 of 1", got.Page, got.TotalPages)
+			}
+		})
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	y, m, d := time.Now().Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		value   string
+		end     bool
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "", want: time.Time{}},
+		{value: "today", want: today},
+		{value: "today", end: true, want: today.AddDate(0, 0, 1)},
+		{value: "yesterday", want: today.AddDate(0, 0, -1)},
+		{value: "2024-03-01", end: true, want: time.Date(2024, 3, 2, 0, 0, 0, 0, time.Local)},
+		{value: "2024-03-01T10:00:00Z", want: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)},
+		{value: "last week", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseTime(tt.value, tt.end)
+		if (err != nil) != tt.wantErr || !got.Equal(tt.want) {
+			t.Errorf("parseTime(%q, %t) = %v, %v; want %v", tt.value, tt.end, got, err, tt.want)
+		}
+	}
+
+	if got, _ := parseTime("24h", false); time.Since(got) < 24*time.Hour || time.Since(got) > 25*time.Hour {
+		t.Errorf("parseTime(24h) = %v, want a day ago", got)
+	}
+}
@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/config"
+)
+
+func configCommand(args []string) error {
+	if len(args) == 0 || args[0] != "print" {
+		return fmt.Errorf("usage: config print [flags]")
+	}
+
+	fs := flag.NewFlagSet("config print", flag.ContinueOnError)
+	cfg, err := config.Load(fs, args[1:])
+	if err != nil {
+		return err
+	}
+
+	if err := cfg.Print(os.Stdout); err != nil {
+		return err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return nil
+}
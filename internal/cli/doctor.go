@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/config"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/encryption"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/health"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/logger"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pluggy"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/redis"
+)
+
+func doctor(args []string) error {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "Print the report as JSON")
+
+	cfg, err := config.Load(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := logger.Init(cfg.LogOptions()); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	checks := []health.Check{configCheck(cfg)}
+
+	redis.Configure(cfg.RedisOptions())
+	// The credentials check authorizes the client.
+	var client *pluggy.Client
+	if keyring, err := encryption.Load(cfg.Encryption.KeyFile, cfg.Encryption.Key); err != nil {
+		checks = append(checks, health.Check{
+			Name:    "encryption",
+			Status:  health.StatusFail,
+			Message: err.Error(),
+			Hint:    "Fix OPENFINANCE_ENCRYPTION_KEY or OPENFINANCE_ENCRYPTION_KEY_FILE (encryption.* in the configuration file); Pluggy is not checked without it",
+		})
+	} else {
+		client = pluggy.NewClient(pluggy.NewAuth(redis.Instance(), keyring), cfg.PluggyOptions())
+	}
+
+	checker := health.NewChecker(cfg.PluggyOptions(), redis.Instance(), client)
+	checks = append(checks, checker.Credentials(ctx), checker.Redis(ctx), checker.ApiKey(ctx), checker.RateLimit())
+	checks = append(checks, checker.Items(ctx)...)
+
+	report := health.NewReport(checks...)
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else if err := printReport(report); err != nil {
+		return err
+	}
+
+	if report.Status == health.StatusFail {
+		return errors.New("doctor: some checks failed")
+	}
+	return nil
+}
+
+func configCheck(cfg *config.Config) health.Check {
+	check := health.Check{Name: "configuration", Status: health.StatusPass, Message: "No configuration file, defaults and environment only"}
+	if cfg.File != "" {
+		check.Message = "Loaded " + cfg.File
+	}
+	if err := cfg.Validate(); err != nil {
+		check.Status = health.StatusFail
+		check.Message = strings.ReplaceAll(err.Error(), "\n", "; ")
+		check.Hint = "Run config print to see the effective configuration"
+	}
+	return check
+}
+
+func printReport(report health.Report) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, check := range report.Checks {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", strings.ToUpper(string(check.Status)), check.Name, check.Message)
+		if check.Hint != "" {
+			fmt.Fprintf(w, "\t\t→ %s\n", check.Hint)
+		}
+	}
+	fmt.Fprintf(w, "\nOverall: %s\n", strings.ToUpper(string(report.Status)))
+	return w.Flush()
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/health"
+)
+
+func TestDoctorReportsEncryption(t *testing.T) {
+	tests := []struct {
+		name       string
+		key        string
+		wantStatus health.Status // of the encryption check, empty when it is left out
+	}{
+		{name: "no key", key: ""},
+		{name: "invalid key", key: "not-a-key", wantStatus: health.StatusFail},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "config.yaml")
+			config := "pluggy:\n  cassette_mode: replay\n  cassette_dir: " + dir + "\nlog:\n  file: stderr\n  level: error\naudit:\n  file: " + filepath.Join(dir, "audit.jsonl") + "\n"
+			if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv("OPENFINANCE_ENCRYPTION_KEY_FILE", "")
+			t.Setenv("OPENFINANCE_ENCRYPTION_KEY", tt.key)
+
+			out, _ := stdout(t, func() error { return Run([]string{"doctor", "-config", path, "-json"}) })
+			var report health.Report
+			if err := json.Unmarshal([]byte(out), &report); err != nil {
+				t.Fatalf("%v in %s", err, out)
+			}
+
+			var got health.Status
+			for _, check := range report.Checks {
+				if check.Name == "encryption" {
+					got = check.Status
+				}
+			}
+			if got != tt.wantStatus {
+				t.Errorf("encryption check = %q, want %q in %+v", got, tt.wantStatus, report.Checks)
+			}
+			if tt.wantStatus == health.StatusFail && report.Status != health.StatusFail {
+				t.Errorf("report status = %s, want fail", report.Status)
+			}
+		})
+	}
+}
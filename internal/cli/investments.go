@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/mcp/output"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pluggy"
+)
+
+func investmentsCommand(args []string) error {
+	return subcommand("investments", []command{
+		{"list", "List the investments of an item", investmentsList},
+	}, args)
+}
+
+func investmentsList(args []string) error {
+	cmd := newPluggyCommand("investments list")
+	itemID := cmd.fs.String("item", "", "ID of the item whose investments are listed")
+	kind := cmd.fs.String("type", "", "Only investments of this type: COE, EQUITY, ETF, FIXED_INCOME, MUTUAL_FUND, SECURITY or OTHER")
+	page := cmd.fs.Int("page", 1, "Page to fetch")
+	pageSize := cmd.fs.Int("page-size", 0, "Results per page, up to 500 (default 20)")
+	all := cmd.fs.Bool("all", false, "Fetch every page from -page on")
+
+	client, format, err := cmd.client(args)
+	if err != nil {
+		return err
+	}
+	if err := required(cmd.fs, map[string]string{"item": *itemID}); err != nil {
+		return err
+	}
+
+	filter := pluggy.InvestmentsFilter{Type: pluggy.InvestmentType(*kind), PageSize: *pageSize}
+	investments, err := fetchPages(*all, *page, func(page int) (*pluggy.PaginatedResponse[pluggy.Investment], error) {
+		filter.Page = page
+		return client.GetInvestments(context.Background(), *itemID, &filter)
+	})
+	if err != nil {
+		return err
+	}
+	return printList(format, output.Investments, investments)
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/mcp/output"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pluggy"
+)
+
+func itemsCommand(args []string) error {
+	return subcommand("items", []command{
+		{"list", "List the items registered with this server", itemsList},
+		{"refresh", "Synchronize an item with its institution", itemsRefresh},
+	}, args)
+}
+
+func itemsList(args []string) error {
+	cmd := newPluggyCommand("items list")
+	client, format, err := cmd.client(args)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	known, err := client.KnownItems(ctx)
+	if err != nil {
+		return err
+	}
+
+	page := &pluggy.PaginatedResponse[pluggy.Item]{Page: 1, TotalPages: 1, Total: float64(len(known))}
+	for _, k := range known {
+		item, err := client.GetItem(ctx, k.ID)
+		if err != nil {
+			return fmt.Errorf("item %s: %w", k.ID, err)
+		}
+		page.Results = append(page.Results, *item)
+	}
+
+	return printList(format, output.Items, page)
+}
+
+func itemsRefresh(args []string) error {
+	cmd := newPluggyCommand("items refresh")
+	itemID := cmd.fs.String("item", "", "ID of the item to refresh")
+	wait := cmd.fs.Bool("wait", false, "Wait for the item to finish updating")
+	timeout := cmd.fs.Duration("timeout", 2*time.Minute, "How long -wait waits before giving up")
+
+	client, format, err := cmd.client(args)
+	if err != nil {
+		return err
+	}
+	if err := required(cmd.fs, map[string]string{"item": *itemID}); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	item, err := client.UpdateItem(ctx, *itemID)
+	if err != nil {
+		return err
+	}
+
+	if *wait {
+		item, err = client.WaitUpdated(ctx, *itemID, pluggy.WaitOptions{
+			Timeout: *timeout,
+			OnChange: func(item *pluggy.Item) {
+				fmt.Fprintf(os.Stderr, "%s: %s %s\n", time.Now().Format(time.TimeOnly), item.Status, item.ExecutionStatus)
+			},
+		})
+		if errors.Is(err, pluggy.ErrStillUpdating) {
+			fmt.Fprintf(os.Stderr, "still updating after %s\n", *timeout)
+		} else if err != nil {
+			return err
+		}
+	}
+
+	return printOne(format, output.Items, item)
+}
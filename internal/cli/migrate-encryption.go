@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/config"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/encryption"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pluggy"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/redis"
+)
+
+func migrateEncryption(args []string) error {
+	fs := flag.NewFlagSet("migrate-encryption", flag.ContinueOnError)
+	cfg, err := config.Load(fs, args)
+	if err != nil {
+		return err
+	}
+
+	keyring, err := encryption.Load(cfg.Encryption.KeyFile, cfg.Encryption.Key)
+	if err != nil {
+		return err
+	}
+	if keyring == nil {
+		return errors.New("set encryption.key or encryption.key_file (OPENFINANCE_ENCRYPTION_KEY or OPENFINANCE_ENCRYPTION_KEY_FILE) before migrating")
+	}
+
+	redis.Configure(cfg.RedisOptions())
+	n, err := pluggy.NewAuth(redis.Instance(), keyring).Reencrypt(context.Background())
+	if err != nil {
+		return fmt.Errorf("migrate-encryption: %w (%d entries re-encrypted before the failure)", err, n)
+	}
+
+	fmt.Printf("Re-encrypted %d entries with key %q\n", n, keyring.PrimaryID())
+	return nil
+}
@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/config"
+	"github.com/thunderjr/openfinance-mcp-server/internal/mcp/output"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/encryption"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/logger"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pluggy"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/redis"
+)
+
+// subcommand runs the entry of subs named by args[0], for commands grouping
+// actions such as "items list" and "items refresh".
+func subcommand(name string, subs []command, args []string) error {
+	if len(args) > 0 {
+		for _, sub := range subs {
+			if sub.name == args[0] {
+				return sub.run(args[1:])
+			}
+		}
+	}
+
+	names := make([]string, len(subs))
+	for i, sub := range subs {
+		names[i] = sub.name
+	}
+	return fmt.Errorf("usage: %s %s [flags]", name, strings.Join(names, "|"))
+}
+
+// pluggyCommand holds what the data commands share: the configuration flags,
+// the output format and the Pluggy client the MCP server would use.
+type pluggyCommand struct {
+	fs     *flag.FlagSet
+	format *string
+}
+
+func newPluggyCommand(name string) *pluggyCommand {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	return &pluggyCommand{
+		fs:     fs,
+		format: fs.String("format", "json", "Output format: json, compact, csv or markdown"),
+	}
+}
+
+// client loads the configuration and builds the Pluggy client. The log never
+// goes to stdout, which only carries the data.
+func (c *pluggyCommand) client(args []string) (*pluggy.Client, output.Format, error) {
+	cfg, err := config.Load(c.fs, args)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, "", fmt.Errorf("invalid configuration:\n%w", err)
+	}
+
+	format, err := output.ParseFormat(c.format)
+	if err != nil {
+		return nil, "", err
+	}
+
+	logOpts := cfg.LogOptions()
+	if logOpts.File == "stdout" {
+		logOpts.File = "stderr"
+	}
+	if err := logger.Init(logOpts); err != nil {
+		return nil, "", err
+	}
+
+	keyring, err := encryption.Load(cfg.Encryption.KeyFile, cfg.Encryption.Key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	redis.Configure(cfg.RedisOptions())
+	client := pluggy.NewClient(pluggy.NewAuth(redis.Instance(), keyring), cfg.PluggyOptions())
+	if err := client.Authorize(context.Background()); err != nil {
+		return nil, "", err
+	}
+	return client, format, nil
+}
+
+// required checks that the flags identifying what to fetch were given.
+func required(fs *flag.FlagSet, values map[string]string) error {
+	for name, value := range values {
+		if value == "" {
+			return fmt.Errorf("%s: -%s is required", fs.Name(), name)
+		}
+	}
+	return nil
+}
+
+// fetchPages returns the page fetch gets for page, or every page from it on
+// merged into one when all is set.
+func fetchPages[T any](all bool, page int, fetch func(page int) (*pluggy.PaginatedResponse[T], error)) (*pluggy.PaginatedResponse[T], error) {
+	first, err := fetch(page)
+	if err != nil || !all {
+		return first, err
+	}
+
+	for p := max(page, 1) + 1; float64(p) <= first.TotalPages; p++ {
+		next, err := fetch(p)
+		if err != nil {
+			return nil, err
+		}
+		first.Results = append(first.Results, next.Results...)
+	}
+	first.Page, first.TotalPages = 1, 1
+	return first, nil
+}
+
+// printList writes a page in the format of the MCP tools.
+func printList[T any](format output.Format, entity output.Entity[T], page *pluggy.PaginatedResponse[T]) error {
+	text, err := output.List(format, entity, page, output.Window{})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(os.Stdout, strings.TrimSuffix(text, "\n"))
+	return err
+}
+
+// printOne writes a single entity: the full payload as indented JSON, or its
+// key fields as a one-row table in the other formats.
+func printOne[T any](format output.Format, entity output.Entity[T], v *T) error {
+	if format == output.FormatJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	return printList(format, entity, &pluggy.PaginatedResponse[T]{
+		Page:       1,
+		Total:      1,
+		TotalPages: 1,
+		Results:    []T{*v},
+	})
+}
@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/mcp/output"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pluggy"
+)
+
+func transactionsCommand(args []string) error {
+	return subcommand("transactions", []command{
+		{"list", "List the transactions of an account", transactionsList},
+	}, args)
+}
+
+func transactionsList(args []string) error {
+	cmd := newPluggyCommand("transactions list")
+	accountID := cmd.fs.String("account", "", "ID of the account whose transactions are listed")
+	from := cmd.fs.String("from", "", "Only transactions from this date (2006-01-02)")
+	to := cmd.fs.String("to", "", "Only transactions up to this date (2006-01-02)")
+	page := cmd.fs.Int("page", 1, "Page to fetch")
+	pageSize := cmd.fs.Int("page-size", 0, "Results per page, up to 500 (default 20)")
+	all := cmd.fs.Bool("all", false, "Fetch every page from -page on")
+
+	client, format, err := cmd.client(args)
+	if err != nil {
+		return err
+	}
+	if err := required(cmd.fs, map[string]string{"account": *accountID}); err != nil {
+		return err
+	}
+
+	filter := pluggy.TransactionFilter{PageSize: *pageSize}
+	if *from != "" {
+		if filter.From, err = time.Parse(time.DateOnly, *from); err != nil {
+			return fmt.Errorf("invalid -from: %w", err)
+		}
+	}
+	if *to != "" {
+		if filter.To, err = time.Parse(time.DateOnly, *to); err != nil {
+			return fmt.Errorf("invalid -to: %w", err)
+		}
+	}
+
+	transactions, err := fetchPages(*all, *page, func(page int) (*pluggy.PaginatedResponse[pluggy.Transaction], error) {
+		filter.Page = page
+		return client.GetTransactions(context.Background(), *accountID, &filter)
+	})
+	if err != nil {
+		return err
+	}
+	return printList(format, output.Transactions, transactions)
+}
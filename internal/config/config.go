@@ -0,0 +1,313 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/audit"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/cassette"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/encryption"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/logger"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/mcp"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pluggy"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/redact"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/redis"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/tracing"
+)
+
+// Config is everything the server can be configured with. Fields tagged
+// secret are masked when the configuration is printed.
+type Config struct {
+	Transport  Transport  `yaml:"transport"`
+	Pluggy     Pluggy     `yaml:"pluggy"`
+	Redis      Redis      `yaml:"redis"`
+	Log        Log        `yaml:"log"`
+	Encryption Encryption `yaml:"encryption"`
+	Redaction  Redaction  `yaml:"redaction"`
+	Pseudonyms bool       `yaml:"pseudonyms"`
+	Tools      Tools      `yaml:"tools"`
+	Audit      Audit      `yaml:"audit"`
+	Connect    Connect    `yaml:"connect"`
+	Metrics    Metrics    `yaml:"metrics"`
+	Tracing    Tracing    `yaml:"tracing"`
+
+	// File is the configuration file that was loaded, if any.
+	File string `yaml:"-"`
+}
+
+type Transport struct {
+	Mode string `yaml:"mode"` // stdio or http
+	HTTP HTTP   `yaml:"http"`
+}
+
+type HTTP struct {
+	Addr      string `yaml:"addr"`
+	TLSCert   string `yaml:"tls_cert"`
+	TLSKey    string `yaml:"tls_key"`
+	AuthToken string `yaml:"auth_token" secret:"true"`
+}
+
+type Pluggy struct {
+	ClientID     string `yaml:"client_id"`
+	ClientSecret string `yaml:"client_secret" secret:"true"`
+	CassetteMode string `yaml:"cassette_mode"` // empty, record or replay
+	CassetteDir  string `yaml:"cassette_dir"`
+}
+
+type Redis struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Password string `yaml:"password" secret:"true"`
+	DB       int    `yaml:"db"`
+}
+
+type Log struct {
+	Level      string `yaml:"level"`  // debug, info, warn or error
+	Format     string `yaml:"format"` // text or json
+	File       string `yaml:"file"`   // a file, or stderr or stdout
+	MaxSizeMB  int    `yaml:"max_size_mb"`
+	MaxAgeDays int    `yaml:"max_age_days"`
+	MaxBackups int    `yaml:"max_backups"`
+}
+
+type Encryption struct {
+	Key     string `yaml:"key" secret:"true"`
+	KeyFile string `yaml:"key_file"`
+}
+
+type Redaction struct {
+	Preset string `yaml:"preset"` // defaults to strict for http and standard otherwise
+	Rules  string `yaml:"rules"`
+	Salt   string `yaml:"salt" secret:"true"`
+}
+
+type Tools struct {
+	Profile string   `yaml:"profile"`
+	Allow   []string `yaml:"allow"`
+	Deny    []string `yaml:"deny"`
+	Timeout Duration `yaml:"timeout"`
+}
+
+type Audit struct {
+	Enabled    bool   `yaml:"enabled"`
+	File       string `yaml:"file"`
+	MaxSizeMB  int    `yaml:"max_size_mb"`
+	MaxAgeDays int    `yaml:"max_age_days"`
+	MaxBackups int    `yaml:"max_backups"`
+}
+
+type Connect struct {
+	Addr      string `yaml:"addr"`       // empty disables the local Pluggy Connect host
+	PublicURL string `yaml:"public_url"` // base of the links handed out, when not the listener itself
+}
+
+type Metrics struct {
+	Addr string `yaml:"addr"` // empty disables the Prometheus /metrics endpoint
+}
+
+type Tracing struct {
+	Endpoint    string `yaml:"endpoint"` // OTLP/HTTP collector; empty disables tracing
+	ServiceName string `yaml:"service_name"`
+}
+
+// Duration is a time.Duration written as "90s" or "2m" in the file.
+type Duration time.Duration
+
+func (d Duration) MarshalYAML() (any, error) {
+	return time.Duration(d).String(), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func Default() *Config {
+	return &Config{
+		Transport: Transport{
+			Mode: "stdio",
+			HTTP: HTTP{Addr: "127.0.0.1:8080"},
+		},
+		Pluggy: Pluggy{
+			CassetteMode: string(cassette.ModeOff),
+			CassetteDir:  "cassettes",
+		},
+		Redis: Redis{Host: "127.0.0.1", Port: 6379},
+		Log: Log{
+			Level:      "info",
+			Format:     "text",
+			File:       "openfinance-mcp.log",
+			MaxSizeMB:  100,
+			MaxAgeDays: 30,
+			MaxBackups: 5,
+		},
+		Tools: Tools{
+			Profile: mcp.DefaultToolProfile,
+			Timeout: Duration(mcp.DefaultToolTimeout),
+		},
+		Tracing: Tracing{ServiceName: "openfinance-mcp-server"},
+		Audit: Audit{
+			Enabled:    true,
+			File:       "openfinance-audit.jsonl",
+			MaxSizeMB:  100,
+			MaxAgeDays: 90,
+		},
+	}
+}
+
+// resolve fills in the values that depend on others.
+func (c *Config) resolve() {
+	if c.Redaction.Preset == "" {
+		// Shared deployments expose data to more people, so they redact it all.
+		c.Redaction.Preset = "standard"
+		if c.Transport.Mode == "http" {
+			c.Redaction.Preset = "strict"
+		}
+	}
+}
+
+// Validate reports every invalid setting at once.
+func (c *Config) Validate() error {
+	var errs []error
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	switch c.Transport.Mode {
+	case "stdio", "http":
+	default:
+		invalid("transport.mode: unknown transport %q, expected stdio or http", c.Transport.Mode)
+	}
+	if (c.Transport.HTTP.TLSCert == "") != (c.Transport.HTTP.TLSKey == "") {
+		invalid("transport.http: tls_cert and tls_key must be set together")
+	}
+
+	// Missing Pluggy credentials are not an error: the server starts without
+	// them and its tools report them missing.
+	if _, err := cassette.ParseMode(c.Pluggy.CassetteMode); err != nil {
+		invalid("pluggy.cassette_mode: %v", err)
+	}
+
+	if c.Transport.Mode == "stdio" && c.Log.File == "stdout" {
+		invalid("log.file: stdout carries the stdio transport, log to stderr or a file")
+	}
+	if _, err := logger.ParseLevel(c.Log.Level); err != nil {
+		invalid("log.level: %v", err)
+	}
+	if _, err := logger.ParseFormat(c.Log.Format); err != nil {
+		invalid("log.format: %v", err)
+	}
+	if c.Log.MaxSizeMB < 0 || c.Log.MaxAgeDays < 0 || c.Log.MaxBackups < 0 {
+		invalid("log: max_size_mb, max_age_days and max_backups cannot be negative")
+	}
+
+	if c.Redis.Port <= 0 || c.Redis.Port > 65535 {
+		invalid("redis.port: %d is not a valid port", c.Redis.Port)
+	}
+
+	if _, err := encryption.Load(c.Encryption.KeyFile, c.Encryption.Key); err != nil {
+		invalid("encryption: %v", err)
+	}
+	if _, err := redact.NewPolicy(c.Redaction.Preset, c.Redaction.Rules, c.Redaction.Salt); err != nil {
+		invalid("redaction: %v", err)
+	}
+	if _, err := mcp.NewToolPolicy(c.Tools.Profile, c.Tools.Allow, c.Tools.Deny); err != nil {
+		invalid("tools: %v", err)
+	}
+	if c.Tools.Timeout <= 0 {
+		invalid("tools.timeout: must be positive")
+	}
+
+	if c.Connect.PublicURL != "" && !strings.HasPrefix(c.Connect.PublicURL, "http://") && !strings.HasPrefix(c.Connect.PublicURL, "https://") {
+		invalid("connect.public_url: %q is not an http:// or https:// URL", c.Connect.PublicURL)
+	}
+
+	if c.Tracing.Endpoint != "" && !strings.HasPrefix(c.Tracing.Endpoint, "http://") && !strings.HasPrefix(c.Tracing.Endpoint, "https://") {
+		invalid("tracing.endpoint: %q is not an http:// or https:// URL", c.Tracing.Endpoint)
+	}
+
+	if c.Audit.Enabled && c.Audit.File == "" {
+		invalid("audit.file: required when the audit log is enabled")
+	}
+	if c.Audit.MaxSizeMB < 0 || c.Audit.MaxAgeDays < 0 || c.Audit.MaxBackups < 0 {
+		invalid("audit: max_size_mb, max_age_days and max_backups cannot be negative")
+	}
+
+	return errors.Join(errs...)
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func (c *Config) PluggyOptions() pluggy.Options {
+	return pluggy.Options{
+		ClientID:     c.Pluggy.ClientID,
+		ClientSecret: c.Pluggy.ClientSecret,
+		CassetteMode: c.Pluggy.CassetteMode,
+		CassetteDir:  c.Pluggy.CassetteDir,
+	}
+}
+
+func (c *Config) RedisOptions() redis.Options {
+	return redis.Options{
+		Host:     c.Redis.Host,
+		Port:     c.Redis.Port,
+		Password: c.Redis.Password,
+		DB:       c.Redis.DB,
+	}
+}
+
+func (c *Config) LogOptions() logger.Options {
+	return logger.Options{
+		Level:      c.Log.Level,
+		Format:     c.Log.Format,
+		File:       c.Log.File,
+		MaxSizeMB:  c.Log.MaxSizeMB,
+		MaxAgeDays: c.Log.MaxAgeDays,
+		MaxBackups: c.Log.MaxBackups,
+		Secrets:    c.secrets(),
+	}
+}
+
+func (c *Config) HTTPOptions() mcp.HTTPTransportOptions {
+	return mcp.HTTPTransportOptions{
+		Addr:      c.Transport.HTTP.Addr,
+		TLSCert:   c.Transport.HTTP.TLSCert,
+		TLSKey:    c.Transport.HTTP.TLSKey,
+		AuthToken: c.Transport.HTTP.AuthToken,
+	}
+}
+
+func (c *Config) TracingOptions() tracing.Options {
+	return tracing.Options{
+		Endpoint:    c.Tracing.Endpoint,
+		ServiceName: c.Tracing.ServiceName,
+	}
+}
+
+// AuditOptions returns options with an empty path when the audit log is
+// disabled.
+func (c *Config) AuditOptions() audit.Options {
+	opts := audit.Options{
+		MaxSizeMB:  c.Audit.MaxSizeMB,
+		MaxAgeDays: c.Audit.MaxAgeDays,
+		MaxBackups: c.Audit.MaxBackups,
+	}
+	if c.Audit.Enabled {
+		opts.Path = c.Audit.File
+	}
+	return opts
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(c *Config)
+		wantErr []string
+	}{
+		{name: "defaults", change: func(*Config) {}},
+		{
+			name:    "transport",
+			change:  func(c *Config) { c.Transport.Mode = "grpc"; c.Transport.HTTP.TLSCert = "cert.pem" },
+			wantErr: []string{`unknown transport "grpc"`, "tls_cert and tls_key must be set together"},
+		},
+		{
+			name:    "stdout with stdio",
+			change:  func(c *Config) { c.Log.File = "stdout" },
+			wantErr: []string{"stdout carries the stdio transport"},
+		},
+		{
+			name:    "every error at once",
+			change:  func(c *Config) { c.Redis.Port = 0; c.Tools.Timeout = 0; c.Tools.Profile = "root"; c.Audit.File = "" },
+			wantErr: []string{"redis.port: 0", "tools.timeout", "tools:", "audit.file"},
+		},
+		{
+			name:    "urls",
+			change:  func(c *Config) { c.Connect.PublicURL = "example.com"; c.Tracing.Endpoint = "collector:4318" },
+			wantErr: []string{"connect.public_url", "tracing.endpoint"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := resolved(Default())
+			tt.change(cfg)
+			err := cfg.Validate()
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Errorf("Validate() = %v, want no error", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate() = nil, want %v", tt.wantErr)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Validate() = %v, want it to mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPrintMasksSecrets(t *testing.T) {
+	cfg := Default()
+	cfg.Pluggy.ClientID = "client-id"
+	cfg.Pluggy.ClientSecret = "client-secret"
+	cfg.Redis.Password = "redis-password"
+
+	var out strings.Builder
+	if err := cfg.Print(&out); err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"client-secret", "redis-password"} {
+		if strings.Contains(out.String(), secret) {
+			t.Errorf("Print() shows %q:\n%s", secret, out.String())
+		}
+	}
+	if !strings.Contains(out.String(), "client_id: client-id") || !strings.Contains(out.String(), "timeout: 1m0s") {
+		t.Errorf("Print() = %s, want the other values", out.String())
+	}
+	if cfg.Pluggy.ClientSecret != "client-secret" {
+		t.Errorf("Print() changed the configuration")
+	}
+}
@@ -0,0 +1,220 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Load builds the configuration from, in increasing order of precedence, the
+// defaults, the configuration file, environment variables and the flags set
+// in args. It registers its flags on fs, so commands accept them too.
+//
+// The file is the one given by -config or OPENFINANCE_CONFIG, or else the
+// first of ./openfinance.yaml and <user config dir>/openfinance-mcp/config.yaml
+// that exists.
+func Load(fs *flag.FlagSet, args []string) (*Config, error) {
+	file := fs.String("config", "", "Configuration file (default $OPENFINANCE_CONFIG, ./openfinance.yaml or <user config dir>/openfinance-mcp/config.yaml)")
+	flags := map[string]*string{
+		"transport":  fs.String("transport", "", "MCP transport: stdio or http"),
+		"addr":       fs.String("addr", "", "Bind address for the http transport"),
+		"tls-cert":   fs.String("tls-cert", "", "TLS certificate file for the http transport"),
+		"tls-key":    fs.String("tls-key", "", "TLS key file for the http transport"),
+		"auth-token": fs.String("auth-token", "", "Bearer token required by the http transport (default $MCP_AUTH_TOKEN)"),
+		"log-file":   fs.String("log-file", "", "Log file, or stderr or stdout (default $OPENFINANCE_LOG_FILE or openfinance-mcp.log)"),
+		"log-level":  fs.String("log-level", "", "Lowest level logged: debug, info, warn or error (default $OPENFINANCE_LOG_LEVEL or info)"),
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	cfg := Default()
+
+	path, explicit := *file, *file != ""
+	if !explicit {
+		path, explicit = os.LookupEnv("OPENFINANCE_CONFIG")
+	}
+	if !explicit {
+		path = findFile()
+	}
+	if path != "" {
+		if err := cfg.readFile(path); err != nil {
+			return nil, err
+		}
+		cfg.File = path
+	}
+
+	if err := cfg.readEnv(); err != nil {
+		return nil, err
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		value, ok := flags[f.Name]
+		if !ok {
+			return
+		}
+		switch f.Name {
+		case "transport":
+			cfg.Transport.Mode = *value
+		case "addr":
+			cfg.Transport.HTTP.Addr = *value
+		case "tls-cert":
+			cfg.Transport.HTTP.TLSCert = *value
+		case "tls-key":
+			cfg.Transport.HTTP.TLSKey = *value
+		case "auth-token":
+			cfg.Transport.HTTP.AuthToken = *value
+		case "log-file":
+			cfg.Log.File = *value
+		case "log-level":
+			cfg.Log.Level = *value
+		}
+	})
+
+	cfg.resolve()
+	return cfg, nil
+}
+
+func findFile() string {
+	candidates := []string{"openfinance.yaml"}
+	if dir, err := os.UserConfigDir(); err == nil {
+		candidates = append(candidates, filepath.Join(dir, "openfinance-mcp", "config.yaml"))
+	}
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+func (c *Config) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config: error reading %s: %w", path, err)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("config: error parsing %s: %w", path, err)
+	}
+	return nil
+}
+
+type envVar struct {
+	name string
+	set  func(c *Config, value string) error
+}
+
+func text(field func(c *Config) *string) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		*field(c) = value
+		return nil
+	}
+}
+
+func number(field func(c *Config) *int) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("expected a number")
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+func boolean(field func(c *Config) *bool) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("expected true or false")
+		}
+		*field(c) = b
+		return nil
+	}
+}
+
+func list(field func(c *Config) *[]string) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		*field(c) = splitList(value)
+		return nil
+	}
+}
+
+// envVars are the environment variables overriding the file, unset or empty
+// ones being ignored.
+var envVars = []envVar{
+	{"OPENFINANCE_TRANSPORT", text(func(c *Config) *string { return &c.Transport.Mode })},
+	{"MCP_AUTH_TOKEN", text(func(c *Config) *string { return &c.Transport.HTTP.AuthToken })},
+	{"PLUGGY_CLIENT_ID", text(func(c *Config) *string { return &c.Pluggy.ClientID })},
+	{"PLUGGY_CLIENT_SECRET", text(func(c *Config) *string { return &c.Pluggy.ClientSecret })},
+	{"PLUGGY_CASSETTE_MODE", text(func(c *Config) *string { return &c.Pluggy.CassetteMode })},
+	{"PLUGGY_CASSETTE_DIR", text(func(c *Config) *string { return &c.Pluggy.CassetteDir })},
+	{"REDIS_HOST", text(func(c *Config) *string { return &c.Redis.Host })},
+	{"REDIS_PORT", number(func(c *Config) *int { return &c.Redis.Port })},
+	{"REDIS_PASSWORD", text(func(c *Config) *string { return &c.Redis.Password })},
+	{"REDIS_DB", number(func(c *Config) *int { return &c.Redis.DB })},
+	{"OPENFINANCE_LOG_FILE", text(func(c *Config) *string { return &c.Log.File })},
+	{"OPENFINANCE_LOG_LEVEL", text(func(c *Config) *string { return &c.Log.Level })},
+	{"OPENFINANCE_LOG_FORMAT", text(func(c *Config) *string { return &c.Log.Format })},
+	{"OPENFINANCE_LOG_MAX_SIZE_MB", number(func(c *Config) *int { return &c.Log.MaxSizeMB })},
+	{"OPENFINANCE_LOG_MAX_AGE_DAYS", number(func(c *Config) *int { return &c.Log.MaxAgeDays })},
+	{"OPENFINANCE_LOG_MAX_BACKUPS", number(func(c *Config) *int { return &c.Log.MaxBackups })},
+	{"OPENFINANCE_ENCRYPTION_KEY", text(func(c *Config) *string { return &c.Encryption.Key })},
+	{"OPENFINANCE_ENCRYPTION_KEY_FILE", text(func(c *Config) *string { return &c.Encryption.KeyFile })},
+	{"OPENFINANCE_REDACTION", text(func(c *Config) *string { return &c.Redaction.Preset })},
+	{"OPENFINANCE_REDACTION_RULES", text(func(c *Config) *string { return &c.Redaction.Rules })},
+	{"OPENFINANCE_REDACTION_SALT", text(func(c *Config) *string { return &c.Redaction.Salt })},
+	{"OPENFINANCE_PSEUDONYMS", boolean(func(c *Config) *bool { return &c.Pseudonyms })},
+	{"OPENFINANCE_TOOL_PROFILE", text(func(c *Config) *string { return &c.Tools.Profile })},
+	{"OPENFINANCE_TOOLS_ALLOW", list(func(c *Config) *[]string { return &c.Tools.Allow })},
+	{"OPENFINANCE_TOOLS_DENY", list(func(c *Config) *[]string { return &c.Tools.Deny })},
+	{"OPENFINANCE_TOOL_TIMEOUT", func(c *Config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("expected a duration such as 90s")
+		}
+		c.Tools.Timeout = Duration(d)
+		return nil
+	}},
+	{"OPENFINANCE_AUDIT_LOG", func(c *Config, value string) error {
+		// A path, or "off" to disable the audit log.
+		c.Audit.Enabled = value != "off"
+		if c.Audit.Enabled {
+			c.Audit.File = value
+		}
+		return nil
+	}},
+	{"OPENFINANCE_AUDIT_MAX_SIZE_MB", number(func(c *Config) *int { return &c.Audit.MaxSizeMB })},
+	{"OPENFINANCE_AUDIT_MAX_AGE_DAYS", number(func(c *Config) *int { return &c.Audit.MaxAgeDays })},
+	{"OPENFINANCE_AUDIT_MAX_BACKUPS", number(func(c *Config) *int { return &c.Audit.MaxBackups })},
+	{"OPENFINANCE_CONNECT_ADDR", text(func(c *Config) *string { return &c.Connect.Addr })},
+	{"OPENFINANCE_CONNECT_PUBLIC_URL", text(func(c *Config) *string { return &c.Connect.PublicURL })},
+	{"OPENFINANCE_METRICS_ADDR", text(func(c *Config) *string { return &c.Metrics.Addr })},
+	{"OTEL_EXPORTER_OTLP_ENDPOINT", text(func(c *Config) *string { return &c.Tracing.Endpoint })},
+	{"OTEL_SERVICE_NAME", text(func(c *Config) *string { return &c.Tracing.ServiceName })},
+}
+
+func (c *Config) readEnv() error {
+	var errs []error
+	for _, env := range envVars {
+		value := os.Getenv(env.name)
+		if value == "" {
+			continue
+		}
+		if err := env.set(c, value); err != nil {
+			errs = append(errs, fmt.Errorf("config: invalid %s %q: %w", env.name, value, err))
+		}
+	}
+	return errors.Join(errs...)
+}
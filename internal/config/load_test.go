@@ -0,0 +1,237 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// isolate clears the environment variables Load reads and makes it look for
+// configuration files in an empty directory.
+func isolate(t *testing.T) string {
+	t.Helper()
+	for _, env := range envVars {
+		t.Setenv(env.name, "")
+	}
+	t.Setenv("OPENFINANCE_CONFIG", "")
+	os.Unsetenv("OPENFINANCE_CONFIG")
+
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	t.Setenv("HOME", dir)
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	return dir
+}
+
+func write(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	const file = `
+transport:
+  mode: http
+  http:
+    addr: 0.0.0.0:9000
+redis:
+  port: 6380
+log:
+  level: debug
+tools:
+  timeout: 90s
+  allow: [get_api_key]
+`
+	tests := []struct {
+		name  string
+		file  string // written to custom.yaml and passed with -config
+		env   map[string]string
+		args  []string
+		check func(t *testing.T, c *Config)
+	}{
+		{
+			name: "defaults",
+			check: func(t *testing.T, c *Config) {
+				if !reflect.DeepEqual(c, resolved(Default())) {
+					t.Errorf("config = %+v, want the defaults", c)
+				}
+			},
+		},
+		{
+			name: "file over defaults",
+			file: file,
+			check: func(t *testing.T, c *Config) {
+				got := []any{c.Transport.Mode, c.Transport.HTTP.Addr, c.Redis.Port, c.Redis.Host, c.Log.Level, c.Tools.Timeout, c.Tools.Allow}
+				want := []any{"http", "0.0.0.0:9000", 6380, "127.0.0.1", "debug", Duration(90 * time.Second), []string{"get_api_key"}}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("config = %v, want %v", got, want)
+				}
+			},
+		},
+		{
+			name: "env over file",
+			file: file,
+			env:  map[string]string{"REDIS_PORT": "6381", "OPENFINANCE_LOG_LEVEL": "warn", "OPENFINANCE_TOOLS_ALLOW": "a, b", "OPENFINANCE_TOOL_TIMEOUT": "2m"},
+			check: func(t *testing.T, c *Config) {
+				if c.Redis.Port != 6381 || c.Log.Level != "warn" || !reflect.DeepEqual(c.Tools.Allow, []string{"a", "b"}) || c.Tools.Timeout != Duration(2*time.Minute) {
+					t.Errorf("config = %+v, want the environment", c)
+				}
+				if c.Transport.HTTP.Addr != "0.0.0.0:9000" {
+					t.Errorf("addr = %q, want the file's", c.Transport.HTTP.Addr)
+				}
+			},
+		},
+		{
+			name: "flags over env",
+			file: file,
+			env:  map[string]string{"OPENFINANCE_LOG_LEVEL": "warn", "OPENFINANCE_TRANSPORT": "http"},
+			args: []string{"-log-level", "error", "-transport", "stdio"},
+			check: func(t *testing.T, c *Config) {
+				if c.Log.Level != "error" || c.Transport.Mode != "stdio" {
+					t.Errorf("level = %q, transport = %q; want the flags", c.Log.Level, c.Transport.Mode)
+				}
+			},
+		},
+		{
+			name: "empty env ignored",
+			file: file,
+			env:  map[string]string{"OPENFINANCE_LOG_LEVEL": ""},
+			check: func(t *testing.T, c *Config) {
+				if c.Log.Level != "debug" {
+					t.Errorf("level = %q, want the file's", c.Log.Level)
+				}
+			},
+		},
+		{
+			name: "redaction follows the transport",
+			env:  map[string]string{"OPENFINANCE_TRANSPORT": "http"},
+			check: func(t *testing.T, c *Config) {
+				if c.Redaction.Preset != "strict" {
+					t.Errorf("preset = %q, want strict for http", c.Redaction.Preset)
+				}
+			},
+		},
+		{
+			name: "audit off",
+			env:  map[string]string{"OPENFINANCE_AUDIT_LOG": "off"},
+			check: func(t *testing.T, c *Config) {
+				if c.Audit.Enabled {
+					t.Errorf("the audit log should be disabled")
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := isolate(t)
+			args := tt.args
+			if tt.file != "" {
+				path := filepath.Join(dir, "custom.yaml")
+				write(t, path, tt.file)
+				args = append([]string{"-config", path}, args...)
+			}
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			cfg, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func resolved(c *Config) *Config {
+	c.resolve()
+	return c
+}
+
+func TestLoadFindsFile(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string // relative to the test directory -> content
+		env   string            // OPENFINANCE_CONFIG, relative to the test directory
+		want  string            // file loaded, relative to the test directory
+	}{
+		{"none", nil, "", ""},
+		{"working directory", map[string]string{"openfinance.yaml": "log: {level: warn}", "config/openfinance-mcp/config.yaml": "log: {level: error}"}, "", "openfinance.yaml"},
+		{"user config dir", map[string]string{"config/openfinance-mcp/config.yaml": "log: {level: error}"}, "", "config/openfinance-mcp/config.yaml"},
+		{"environment", map[string]string{"openfinance.yaml": "", "other.yaml": "log: {level: warn}"}, "other.yaml", "other.yaml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := isolate(t)
+			for name, content := range tt.files {
+				write(t, filepath.Join(dir, name), content)
+			}
+			if tt.env != "" {
+				t.Setenv("OPENFINANCE_CONFIG", filepath.Join(dir, tt.env))
+			}
+
+			cfg, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.TrimPrefix(cfg.File, dir+string(filepath.Separator)); filepath.ToSlash(got) != tt.want {
+				t.Errorf("File = %q, want %q", cfg.File, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		env     map[string]string
+		args    []string
+		wantErr string
+	}{
+		{name: "unknown key", file: "transport:\n  mod: http\n", wantErr: "field mod not found"},
+		{name: "bad duration", file: "tools:\n  timeout: soon\n", wantErr: "error parsing"},
+		{name: "missing file", args: []string{"-config", "missing.yaml"}, wantErr: "error reading missing.yaml"},
+		{name: "bad number", env: map[string]string{"REDIS_PORT": "high"}, wantErr: `invalid REDIS_PORT "high": expected a number`},
+		{name: "bad bool", env: map[string]string{"OPENFINANCE_PSEUDONYMS": "sure"}, wantErr: "expected true or false"},
+		{name: "unknown flag", args: []string{"-verbose"}, wantErr: "flag provided but not defined"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := isolate(t)
+			args := tt.args
+			if tt.file != "" {
+				path := filepath.Join(dir, "custom.yaml")
+				write(t, path, tt.file)
+				args = append([]string{"-config", path}, args...)
+			}
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(new(strings.Builder))
+			_, err := Load(fs, args)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
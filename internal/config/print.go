@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+const masked = "********"
+
+// Print writes the configuration as YAML, with secrets masked, in a form that
+// can be used as a configuration file.
+func (c *Config) Print(w io.Writer) error {
+	source := "none, defaults and environment only"
+	if c.File != "" {
+		source = c.File
+	}
+	if _, err := fmt.Fprintf(w, "# effective configuration (file: %s)\n", source); err != nil {
+		return err
+	}
+
+	copied := *c
+	mask(reflect.ValueOf(&copied).Elem())
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&copied); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// secrets returns the non-empty values of the fields tagged secret.
+func (c *Config) secrets() []string {
+	var values []string
+	walkSecrets(reflect.ValueOf(c).Elem(), func(v reflect.Value) {
+		values = append(values, v.String())
+	})
+	return values
+}
+
+// mask blanks out the non-empty fields tagged secret.
+func mask(v reflect.Value) {
+	walkSecrets(v, func(v reflect.Value) {
+		v.SetString(masked)
+	})
+}
+
+func walkSecrets(v reflect.Value, fn func(reflect.Value)) {
+	for i := 0; i < v.NumField(); i++ {
+		field, value := v.Type().Field(i), v.Field(i)
+		switch {
+		case value.Kind() == reflect.Struct:
+			walkSecrets(value, fn)
+		case field.Tag.Get("secret") == "true" && value.Kind() == reflect.String && value.String() != "":
+			fn(value)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package output
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// Budget caps how much of a page is rendered. Zero fields mean no limit.
+type Budget struct {
+	MaxItems  int
+	MaxTokens int
+}
+
+func ParseBudget(maxItems, maxTokens *int) (Budget, error) {
+	var b Budget
+	if maxItems != nil {
+		if *maxItems < 0 {
+			return b, fmt.Errorf("invalid max_items %d, expected a positive number", *maxItems)
+		}
+		b.MaxItems = *maxItems
+	}
+	if maxTokens != nil {
+		if *maxTokens < 0 {
+			return b, fmt.Errorf("invalid max_output_tokens %d, expected a positive number", *maxTokens)
+		}
+		b.MaxTokens = *maxTokens
+	}
+	return b, nil
+}
+
+func (b Budget) IsZero() bool {
+	return b.MaxItems == 0 && b.MaxTokens == 0
+}
+
+// Window selects the part of a page List renders. The zero value renders the
+// whole page as is, without paging metadata for formats that lack it.
+type Window struct {
+	Budget Budget
+	// Cursor is where a previous call stopped, if any.
+	Cursor *Cursor
+	// Scope and Query identify the listing, the Pluggy item or account ID and
+	// the filter, and are encoded in the next cursor.
+	Scope string
+	Query any
+	// PageSize is the page size the page was requested with.
+	PageSize int
+}
+
+func (w Window) isZero() bool {
+	return w.Budget.IsZero() && w.Cursor == nil && w.Scope == "" && w.Query == nil
+}
+
+// continuation is appended to a windowed rendering so the model knows what
+// was left out and how to get it.
+type continuation struct {
+	Truncated  bool
+	Shown      int
+	Rows       int // rows available from the window start, shown or not
+	Summary    []Stat
+	HasMore    bool
+	NextCursor string
+}
+
+func (c *continuation) fields() map[string]any {
+	fields := map[string]any{}
+	if c.Truncated {
+		fields["truncated"] = true
+		fields["shown"] = c.Shown
+		fields["omitted"] = c.Rows - c.Shown
+		fields["summary"] = statsMap(c.Summary)
+	}
+	fields["has_more"] = c.HasMore
+	if c.HasMore {
+		fields["next_cursor"] = c.NextCursor
+	}
+	return fields
+}
+
+func (c *continuation) lines() []string {
+	var lines []string
+	if c.Truncated {
+		// The summary covers the fetched page only, not the whole result.
+		lines = append(lines, fmt.Sprintf("Truncated: showing %d of %d rows. Summary of this page (%d rows):", c.Shown, c.Rows, c.Rows))
+		for _, stat := range c.Summary {
+			lines = append(lines, fmt.Sprintf("- %s: %s", stat.Name, stringify(stat.Value)))
+		}
+	}
+	lines = append(lines, fmt.Sprintf("has_more: %t", c.HasMore))
+	if c.HasMore {
+		lines = append(lines, fmt.Sprintf("next_cursor: %s", c.NextCursor))
+	}
+	return lines
+}
+
+// estimateTokens approximates the token count of s at four characters per
+// token, which is close enough for JSON and tables of short fields.
+func estimateTokens(s string) int {
+	return (utf8.RuneCountInString(s) + 3) / 4
+}
@@ -0,0 +1,142 @@
+package output
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pluggy"
+)
+
+func TestParseBudget(t *testing.T) {
+	num := func(n int) *int { return &n }
+	tests := []struct {
+		maxItems, maxTokens *int
+		want                Budget
+		wantErr             bool
+	}{
+		{nil, nil, Budget{}, false},
+		{num(10), nil, Budget{MaxItems: 10}, false},
+		{nil, num(500), Budget{MaxTokens: 500}, false},
+		{num(0), num(0), Budget{}, false},
+		{num(-1), nil, Budget{}, true},
+		{nil, num(-5), Budget{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseBudget(tt.maxItems, tt.maxTokens)
+		if (err != nil) != tt.wantErr || (!tt.wantErr && got != tt.want) {
+			t.Errorf("ParseBudget(%v, %v) = %+v, %v; want %+v, error %t", tt.maxItems, tt.maxTokens, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestListBudget(t *testing.T) {
+	tests := []struct {
+		name      string
+		rows      int
+		budget    Budget
+		wantShown int
+		wantMore  bool
+	}{
+		{"fits", 5, Budget{MaxItems: 10}, 5, false},
+		{"max items", 5, Budget{MaxItems: 2}, 2, true},
+		{"max tokens", 20, Budget{MaxTokens: 400}, -1, true},
+		{"tiny token budget keeps one row", 5, Budget{MaxTokens: 1}, 1, true},
+		{"empty page", 0, Budget{MaxTokens: 1}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := &pluggy.PaginatedResponse[pluggy.Transaction]{Page: 1, Total: float64(tt.rows), TotalPages: 1, Results: transactions(tt.rows)}
+			text, err := List(FormatCompact, Transactions, page, Window{Budget: tt.budget, Scope: "acc-1"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got struct {
+				Results    []json.RawMessage `json:"results"`
+				HasMore    bool              `json:"has_more"`
+				NextCursor string            `json:"next_cursor"`
+				Truncated  bool              `json:"truncated"`
+				Omitted    int               `json:"omitted"`
+				Summary    map[string]any    `json:"summary"`
+			}
+			if err := json.Unmarshal([]byte(text), &got); err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.wantShown >= 0 && len(got.Results) != tt.wantShown {
+				t.Errorf("shown %d rows, want %d", len(got.Results), tt.wantShown)
+			}
+			if tt.budget.MaxTokens > 1 && estimateTokens(text) > tt.budget.MaxTokens {
+				t.Errorf("output is %d tokens, over the budget of %d", estimateTokens(text), tt.budget.MaxTokens)
+			}
+			if got.HasMore != tt.wantMore || (got.NextCursor != "") != tt.wantMore {
+				t.Errorf("has_more = %t, next_cursor = %q; want has_more %t", got.HasMore, got.NextCursor, tt.wantMore)
+			}
+			if got.Truncated {
+				if got.Omitted != tt.rows-len(got.Results) {
+					t.Errorf("omitted = %d, want %d", got.Omitted, tt.rows-len(got.Results))
+				}
+				if got.Summary["count"] != float64(tt.rows) {
+					t.Errorf("summary = %v, want it to count the %d rows of the page", got.Summary, tt.rows)
+				}
+			}
+		})
+	}
+}
+
+func TestContinuationLines(t *testing.T) {
+	tests := []struct {
+		name string
+		cont continuation
+		want []string
+	}{
+		{
+			name: "complete",
+			cont: continuation{Shown: 3, Rows: 3},
+			want: []string{"has_more: false"},
+		},
+		{
+			name: "next page",
+			cont: continuation{Shown: 3, Rows: 3, HasMore: true, NextCursor: "abc"},
+			want: []string{"has_more: true", "next_cursor: abc"},
+		},
+		{
+			name: "truncated",
+			cont: continuation{Truncated: true, Shown: 2, Rows: 5, Summary: []Stat{{"count", 5}}, HasMore: true, NextCursor: "abc"},
+			want: []string{
+				"Truncated: showing 2 of 5 rows. Summary of this page (5 rows):",
+				"- count: 5",
+				"has_more: true",
+				"next_cursor: abc",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cont.lines(); strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("lines() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestSummaries(t *testing.T) {
+	rows := transactions(3)
+	rows[2].Type, rows[2].Category = "CREDIT", ""
+
+	got := statsMap(Transactions.Summary(rows))
+	want := map[string]string{
+		"count":          "3",
+		"from":           "2024-03-01",
+		"to":             "2024-03-03",
+		"total_in":       "30",
+		"total_out":      "30",
+		"top_categories": "Food 30.00 (2), Uncategorized 30.00 (1)",
+	}
+	for name, value := range want {
+		if s := stringify(got[name]); s != value {
+			t.Errorf("%s = %q, want %q", name, s, value)
+		}
+	}
+}
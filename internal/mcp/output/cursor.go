@@ -0,0 +1,123 @@
+package output
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// defaultPageSize is the page size Pluggy uses when none is requested.
+const defaultPageSize = 20
+
+// Cursor is the position of the next result of a listing. It is handed to the
+// model as an opaque string and carries everything needed to continue: the
+// resource being listed, the filter and the offset across all pages, so the
+// model never has to deal with Pluggy's page numbers.
+type Cursor struct {
+	Scope    string          `json:"s"`
+	Query    json.RawMessage `json:"q,omitempty"`
+	Offset   int             `json:"o"`
+	PageSize int             `json:"n,omitempty"`
+}
+
+// ParseCursor decodes a cursor and checks it was issued for scope, the Pluggy
+// ID of the item or account being listed. Pass the resolved ID rather than the
+// argument, which may be a pseudonym that differs between sessions.
+func ParseCursor(s *string, scope string) (*Cursor, error) {
+	if s == nil || *s == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(*s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Offset < 0 || c.PageSize < 0 {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	if c.Scope != scopeKey(scope) {
+		return nil, fmt.Errorf("cursor was issued for another listing")
+	}
+
+	return &c, nil
+}
+
+func (c Cursor) String() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Filter decodes the filter the listing was made with into v.
+func (c *Cursor) Filter(v any) error {
+	if len(c.Query) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(c.Query, v); err != nil {
+		return fmt.Errorf("invalid cursor filter: %w", err)
+	}
+	return nil
+}
+
+// Matches checks that explicit, the filter passed along with the cursor, sets
+// no field to another value than the cursor's filter. Unset fields must be
+// omitted from explicit's JSON.
+func (c *Cursor) Matches(explicit any) error {
+	data, err := json.Marshal(explicit)
+	if err != nil {
+		return err
+	}
+	var passed, carried map[string]json.RawMessage
+	if err := json.Unmarshal(data, &passed); err != nil {
+		return err
+	}
+	if len(c.Query) > 0 {
+		if err := json.Unmarshal(c.Query, &carried); err != nil {
+			return fmt.Errorf("invalid cursor filter: %w", err)
+		}
+	}
+
+	names := make([]string, 0, len(passed))
+	for name := range passed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if want, ok := carried[name]; !ok || !bytes.Equal(passed[name], want) {
+			if !ok {
+				want = json.RawMessage("unset")
+			}
+			return fmt.Errorf("cursor was issued with %s=%s, not %s; pass the cursor alone or start over without it", name, want, passed[name])
+		}
+	}
+	return nil
+}
+
+// scopeKey identifies a listing in a cursor without handing the Pluggy ID to
+// the model, which only ever sees its pseudonym when they are enabled.
+func scopeKey(scope string) string {
+	sum := sha256.Sum256([]byte(scope))
+	return hex.EncodeToString(sum[:8])
+}
+
+func (c *Cursor) size() int {
+	if c.PageSize > 0 {
+		return c.PageSize
+	}
+	return defaultPageSize
+}
+
+// Page is the Pluggy page holding the cursor's result.
+func (c *Cursor) Page() int {
+	return c.Offset/c.size() + 1
+}
+
+// pageOffset is the index of the cursor's result within its page.
+func (c *Cursor) pageOffset() int {
+	return c.Offset % c.size()
+}
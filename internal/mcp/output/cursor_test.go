@@ -0,0 +1,169 @@
+package output
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pluggy"
+)
+
+func TestParseCursor(t *testing.T) {
+	str := func(s string) *string { return &s }
+	valid := Cursor{Scope: scopeKey("acc-1"), Offset: 45, PageSize: 20}.String()
+
+	tests := []struct {
+		name    string
+		cursor  *string
+		scope   string
+		want    *Cursor
+		wantErr string
+	}{
+		{name: "none", cursor: nil, scope: "acc-1"},
+		{name: "empty", cursor: str(""), scope: "acc-1"},
+		{name: "valid", cursor: &valid, scope: "acc-1", want: &Cursor{Scope: scopeKey("acc-1"), Offset: 45, PageSize: 20}},
+		{name: "other listing", cursor: &valid, scope: "acc-2", wantErr: "cursor was issued for another listing"},
+		{name: "not base64", cursor: str("!!!"), scope: "acc-1", wantErr: "invalid cursor"},
+		{name: "not JSON", cursor: str("bm9wZQ"), scope: "acc-1", wantErr: "invalid cursor"},
+		{name: "negative offset", cursor: str(Cursor{Scope: scopeKey("acc-1"), Offset: -1}.String()), scope: "acc-1", wantErr: "invalid cursor"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCursor(tt.cursor, tt.scope)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && (got.Scope != tt.want.Scope || got.Offset != tt.want.Offset || got.PageSize != tt.want.PageSize)) {
+				t.Errorf("ParseCursor() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// The cursor is opaque to the model, and must not hand it the Pluggy ID it is
+// scoped on.
+func TestCursorHidesScope(t *testing.T) {
+	s := Cursor{Scope: scopeKey("3d5e2a9c-pluggy-account"), Offset: 1}.String()
+	c, err := ParseCursor(&s, "3d5e2a9c-pluggy-account")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(c.Scope, "pluggy") {
+		t.Errorf("cursor scope %q holds the ID", c.Scope)
+	}
+}
+
+func TestCursorPage(t *testing.T) {
+	tests := []struct {
+		offset, pageSize     int
+		wantPage, wantOffset int
+	}{
+		{0, 0, 1, 0},
+		{19, 0, 1, 19},
+		{20, 0, 2, 0},
+		{45, 20, 3, 5},
+		{500, 500, 2, 0},
+		{7, 5, 2, 2},
+	}
+	for _, tt := range tests {
+		c := &Cursor{Offset: tt.offset, PageSize: tt.pageSize}
+		if c.Page() != tt.wantPage || c.pageOffset() != tt.wantOffset {
+			t.Errorf("offset %d, page size %d: page %d, offset %d; want %d, %d", tt.offset, tt.pageSize, c.Page(), c.pageOffset(), tt.wantPage, tt.wantOffset)
+		}
+	}
+}
+
+type testQuery struct {
+	From string   `json:"from,omitempty"`
+	To   string   `json:"to,omitempty"`
+	IDs  []string `json:"ids,omitempty"`
+}
+
+func TestCursorMatches(t *testing.T) {
+	carried, _ := json.Marshal(testQuery{From: "2024-01-01", IDs: []string{"tx_1"}})
+	c := &Cursor{Query: carried}
+
+	tests := []struct {
+		name     string
+		explicit testQuery
+		wantErr  string
+	}{
+		{"no filters", testQuery{}, ""},
+		{"same filters", testQuery{From: "2024-01-01", IDs: []string{"tx_1"}}, ""},
+		{"one of the filters", testQuery{From: "2024-01-01"}, ""},
+		{"other value", testQuery{From: "2024-02-01"}, `cursor was issued with from="2024-01-01", not "2024-02-01"`},
+		{"unset in the cursor", testQuery{To: "2024-01-31"}, `cursor was issued with to=unset, not "2024-01-31"`},
+		{"other list", testQuery{IDs: []string{"tx_1", "tx_2"}}, `cursor was issued with ids=["tx_1"], not ["tx_1","tx_2"]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := c.Matches(tt.explicit)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Matches() = %v, want no error", err)
+				}
+				return
+			}
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Errorf("Matches() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// Following next_cursor through a listing visits every row exactly once,
+// whatever the budget cuts each call to.
+func TestListCursorWalk(t *testing.T) {
+	const total, pageSize = 23, 10
+	rows := transactions(total)
+
+	var seen []string
+	var cursor *Cursor
+	for calls := 0; calls < 50; calls++ {
+		pageNumber := 1
+		if cursor != nil {
+			pageNumber = cursor.Page()
+		}
+		end := min(pageNumber*pageSize, total)
+		page := &pluggy.PaginatedResponse[pluggy.Transaction]{Page: float64(pageNumber), Total: total, TotalPages: 3, Results: rows[(pageNumber-1)*pageSize : end]}
+
+		text, err := List(FormatCompact, Transactions, page, Window{Budget: Budget{MaxItems: 4}, Cursor: cursor, Scope: "acc-1", PageSize: pageSize})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got struct {
+			Results []struct {
+				ID string `json:"id"`
+			} `json:"results"`
+			HasMore    bool   `json:"has_more"`
+			NextCursor string `json:"next_cursor"`
+		}
+		if err := json.Unmarshal([]byte(text), &got); err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range got.Results {
+			seen = append(seen, r.ID)
+		}
+		if !got.HasMore {
+			break
+		}
+		if cursor, err = ParseCursor(&got.NextCursor, "acc-1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(seen) != total {
+		t.Fatalf("saw %d rows, want %d: %v", len(seen), total, seen)
+	}
+	for i, id := range seen {
+		if id != rows[i].ID {
+			t.Fatalf("row %d is %s, want %s", i, id, rows[i].ID)
+		}
+	}
+}
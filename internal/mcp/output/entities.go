@@ -0,0 +1,129 @@
+package output
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pluggy"
+)
+
+func date(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t.Format("2006-01-02")
+}
+
+var Transactions = Entity[pluggy.Transaction]{
+	Columns: []Column[pluggy.Transaction]{
+		{"id", func(t pluggy.Transaction) any { return t.ID }},
+		{"date", func(t pluggy.Transaction) any { return date(t.Date) }},
+		{"description", func(t pluggy.Transaction) any { return t.Description }},
+		{"amount", func(t pluggy.Transaction) any { return t.Amount }},
+		{"currencyCode", func(t pluggy.Transaction) any { return t.CurrencyCode }},
+		{"type", func(t pluggy.Transaction) any { return t.Type }},
+		{"category", func(t pluggy.Transaction) any { return t.Category }},
+		{"status", func(t pluggy.Transaction) any { return t.Status }},
+	},
+	Summary: func(rows []pluggy.Transaction) []Stat {
+		from, to := dateSpan(rows, func(t pluggy.Transaction) time.Time { return t.Date })
+		return []Stat{
+			{"count", len(rows)},
+			{"from", from},
+			{"to", to},
+			{"total_in", sum(rows, func(t pluggy.Transaction) decimal.Decimal { return flow(t, "CREDIT") })},
+			{"total_out", sum(rows, func(t pluggy.Transaction) decimal.Decimal { return flow(t, "DEBIT") })},
+			{"top_categories", groupBy(rows,
+				func(t pluggy.Transaction) string { return t.Category },
+				func(t pluggy.Transaction) decimal.Decimal { return t.Amount.Abs() },
+				5)},
+		}
+	},
+}
+
+// flow is the absolute amount of t when it goes in the given direction
+// ("CREDIT" or "DEBIT"), zero otherwise.
+func flow(t pluggy.Transaction, direction string) decimal.Decimal {
+	if t.Type != direction {
+		return decimal.Zero
+	}
+	return t.Amount.Abs()
+}
+
+var Accounts = Entity[pluggy.Account]{
+	Columns: []Column[pluggy.Account]{
+		{"id", func(a pluggy.Account) any { return a.ID }},
+		{"name", func(a pluggy.Account) any { return a.Name }},
+		{"type", func(a pluggy.Account) any { return a.Type }},
+		{"subtype", func(a pluggy.Account) any { return a.Subtype }},
+		{"number", func(a pluggy.Account) any { return a.Number }},
+		{"balance", func(a pluggy.Account) any { return a.Balance }},
+		{"currencyCode", func(a pluggy.Account) any { return a.CurrencyCode }},
+	},
+	Summary: func(rows []pluggy.Account) []Stat {
+		return []Stat{
+			{"count", len(rows)},
+			{"balance_by_type", groupBy(rows,
+				func(a pluggy.Account) string { return a.Type },
+				func(a pluggy.Account) decimal.Decimal { return a.Balance },
+				0)},
+		}
+	},
+}
+
+var Investments = Entity[pluggy.Investment]{
+	Columns: []Column[pluggy.Investment]{
+		{"id", func(i pluggy.Investment) any { return i.ID }},
+		{"name", func(i pluggy.Investment) any { return i.Name }},
+		{"type", func(i pluggy.Investment) any { return i.Type }},
+		{"subtype", func(i pluggy.Investment) any { return i.Subtype }},
+		{"balance", func(i pluggy.Investment) any { return i.Balance }},
+		{"currencyCode", func(i pluggy.Investment) any { return i.CurrencyCode }},
+		{"status", func(i pluggy.Investment) any { return i.Status }},
+	},
+	Summary: func(rows []pluggy.Investment) []Stat {
+		return []Stat{
+			{"count", len(rows)},
+			{"total_balance", sum(rows, func(i pluggy.Investment) decimal.Decimal { return i.Balance })},
+			{"balance_by_type", groupBy(rows,
+				func(i pluggy.Investment) string { return i.Type },
+				func(i pluggy.Investment) decimal.Decimal { return i.Balance },
+				0)},
+		}
+	},
+}
+
+var Bills = Entity[pluggy.Bill]{
+	Columns: []Column[pluggy.Bill]{
+		{"id", func(b pluggy.Bill) any { return b.ID }},
+		{"dueDate", func(b pluggy.Bill) any { return date(b.DueDate) }},
+		{"totalAmount", func(b pluggy.Bill) any { return b.TotalAmount }},
+		{"totalAmountCurrencyCode", func(b pluggy.Bill) any { return b.TotalAmountCurrencyCode }},
+		{"minimumPaymentAmount", func(b pluggy.Bill) any { return b.MinimumPaymentAmount }},
+	},
+	Summary: func(rows []pluggy.Bill) []Stat {
+		from, to := dateSpan(rows, func(b pluggy.Bill) time.Time { return b.DueDate })
+		return []Stat{
+			{"count", len(rows)},
+			{"from", from},
+			{"to", to},
+			{"total_amount", sum(rows, func(b pluggy.Bill) decimal.Decimal { return decimal.NewFromFloat(b.TotalAmount) })},
+		}
+	},
+}
+
+var Items = Entity[pluggy.Item]{
+	Columns: []Column[pluggy.Item]{
+		{"id", func(i pluggy.Item) any { return i.ID }},
+		{"connector", func(i pluggy.Item) any { return i.Connector.Name }},
+		{"status", func(i pluggy.Item) any { return i.Status }},
+		{"executionStatus", func(i pluggy.Item) any { return i.ExecutionStatus }},
+		{"lastUpdatedAt", func(i pluggy.Item) any { return date(i.LastUpdatedAt) }},
+	},
+	Summary: func(rows []pluggy.Item) []Stat {
+		return []Stat{
+			{"count", len(rows)},
+		}
+	},
+}
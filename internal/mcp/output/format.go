@@ -0,0 +1,304 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pluggy"
+)
+
+type Format string
+
+const (
+	FormatJSON     Format = "json"     // the full Pluggy payload
+	FormatCompact  Format = "compact"  // JSON with only the entity's key fields
+	FormatCSV      Format = "csv"      // key fields as CSV with a header row
+	FormatMarkdown Format = "markdown" // key fields as a Markdown table
+)
+
+func ParseFormat(s *string) (Format, error) {
+	if s == nil || *s == "" {
+		return FormatJSON, nil
+	}
+	switch f := Format(strings.ToLower(*s)); f {
+	case FormatJSON, FormatCompact, FormatCSV, FormatMarkdown:
+		return f, nil
+	default:
+		return "", fmt.Errorf("invalid format %q, expected json, compact, csv or markdown", *s)
+	}
+}
+
+// Column is one key field of an entity, shared by the compact, CSV and
+// Markdown renderings so they always agree on what is shown.
+type Column[T any] struct {
+	Name  string
+	Value func(T) any
+}
+
+type Entity[T any] struct {
+	Columns []Column[T]
+	// Summary aggregates the rows of a page that was truncated to fit a Budget.
+	Summary func([]T) []Stat
+}
+
+// List renders a page of results in the requested format. A non-zero window
+// skips the rows already returned through a cursor, truncates the rest to the
+// budget and reports whether there is more, with the cursor to continue and a
+// summary of what was truncated.
+func List[T any](format Format, entity Entity[T], page *pluggy.PaginatedResponse[T], window Window) (string, error) {
+	if window.isZero() {
+		return render(format, entity, page, page.Results, nil)
+	}
+
+	var query json.RawMessage
+	if window.Query != nil {
+		data, err := json.Marshal(window.Query)
+		if err != nil {
+			return "", err
+		}
+		query = data
+	}
+
+	// Positions are counted across all pages, so the next cursor does not
+	// depend on Pluggy's page numbering.
+	pageSize := window.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	offset := 0
+	if window.Cursor != nil {
+		offset = window.Cursor.pageOffset()
+	}
+	rows := page.Results[min(offset, len(page.Results)):]
+	start := (max(int(page.Page), 1)-1)*pageSize + offset
+
+	cont := &continuation{Rows: len(rows)}
+	cut := func(n int) (string, error) {
+		cont.Truncated = n < len(rows)
+		cont.Shown = n
+		cont.Summary = nil
+		if cont.Truncated && entity.Summary != nil {
+			cont.Summary = entity.Summary(rows)
+		}
+
+		cont.HasMore = cont.Truncated || page.Page < page.TotalPages
+		cont.NextCursor = ""
+		if cont.HasMore {
+			cont.NextCursor = Cursor{
+				Scope:    scopeKey(window.Scope),
+				Query:    query,
+				Offset:   start + n,
+				PageSize: window.PageSize,
+			}.String()
+		}
+
+		return render(format, entity, page, rows[:n], cont)
+	}
+
+	n := len(rows)
+	if window.Budget.MaxItems > 0 {
+		n = min(n, window.Budget.MaxItems)
+	}
+
+	text, err := cut(n)
+	if err != nil || window.Budget.MaxTokens == 0 || estimateTokens(text) <= window.Budget.MaxTokens {
+		return text, err
+	}
+
+	// Find the most rows that fit the token budget. At least one row is kept
+	// so a cursor always moves forward.
+	lo, hi := 1, n-1
+	best := 1
+	for lo <= hi {
+		mid := (lo + hi) / 2
+		text, err := cut(mid)
+		if err != nil {
+			return "", err
+		}
+		if estimateTokens(text) <= window.Budget.MaxTokens {
+			best, lo = mid, mid+1
+		} else {
+			hi = mid - 1
+		}
+	}
+
+	if len(rows) == 0 {
+		best = 0
+	}
+	return cut(best)
+}
+
+func render[T any](format Format, entity Entity[T], page *pluggy.PaginatedResponse[T], rows []T, cont *continuation) (string, error) {
+	switch format {
+	case FormatCompact:
+		fields := map[string]any{
+			"page":       page.Page,
+			"total":      page.Total,
+			"totalPages": page.TotalPages,
+			"results":    entity.compact(rows),
+		}
+		if cont != nil {
+			for k, v := range cont.fields() {
+				fields[k] = v
+			}
+		}
+		return marshal(fields)
+	case FormatCSV:
+		text, err := entity.csv(rows)
+		if err != nil || cont == nil {
+			return text, err
+		}
+		// CSV has no room for metadata, so it trails as comment lines.
+		text += fmt.Sprintf("# page %.0f of %.0f, %.0f results in total\n", page.Page, page.TotalPages, page.Total)
+		for _, line := range cont.lines() {
+			text += "# " + line + "\n"
+		}
+		return text, nil
+	case FormatMarkdown:
+		text := fmt.Sprintf("%s\n_Page %.0f of %.0f, %.0f results in total._", entity.markdown(rows), page.Page, page.TotalPages, page.Total)
+		if cont != nil {
+			if lines := cont.lines(); len(lines) > 0 {
+				text += "\n\n" + strings.Join(lines, "\n")
+			}
+		}
+		return text, nil
+	default:
+		trimmed := *page
+		trimmed.Results = rows
+		if cont == nil {
+			return marshal(trimmed)
+		}
+		return marshalWith(trimmed, cont.fields())
+	}
+}
+
+func (e Entity[T]) compact(rows []T) []map[string]any {
+	out := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		fields := make(map[string]any, len(e.Columns))
+		for _, col := range e.Columns {
+			if v := col.Value(row); !isEmpty(v) {
+				fields[col.Name] = v
+			}
+		}
+		out = append(out, fields)
+	}
+	return out
+}
+
+func (e Entity[T]) csv(rows []T) (string, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	header := make([]string, len(e.Columns))
+	for i, col := range e.Columns {
+		header[i] = col.Name
+	}
+	if err := w.Write(header); err != nil {
+		return "", err
+	}
+
+	for _, row := range rows {
+		record := make([]string, len(e.Columns))
+		for i, col := range e.Columns {
+			record[i] = stringify(col.Value(row))
+		}
+		if err := w.Write(record); err != nil {
+			return "", err
+		}
+	}
+
+	w.Flush()
+	return buf.String(), w.Error()
+}
+
+func (e Entity[T]) markdown(rows []T) string {
+	var b strings.Builder
+
+	b.WriteString("|")
+	for _, col := range e.Columns {
+		b.WriteString(" " + col.Name + " |")
+	}
+	b.WriteString("\n|")
+	for range e.Columns {
+		b.WriteString(" --- |")
+	}
+	b.WriteString("\n")
+
+	for _, row := range rows {
+		b.WriteString("|")
+		for _, col := range e.Columns {
+			cell := strings.ReplaceAll(stringify(col.Value(row)), "|", "\\|")
+			b.WriteString(" " + strings.ReplaceAll(cell, "\n", " ") + " |")
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+func marshal(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// marshalWith marshals v, an object, with extra top-level fields.
+func marshalWith(v any, extra map[string]any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return string(data), err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return "", err
+	}
+	for k, v := range extra {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		fields[k] = raw
+	}
+	return marshal(fields)
+}
+
+func stringify(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case decimal.Decimal:
+		return val.String()
+	case time.Time:
+		if val.IsZero() {
+			return ""
+		}
+		return val.Format("2006-01-02")
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+func isEmpty(v any) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case string:
+		return val == ""
+	case time.Time:
+		return val.IsZero()
+	default:
+		return false
+	}
+}
@@ -0,0 +1,123 @@
+package output
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pluggy"
+)
+
+func transactions(n int) []pluggy.Transaction {
+	rows := make([]pluggy.Transaction, n)
+	for i := range rows {
+		rows[i] = pluggy.Transaction{
+			ID:           "tx-" + string(rune('a'+i)),
+			Description:  "Padaria | Centro",
+			Amount:       decimal.NewFromInt(int64(-10 * (i + 1))),
+			CurrencyCode: "BRL",
+			Date:         time.Date(2024, 3, i+1, 12, 0, 0, 0, time.UTC),
+			Type:         "DEBIT",
+			Category:     "Food",
+			Status:       "POSTED",
+		}
+	}
+	return rows
+}
+
+func TestParseFormat(t *testing.T) {
+	str := func(s string) *string { return &s }
+	tests := []struct {
+		in      *string
+		want    Format
+		wantErr bool
+	}{
+		{nil, FormatJSON, false},
+		{str(""), FormatJSON, false},
+		{str("json"), FormatJSON, false},
+		{str("Compact"), FormatCompact, false},
+		{str("CSV"), FormatCSV, false},
+		{str("markdown"), FormatMarkdown, false},
+		{str("xml"), "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseFormat(%v) = %q, %v; want %q, error %t", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestList(t *testing.T) {
+	page := &pluggy.PaginatedResponse[pluggy.Transaction]{Page: 1, Total: 2, TotalPages: 1, Results: transactions(2)}
+
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{FormatCompact, `{"page":1,"results":[` +
+			`{"amount":"-10","category":"Food","currencyCode":"BRL","date":"2024-03-01","description":"Padaria | Centro","id":"tx-a","status":"POSTED","type":"DEBIT"},` +
+			`{"amount":"-20","category":"Food","currencyCode":"BRL","date":"2024-03-02","description":"Padaria | Centro","id":"tx-b","status":"POSTED","type":"DEBIT"}` +
+			`],"total":2,"totalPages":1}`},
+		{FormatCSV, "id,date,description,amount,currencyCode,type,category,status\n" +
+			"tx-a,2024-03-01,Padaria | Centro,-10,BRL,DEBIT,Food,POSTED\n" +
+			"tx-b,2024-03-02,Padaria | Centro,-20,BRL,DEBIT,Food,POSTED\n"},
+		{FormatMarkdown, "| id | date | description | amount | currencyCode | type | category | status |\n" +
+			"| --- | --- | --- | --- | --- | --- | --- | --- |\n" +
+			"| tx-a | 2024-03-01 | Padaria \\| Centro | -10 | BRL | DEBIT | Food | POSTED |\n" +
+			"| tx-b | 2024-03-02 | Padaria \\| Centro | -20 | BRL | DEBIT | Food | POSTED |\n" +
+			"\n_Page 1 of 1, 2 results in total._"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			got, err := List(tt.format, Transactions, page, Window{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("List() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	got, err := List(FormatJSON, Transactions, page, Window{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"page":1`, `"totalPages":1`, `"id":"tx-a"`, `"accountId":""`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("full JSON does not contain %s:\n%s", want, got)
+		}
+	}
+}
+
+// Windowed CSV keeps the page and the cursor as trailing comment lines, since
+// the format has no room for them otherwise.
+func TestListCSVContinuation(t *testing.T) {
+	page := &pluggy.PaginatedResponse[pluggy.Transaction]{Page: 1, Total: 3, TotalPages: 2, Results: transactions(2)}
+
+	got, err := List(FormatCSV, Transactions, page, Window{Scope: "acc-1", PageSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(got), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("got %d lines, want header, 2 rows and 3 comments:\n%s", len(lines), got)
+	}
+	if lines[3] != "# page 1 of 2, 3 results in total" || lines[4] != "# has_more: true" {
+		t.Errorf("unexpected comment lines:\n%s", got)
+	}
+	next, ok := strings.CutPrefix(lines[5], "# next_cursor: ")
+	if !ok {
+		t.Fatalf("no next_cursor line:\n%s", got)
+	}
+	cursor, err := ParseCursor(&next, "acc-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cursor.Offset != 2 || cursor.Page() != 2 {
+		t.Errorf("next cursor = %+v, want offset 2 on page 2", cursor)
+	}
+}
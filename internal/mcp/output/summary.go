@@ -0,0 +1,95 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Stat is one aggregate of a summary, kept in order for the text renderings.
+type Stat struct {
+	Name  string
+	Value any
+}
+
+func statsMap(stats []Stat) map[string]any {
+	m := make(map[string]any, len(stats))
+	for _, stat := range stats {
+		m[stat.Name] = stat.Value
+	}
+	return m
+}
+
+type groupTotal struct {
+	Name   string          `json:"name"`
+	Count  int             `json:"count"`
+	Amount decimal.Decimal `json:"amount"`
+}
+
+type groupTotals []groupTotal
+
+func (g groupTotals) String() string {
+	parts := make([]string, len(g))
+	for i, total := range g {
+		parts[i] = fmt.Sprintf("%s %s (%d)", total.Name, total.Amount.StringFixed(2), total.Count)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// groupBy totals rows by key and returns the largest groups by absolute
+// amount, at most limit of them when limit is positive.
+func groupBy[T any](rows []T, key func(T) string, amount func(T) decimal.Decimal, limit int) groupTotals {
+	index := map[string]int{}
+	var totals groupTotals
+	for _, row := range rows {
+		name := key(row)
+		if name == "" {
+			name = "Uncategorized"
+		}
+		i, ok := index[name]
+		if !ok {
+			i = len(totals)
+			index[name] = i
+			totals = append(totals, groupTotal{Name: name})
+		}
+		totals[i].Count++
+		totals[i].Amount = totals[i].Amount.Add(amount(row))
+	}
+
+	sort.SliceStable(totals, func(i, j int) bool {
+		return totals[i].Amount.Abs().GreaterThan(totals[j].Amount.Abs())
+	})
+	if limit > 0 && len(totals) > limit {
+		totals = totals[:limit]
+	}
+	return totals
+}
+
+// dateSpan returns the earliest and latest non-zero dates of rows.
+func dateSpan[T any](rows []T, value func(T) time.Time) (from, to any) {
+	var first, last time.Time
+	for _, row := range rows {
+		d := value(row)
+		if d.IsZero() {
+			continue
+		}
+		if first.IsZero() || d.Before(first) {
+			first = d
+		}
+		if d.After(last) {
+			last = d
+		}
+	}
+	return date(first), date(last)
+}
+
+func sum[T any](rows []T, amount func(T) decimal.Decimal) decimal.Decimal {
+	total := decimal.Zero
+	for _, row := range rows {
+		total = total.Add(amount(row))
+	}
+	return total
+}
@@ -0,0 +1,59 @@
+package prompts
+
+import (
+	"fmt"
+
+	mcp "github.com/metoro-io/mcp-golang"
+
+	internalMcp "github.com/thunderjr/openfinance-mcp-server/internal/provider/mcp"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pseudonym"
+)
+
+type CreditCardBillAuditArgs struct {
+	Item  *string `json:"item,omitempty" jsonschema:"description=Item ID holding the credit card (default: all known items)"`
+	Month *string `json:"month,omitempty" jsonschema:"description=Month of the bill due date as yyyy-mm (default: last month)"`
+}
+
+type CreditCardBillAuditPrompt struct{}
+
+func NewCreditCardBillAuditPrompt() *CreditCardBillAuditPrompt {
+	return &CreditCardBillAuditPrompt{}
+}
+
+func (p *CreditCardBillAuditPrompt) Name() string {
+	return "credit_card_bill_audit"
+}
+
+func (p *CreditCardBillAuditPrompt) Description() string {
+	return "Audit a credit card bill: check charges, installments, fees and duplicates against the transactions"
+}
+
+func (p *CreditCardBillAuditPrompt) Identifiers() map[string]pseudonym.Kind {
+	return map[string]pseudonym.Kind{"item": pseudonym.Item}
+}
+
+func (p *CreditCardBillAuditPrompt) Handle() internalMcp.PromptHandlerFunc {
+	return p.handle
+}
+
+func (p *CreditCardBillAuditPrompt) handle(args CreditCardBillAuditArgs) (*mcp.PromptResponse, error) {
+	month, err := resolveMonth(args.Month)
+	if err != nil {
+		return nil, err
+	}
+
+	text := fmt.Sprintf(`Audit my credit card bill due in %s using %s.
+
+1. Call get_item_accounts and keep the CREDIT accounts.
+2. For each card, call get_account_bills and pick the bill whose dueDate falls in %s; use get_bill_details if you need its finance charges.
+3. Call get_account_transactions for the card covering the bill period (roughly the 40 days before the due date, page_size=500) and match transactions to the bill through creditCardMetadata.billId.
+
+Then report:
+- the bill total, minimum payment and due date, and whether the matched transactions add up to the total;
+- installment purchases (installmentNumber / totalInstallments) and how much they commit in the coming months;
+- finance charges, interest, IOF, annual fees and late fees, with their amounts;
+- possible duplicate charges (same merchant and amount within a few days) and charges from unknown merchants;
+- a clear verdict: OK to pay, or items I should dispute with the bank.`, month.Format("January 2006"), itemScope(args.Item), month.Format("2006-01"))
+
+	return userPrompt(p.Description(), text), nil
+}
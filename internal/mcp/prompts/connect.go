@@ -0,0 +1,47 @@
+package prompts
+
+import (
+	mcp "github.com/metoro-io/mcp-golang"
+
+	internalMcp "github.com/thunderjr/openfinance-mcp-server/internal/provider/mcp"
+)
+
+type ConnectNewBankArgs struct {
+	Bank *string `json:"bank,omitempty" jsonschema:"description=Name of the bank to connect, if already known"`
+}
+
+type ConnectNewBankPrompt struct{}
+
+func NewConnectNewBankPrompt() *ConnectNewBankPrompt {
+	return &ConnectNewBankPrompt{}
+}
+
+func (p *ConnectNewBankPrompt) Name() string {
+	return "connect_new_bank"
+}
+
+func (p *ConnectNewBankPrompt) Description() string {
+	return "Walk through connecting a new bank with Pluggy Connect and confirm the data is available"
+}
+
+func (p *ConnectNewBankPrompt) Handle() internalMcp.PromptHandlerFunc {
+	return p.handle
+}
+
+func (p *ConnectNewBankPrompt) handle(args ConnectNewBankArgs) (*mcp.PromptResponse, error) {
+	bank := "a new bank"
+	if b := value(args.Bank); b != "" {
+		bank = b
+	}
+
+	text := `Help me connect ` + bank + ` to this server.
+
+1. Call pluggy_connect_url without an item_id and give me the returned URL as a clickable link. Explain that it opens Pluggy Connect in my browser, where I pick the bank and authorize access; my bank credentials never pass through this chat. If the tool is not available, call pluggy_connect_token instead and explain that the token must be used in a Pluggy Connect widget.
+2. Wait for me to say I am done, then call pluggy_connect_status with the session ID. If it is still PENDING, ask me to finish in the browser; if it FAILED, show the error and offer a new link.
+3. With the connected item ID, call pluggy_wait_item_updated so the first synchronization finishes. If it times out, call it again; if it stops on WAITING_USER_INPUT or LOGIN_ERROR, relay its explanation to me.
+4. Call get_item_details and get_item_accounts and show me the connector name, item status and a table of the accounts found with their balances.
+
+Do not ask me for bank passwords or tokens at any point.`
+
+	return userPrompt(p.Description(), text), nil
+}
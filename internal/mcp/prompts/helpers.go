@@ -0,0 +1,44 @@
+package prompts
+
+import (
+	"fmt"
+	"time"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// Prompt arguments are named after the Go struct fields, so argument structs
+// use single-word fields (Item, Month) that read well to the user.
+
+func userPrompt(description, text string) *mcp.PromptResponse {
+	return mcp.NewPromptResponse(description, mcp.NewPromptMessage(mcp.NewTextContent(text), mcp.RoleUser))
+}
+
+func value(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// resolveMonth validates a yyyy-mm argument and defaults to the last closed
+// month, which is what a review usually targets.
+func resolveMonth(month *string) (time.Time, error) {
+	if value(month) == "" {
+		now := time.Now()
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0), nil
+	}
+
+	parsed, err := time.Parse("2006-01", *month)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid month %q, expected yyyy-mm", *month)
+	}
+	return parsed, nil
+}
+
+func itemScope(item *string) string {
+	if id := value(item); id != "" {
+		return fmt.Sprintf("item `%s`", id)
+	}
+	return "every item returned by `get_known_items` (ask me which bank to use if there are none)"
+}
@@ -0,0 +1,53 @@
+package prompts
+
+import (
+	"fmt"
+
+	mcp "github.com/metoro-io/mcp-golang"
+
+	internalMcp "github.com/thunderjr/openfinance-mcp-server/internal/provider/mcp"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pseudonym"
+)
+
+type InvestmentPortfolioCheckupArgs struct {
+	Item *string `json:"item,omitempty" jsonschema:"description=Item ID holding the investments (default: all known items)"`
+}
+
+type InvestmentPortfolioCheckupPrompt struct{}
+
+func NewInvestmentPortfolioCheckupPrompt() *InvestmentPortfolioCheckupPrompt {
+	return &InvestmentPortfolioCheckupPrompt{}
+}
+
+func (p *InvestmentPortfolioCheckupPrompt) Name() string {
+	return "investment_portfolio_checkup"
+}
+
+func (p *InvestmentPortfolioCheckupPrompt) Description() string {
+	return "Check up an investment portfolio: allocation, returns, concentration and upcoming maturities"
+}
+
+func (p *InvestmentPortfolioCheckupPrompt) Identifiers() map[string]pseudonym.Kind {
+	return map[string]pseudonym.Kind{"item": pseudonym.Item}
+}
+
+func (p *InvestmentPortfolioCheckupPrompt) Handle() internalMcp.PromptHandlerFunc {
+	return p.handle
+}
+
+func (p *InvestmentPortfolioCheckupPrompt) handle(args InvestmentPortfolioCheckupArgs) (*mcp.PromptResponse, error) {
+	text := fmt.Sprintf(`Give my investment portfolio a checkup using %s.
+
+1. Call get_item_investments with page_size=500, fetching further pages until every investment is loaded.
+2. Ignore investments whose status is not ACTIVE, but mention how many were skipped.
+
+Then give me:
+- the total balance and the allocation by type (FIXED_INCOME, MUTUAL_FUND, EQUITY, ETF, ...) and by institution/issuer, in a table;
+- returns: last month and last twelve months rates where available, highlighting the best and worst positions;
+- concentration risks, such as a single issuer or type above 30%% of the portfolio, or fixed income above the FGC guarantee limit per issuer;
+- fixed income positions maturing in the next 90 days;
+- taxes and fees visible in the data;
+- a short, neutral assessment of diversification. Do not recommend specific products.`, itemScope(args.Item))
+
+	return userPrompt(p.Description(), text), nil
+}
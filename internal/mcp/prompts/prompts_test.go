@@ -0,0 +1,106 @@
+package prompts
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+func text(t *testing.T, res *mcp.PromptResponse) string {
+	t.Helper()
+	if len(res.Messages) != 1 || res.Messages[0].Content.TextContent == nil {
+		t.Fatalf("prompt response = %+v, want a single text message", res)
+	}
+	return res.Messages[0].Content.TextContent.Text
+}
+
+func TestPrompts(t *testing.T) {
+	str := func(s string) *string { return &s }
+	lastMonth := time.Now().UTC().AddDate(0, 0, -time.Now().UTC().Day()+1).AddDate(0, -1, 0)
+
+	tests := []struct {
+		name    string
+		handle  func() (*mcp.PromptResponse, error)
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "spending review of one item and month",
+			handle: func() (*mcp.PromptResponse, error) {
+				return NewMonthlySpendingReviewPrompt().handle(MonthlySpendingReviewArgs{Item: str("item_1"), Month: str("2024-02")})
+			},
+			want: []string{"February 2024", "item `item_1`", "from=2024-02-01 and to=2024-02-29", "get_account_transactions"},
+		},
+		{
+			name: "spending review defaults to every item and last month",
+			handle: func() (*mcp.PromptResponse, error) {
+				return NewMonthlySpendingReviewPrompt().handle(MonthlySpendingReviewArgs{})
+			},
+			want: []string{lastMonth.Format("January 2006"), "get_known_items", "from=" + lastMonth.Format("2006-01-02")},
+		},
+		{
+			name: "spending review rejects a bad month",
+			handle: func() (*mcp.PromptResponse, error) {
+				return NewMonthlySpendingReviewPrompt().handle(MonthlySpendingReviewArgs{Month: str("02/2024")})
+			},
+			wantErr: `invalid month "02/2024", expected yyyy-mm`,
+		},
+		{
+			name: "bill audit",
+			handle: func() (*mcp.PromptResponse, error) {
+				return NewCreditCardBillAuditPrompt().handle(CreditCardBillAuditArgs{Item: str("item_2"), Month: str("2024-12")})
+			},
+			want: []string{"due in December 2024", "item `item_2`", "dueDate falls in 2024-12", "get_account_bills", "creditCardMetadata.billId"},
+		},
+		{
+			name: "bill audit rejects a bad month",
+			handle: func() (*mcp.PromptResponse, error) {
+				return NewCreditCardBillAuditPrompt().handle(CreditCardBillAuditArgs{Month: str("2024-13")})
+			},
+			wantErr: "invalid month",
+		},
+		{
+			name: "portfolio checkup",
+			handle: func() (*mcp.PromptResponse, error) {
+				return NewInvestmentPortfolioCheckupPrompt().handle(InvestmentPortfolioCheckupArgs{Item: str("item_3")})
+			},
+			want: []string{"item `item_3`", "get_item_investments", "above 30% of the portfolio"},
+		},
+		{
+			name: "connect a named bank",
+			handle: func() (*mcp.PromptResponse, error) {
+				return NewConnectNewBankPrompt().handle(ConnectNewBankArgs{Bank: str("Nubank")})
+			},
+			want: []string{"connect Nubank to this server", "pluggy_connect_url", "pluggy_connect_status", "pluggy_wait_item_updated"},
+		},
+		{
+			name: "connect an unnamed bank",
+			handle: func() (*mcp.PromptResponse, error) {
+				return NewConnectNewBankPrompt().handle(ConnectNewBankArgs{})
+			},
+			want: []string{"connect a new bank to this server"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := tt.handle()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := text(t, res)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("prompt text does not contain %q:\n%s", want, got)
+				}
+			}
+		})
+	}
+}
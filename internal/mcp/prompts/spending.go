@@ -0,0 +1,63 @@
+package prompts
+
+import (
+	"fmt"
+
+	mcp "github.com/metoro-io/mcp-golang"
+
+	internalMcp "github.com/thunderjr/openfinance-mcp-server/internal/provider/mcp"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pseudonym"
+)
+
+type MonthlySpendingReviewArgs struct {
+	Item  *string `json:"item,omitempty" jsonschema:"description=Item ID to review (default: all known items)"`
+	Month *string `json:"month,omitempty" jsonschema:"description=Month to review as yyyy-mm (default: last month)"`
+}
+
+type MonthlySpendingReviewPrompt struct{}
+
+func NewMonthlySpendingReviewPrompt() *MonthlySpendingReviewPrompt {
+	return &MonthlySpendingReviewPrompt{}
+}
+
+func (p *MonthlySpendingReviewPrompt) Name() string {
+	return "monthly_spending_review"
+}
+
+func (p *MonthlySpendingReviewPrompt) Description() string {
+	return "Review a month of spending: totals, categories, largest expenses and recurring charges"
+}
+
+func (p *MonthlySpendingReviewPrompt) Identifiers() map[string]pseudonym.Kind {
+	return map[string]pseudonym.Kind{"item": pseudonym.Item}
+}
+
+func (p *MonthlySpendingReviewPrompt) Handle() internalMcp.PromptHandlerFunc {
+	return p.handle
+}
+
+func (p *MonthlySpendingReviewPrompt) handle(args MonthlySpendingReviewArgs) (*mcp.PromptResponse, error) {
+	month, err := resolveMonth(args.Month)
+	if err != nil {
+		return nil, err
+	}
+	from, to := month.Format("2006-01-02"), month.AddDate(0, 1, -1).Format("2006-01-02")
+
+	text := fmt.Sprintf(`Review my spending for %s using %s.
+
+1. Call get_item_accounts to list the accounts. Include both checking (BANK) and credit card (CREDIT) accounts.
+2. For each account, call get_account_transactions with from=%s and to=%s and page_size=500, fetching further pages until every transaction is loaded.
+3. Ignore transfers between my own accounts and credit card bill payments so nothing is counted twice.
+
+Then give me:
+- total income, total expenses and the net result for the month;
+- expenses grouped by category, sorted by amount, with each category's share of the total;
+- the 10 largest expenses with date, description and amount;
+- recurring charges and subscriptions, flagging any that are new or changed in value;
+- anything unusual (duplicate charges, fees, interest, spikes in a category);
+- two or three concrete suggestions to spend less next month.
+
+Use BRL formatting and keep the summary short; put details in tables.`, month.Format("January 2006"), itemScope(args.Item), from, to)
+
+	return userPrompt(p.Description(), text), nil
+}
@@ -0,0 +1,182 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	mcp "github.com/metoro-io/mcp-golang"
+
+	internalMcp "github.com/thunderjr/openfinance-mcp-server/internal/provider/mcp"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pluggy"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pseudonym"
+)
+
+// A month of transactions rarely exceeds a couple of pages of 500; the cap
+// keeps a runaway account from producing an unbounded resource.
+const maxStatementPages = 10
+
+type AccountResource struct {
+	client *pluggy.Client
+}
+
+func NewAccountResource(client *pluggy.Client) *AccountResource {
+	return &AccountResource{client}
+}
+
+func (r *AccountResource) URITemplate() string {
+	return "openfinance://accounts/{account_id}"
+}
+
+func (r *AccountResource) Name() string {
+	return "account"
+}
+
+func (r *AccountResource) Description() string {
+	return "Account snapshot with balance and bank or credit card data"
+}
+
+func (r *AccountResource) MimeType() string {
+	return mimeTypeJSON
+}
+
+func (r *AccountResource) Tool() string {
+	return "get_account_details"
+}
+
+func (r *AccountResource) Read(ctx context.Context, uri string, params map[string]string) (*mcp.ResourceResponse, error) {
+	if err := resolveParams(ctx, params); err != nil {
+		return nil, err
+	}
+	account, err := r.client.GetAccount(ctx, params["account_id"])
+	if err != nil {
+		return nil, pluggyError(ctx, err)
+	}
+	account, err = pseudonymize(ctx, pseudonym.Account, account)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResource(uri, account)
+}
+
+type AccountTransactionsResource struct {
+	client *pluggy.Client
+}
+
+func NewAccountTransactionsResource(client *pluggy.Client) *AccountTransactionsResource {
+	return &AccountTransactionsResource{client}
+}
+
+func (r *AccountTransactionsResource) URITemplate() string {
+	return "openfinance://accounts/{account_id}/transactions{?month}"
+}
+
+func (r *AccountTransactionsResource) Name() string {
+	return "account-statement"
+}
+
+func (r *AccountTransactionsResource) Description() string {
+	return "Monthly statement: every transaction of an account in a month (month=yyyy-mm, default: current month)"
+}
+
+func (r *AccountTransactionsResource) MimeType() string {
+	return mimeTypeJSON
+}
+
+func (r *AccountTransactionsResource) Tool() string {
+	return "get_account_transactions"
+}
+
+func (r *AccountTransactionsResource) Read(ctx context.Context, uri string, params map[string]string) (*mcp.ResourceResponse, error) {
+	if err := resolveParams(ctx, params); err != nil {
+		return nil, err
+	}
+
+	month := time.Now()
+	if params["month"] != "" {
+		parsed, err := time.Parse("2006-01", params["month"])
+		if err != nil {
+			return nil, fmt.Errorf("invalid month %q, expected yyyy-mm", params["month"])
+		}
+		month = parsed
+	}
+
+	from := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	filter := &pluggy.TransactionFilter{
+		From:     from,
+		To:       from.AddDate(0, 1, -1),
+		PageSize: 500,
+	}
+
+	var transactions []pluggy.Transaction
+	for page := 1; page <= maxStatementPages; page++ {
+		filter.Page = page
+		res, err := r.client.GetTransactions(ctx, params["account_id"], filter)
+		if err != nil {
+			return nil, pluggyError(ctx, err)
+		}
+		transactions = append(transactions, res.Results...)
+		if float64(page) >= res.TotalPages {
+			break
+		}
+	}
+
+	transactions, err := pseudonymize(ctx, pseudonym.Transaction, transactions)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResource(uri, statement{
+		AccountID:    pseudonym.Alias(internalMcp.SessionID(ctx), pseudonym.Account, params["account_id"]),
+		Month:        from.Format("2006-01"),
+		Transactions: transactions,
+	})
+}
+
+type statement struct {
+	AccountID    string               `json:"accountId"`
+	Month        string               `json:"month"`
+	Transactions []pluggy.Transaction `json:"transactions"`
+}
+
+type AccountBillsResource struct {
+	client *pluggy.Client
+}
+
+func NewAccountBillsResource(client *pluggy.Client) *AccountBillsResource {
+	return &AccountBillsResource{client}
+}
+
+func (r *AccountBillsResource) URITemplate() string {
+	return "openfinance://accounts/{account_id}/bills"
+}
+
+func (r *AccountBillsResource) Name() string {
+	return "account-bills"
+}
+
+func (r *AccountBillsResource) Description() string {
+	return "Credit card bills of an account"
+}
+
+func (r *AccountBillsResource) MimeType() string {
+	return mimeTypeJSON
+}
+
+func (r *AccountBillsResource) Tool() string {
+	return "get_account_bills"
+}
+
+func (r *AccountBillsResource) Read(ctx context.Context, uri string, params map[string]string) (*mcp.ResourceResponse, error) {
+	if err := resolveParams(ctx, params); err != nil {
+		return nil, err
+	}
+	bills, err := r.client.GetBills(ctx, params["account_id"])
+	if err != nil {
+		return nil, pluggyError(ctx, err)
+	}
+	results, err := pseudonymize(ctx, pseudonym.Bill, bills.Results)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResource(uri, results)
+}
@@ -0,0 +1,119 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+
+	mcp "github.com/metoro-io/mcp-golang"
+
+	internalMcp "github.com/thunderjr/openfinance-mcp-server/internal/provider/mcp"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pluggy"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pseudonym"
+)
+
+type ItemResource struct {
+	client *pluggy.Client
+}
+
+func NewItemResource(client *pluggy.Client) *ItemResource {
+	return &ItemResource{client}
+}
+
+func (r *ItemResource) URITemplate() string {
+	return "openfinance://items/{item_id}"
+}
+
+func (r *ItemResource) Name() string {
+	return "item"
+}
+
+func (r *ItemResource) Description() string {
+	return "A connected item (bank connection) with its status and connector"
+}
+
+func (r *ItemResource) MimeType() string {
+	return mimeTypeJSON
+}
+
+func (r *ItemResource) Tool() string {
+	return "get_item_details"
+}
+
+func (r *ItemResource) Read(ctx context.Context, uri string, params map[string]string) (*mcp.ResourceResponse, error) {
+	if err := resolveParams(ctx, params); err != nil {
+		return nil, err
+	}
+	item, err := r.client.GetItem(ctx, params["item_id"])
+	if err != nil {
+		return nil, pluggyError(ctx, err)
+	}
+	item, err = pseudonymize(ctx, pseudonym.Item, item)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResource(uri, item)
+}
+
+func (r *ItemResource) List(ctx context.Context) ([]internalMcp.ResourceEntry, error) {
+	items, err := r.client.KnownItems(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items, err = pseudonym.Apply(internalMcp.SessionID(ctx), pseudonym.Item, items)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]internalMcp.ResourceEntry, 0, len(items))
+	for _, item := range items {
+		entries = append(entries, internalMcp.ResourceEntry{
+			URI:         fmt.Sprintf("openfinance://items/%s", item.ID),
+			Name:        fmt.Sprintf("Item %s", item.ID),
+			Description: "Connected item snapshot",
+		})
+	}
+	return entries, nil
+}
+
+type ItemAccountsResource struct {
+	client *pluggy.Client
+}
+
+func NewItemAccountsResource(client *pluggy.Client) *ItemAccountsResource {
+	return &ItemAccountsResource{client}
+}
+
+func (r *ItemAccountsResource) URITemplate() string {
+	return "openfinance://items/{item_id}/accounts"
+}
+
+func (r *ItemAccountsResource) Name() string {
+	return "item-accounts"
+}
+
+func (r *ItemAccountsResource) Description() string {
+	return "All accounts of an item with their balances"
+}
+
+func (r *ItemAccountsResource) MimeType() string {
+	return mimeTypeJSON
+}
+
+func (r *ItemAccountsResource) Tool() string {
+	return "get_item_accounts"
+}
+
+func (r *ItemAccountsResource) Read(ctx context.Context, uri string, params map[string]string) (*mcp.ResourceResponse, error) {
+	if err := resolveParams(ctx, params); err != nil {
+		return nil, err
+	}
+	accounts, err := r.client.GetAccounts(ctx, params["item_id"])
+	if err != nil {
+		return nil, pluggyError(ctx, err)
+	}
+	results, err := pseudonymize(ctx, pseudonym.Account, accounts.Results)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResource(uri, results)
+}
@@ -0,0 +1,56 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	mcp "github.com/metoro-io/mcp-golang"
+
+	internalMcp "github.com/thunderjr/openfinance-mcp-server/internal/provider/mcp"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pseudonym"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/redact"
+)
+
+const mimeTypeJSON = "application/json"
+
+// redactionScope is the name resources go by in redaction rules.
+const redactionScope = "resources"
+
+// jsonResource renders v with personal data redacted.
+func jsonResource(uri string, v any) (*mcp.ResourceResponse, error) {
+	data, err := redact.JSON(redactionScope, v)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling %s: %w", uri, err)
+	}
+	return mcp.NewResourceResponse(mcp.NewTextEmbeddedResource(uri, string(data), mimeTypeJSON)), nil
+}
+
+// pseudonymize replaces identifiers by the session's pseudonyms, when enabled,
+// as the tools do.
+func pseudonymize[T any](ctx context.Context, kind pseudonym.Kind, v T) (T, error) {
+	return pseudonym.Apply(internalMcp.SessionID(ctx), kind, v)
+}
+
+// resolveParams replaces the identifiers of a URI, which may be the session's
+// pseudonyms, by the Pluggy ones.
+func resolveParams(ctx context.Context, params map[string]string) error {
+	for name, value := range params {
+		if !strings.HasSuffix(name, "_id") {
+			continue
+		}
+		id, err := pseudonym.Resolve(internalMcp.SessionID(ctx), value)
+		if err != nil {
+			return err
+		}
+		params[name] = id
+	}
+	return nil
+}
+
+// pluggyError keeps the Pluggy identifiers of a failed request, such as the
+// ones in its URL, out of the error the client gets.
+func pluggyError(ctx context.Context, err error) error {
+	return errors.New(pseudonym.Text(internalMcp.SessionID(ctx), err.Error()))
+}
@@ -1,18 +1,25 @@
 package tools
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
 	mcp "github.com/metoro-io/mcp-golang"
 
+	"github.com/thunderjr/openfinance-mcp-server/internal/mcp/output"
 	"github.com/thunderjr/openfinance-mcp-server/internal/provider/logger"
 	internalMcp "github.com/thunderjr/openfinance-mcp-server/internal/provider/mcp"
 	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pluggy"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pseudonym"
 )
 
 type AccountsArgs struct {
-	ItemID string `json:"item_id" jsonschema:"required,description=The ID of the item to retrieve accounts for"`
+	ItemID          string  `json:"item_id" jsonschema:"required,description=The ID of the item to retrieve accounts for"`
+	Format          *string `json:"format,omitempty" jsonschema:"enum=json,enum=compact,enum=csv,enum=markdown,description=Output format (default: json). json returns the full payload; compact keeps only key fields; csv and markdown render the key fields as a table"`
+	MaxItems        *int    `json:"max_items,omitempty" jsonschema:"description=Return at most this many results; the rest of the page is summarized and can be fetched with next_cursor"`
+	MaxOutputTokens *int    `json:"max_output_tokens,omitempty" jsonschema:"description=Approximate token budget for the output; results are truncated to fit and the rest of the page is summarized"`
+	Cursor          *string `json:"cursor,omitempty" jsonschema:"description=next_cursor returned by a previous call to continue where it stopped"`
 }
 
 type PluggyAccountsTool struct {
@@ -31,6 +38,10 @@ func (t *PluggyAccountsTool) Description() string {
 	return "Retrieves all accounts associated with a specific item"
 }
 
+func (t *PluggyAccountsTool) Access() internalMcp.Access {
+	return internalMcp.AccessRead
+}
+
 func (t *PluggyAccountsTool) Handle() internalMcp.ToolHandlerFunc {
 	return t.handleGetAccounts
 }
@@ -55,49 +66,82 @@ func (t *PluggyAccountTool) Description() string {
 	return "Retrieves detailed information for a specific account"
 }
 
+func (t *PluggyAccountTool) Access() internalMcp.Access {
+	return internalMcp.AccessRead
+}
+
 func (t *PluggyAccountTool) Handle() internalMcp.ToolHandlerFunc {
 	return t.handleGetAccount
 }
 
-func (t *PluggyAccountsTool) handleGetAccounts(args AccountsArgs) (*mcp.ToolResponse, error) {
+func (t *PluggyAccountsTool) handleGetAccounts(ctx context.Context, args AccountsArgs) (*mcp.ToolResponse, error) {
 	if args.ItemID == "" {
-		errorMessage := "Item ID is required"
-		return mcp.NewToolResponse(mcp.NewTextContent(errorMessage)), fmt.Errorf(errorMessage)
+		return nil, validationError("Item ID is required")
+	}
+
+	itemID, err := resolveID(ctx, args.ItemID)
+	if err != nil {
+		return nil, err
+	}
+
+	format, err := output.ParseFormat(args.Format)
+	if err != nil {
+		return nil, validationError(err.Error())
+	}
+
+	budget, err := output.ParseBudget(args.MaxItems, args.MaxOutputTokens)
+	if err != nil {
+		return nil, validationError(err.Error())
 	}
 
-	accounts, err := t.client.GetAccounts(args.ItemID)
+	cursor, err := output.ParseCursor(args.Cursor, itemID)
 	if err != nil {
-		errorMessage := fmt.Sprintf("Error getting accounts: %v", err)
-		return mcp.NewToolResponse(mcp.NewTextContent(errorMessage)), fmt.Errorf(errorMessage)
+		return nil, validationError(err.Error())
 	}
 
-	accountsJSON, err := json.Marshal(accounts)
+	accounts, err := t.client.GetAccounts(ctx, itemID)
 	if err != nil {
-		errorMessage := fmt.Sprintf("Error marshalling accounts: %v", err)
-		return mcp.NewToolResponse(mcp.NewTextContent(errorMessage)), fmt.Errorf(errorMessage)
+		return nil, pluggyError(ctx, "Error getting accounts", err)
 	}
 
-	return mcp.NewToolResponse(mcp.NewTextContent(string(accountsJSON))), nil
+	accounts, err = sanitize(ctx, t.Name(), pseudonym.Account, accounts)
+	if err != nil {
+		return nil, internalError(fmt.Sprintf("Error sanitizing accounts: %v", err))
+	}
+
+	text, err := output.List(format, output.Accounts, accounts, output.Window{Budget: budget, Cursor: cursor, Scope: itemID})
+	if err != nil {
+		return nil, internalError(fmt.Sprintf("Error rendering accounts: %v", err))
+	}
+
+	return mcp.NewToolResponse(mcp.NewTextContent(text)), nil
 }
 
-func (t *PluggyAccountTool) handleGetAccount(args AccountArgs) (*mcp.ToolResponse, error) {
+func (t *PluggyAccountTool) handleGetAccount(ctx context.Context, args AccountArgs) (*mcp.ToolResponse, error) {
 	if args.AccountID == "" {
-		errorMessage := "Account ID is required"
-		return mcp.NewToolResponse(mcp.NewTextContent(errorMessage)), fmt.Errorf(errorMessage)
+		return nil, validationError("Account ID is required")
 	}
 
-	logger.Info("Getting account details for:", args.AccountID)
+	accountID, err := resolveID(ctx, args.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Getting account details", "account_id", args.AccountID)
+
+	account, err := t.client.GetAccount(ctx, accountID)
+	if err != nil {
+		return nil, pluggyError(ctx, "Error getting account", err)
+	}
 
-	account, err := t.client.GetAccount(args.AccountID)
+	account, err = sanitize(ctx, t.Name(), pseudonym.Account, account)
 	if err != nil {
-		errorMessage := fmt.Sprintf("Error getting account: %v", err)
-		return mcp.NewToolResponse(mcp.NewTextContent(errorMessage)), fmt.Errorf(errorMessage)
+		return nil, internalError(fmt.Sprintf("Error sanitizing account: %v", err))
 	}
 
 	accountJSON, err := json.Marshal(account)
 	if err != nil {
-		errorMessage := fmt.Sprintf("Error marshalling account: %v", err)
-		return mcp.NewToolResponse(mcp.NewTextContent(errorMessage)), fmt.Errorf(errorMessage)
+		return nil, internalError(fmt.Sprintf("Error marshalling account: %v", err))
 	}
 
 	return mcp.NewToolResponse(mcp.NewTextContent(string(accountJSON))), nil
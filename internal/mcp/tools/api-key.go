@@ -1,8 +1,7 @@
 package tools
 
 import (
-	"fmt"
-
+	"context"
 	mcp "github.com/metoro-io/mcp-golang"
 
 	"github.com/thunderjr/openfinance-mcp-server/internal/provider/logger"
@@ -26,19 +25,22 @@ func (t *PluggyApiKeyTool) Description() string {
 	return "Generates a new Pluggy API key using the client ID and client secret"
 }
 
+func (t *PluggyApiKeyTool) Access() internalMcp.Access {
+	return internalMcp.AccessSensitive
+}
+
 func (t *PluggyApiKeyTool) Handle() internalMcp.ToolHandlerFunc {
 	return t.handleApiKey
 }
 
 type ApiKeyArgs struct{}
 
-func (t *PluggyApiKeyTool) handleApiKey(args ApiKeyArgs) (*mcp.ToolResponse, error) {
+func (t *PluggyApiKeyTool) handleApiKey(ctx context.Context, args ApiKeyArgs) (*mcp.ToolResponse, error) {
 	logger.Info("Generating new Pluggy API key")
 
-	apiKey, err := t.client.ApiKey()
+	apiKey, err := t.client.ApiKey(ctx)
 	if err != nil {
-		errorMessage := fmt.Sprintf("Error generating Pluggy API key: %v", err)
-		return mcp.NewToolResponse(mcp.NewTextContent(errorMessage)), fmt.Errorf(errorMessage)
+		return nil, pluggyError(ctx, "Error generating Pluggy API key", err)
 	}
 
 	return mcp.NewToolResponse(mcp.NewTextContent(apiKey)), nil
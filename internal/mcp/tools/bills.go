@@ -1,18 +1,25 @@
 package tools
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
 	mcp "github.com/metoro-io/mcp-golang"
 
+	"github.com/thunderjr/openfinance-mcp-server/internal/mcp/output"
 	"github.com/thunderjr/openfinance-mcp-server/internal/provider/logger"
 	internalMcp "github.com/thunderjr/openfinance-mcp-server/internal/provider/mcp"
 	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pluggy"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pseudonym"
 )
 
 type BillsArgs struct {
-	AccountID string `json:"account_id" jsonschema:"required,description=The ID of the account to retrieve bills for"`
+	AccountID       string  `json:"account_id" jsonschema:"required,description=The ID of the account to retrieve bills for"`
+	Format          *string `json:"format,omitempty" jsonschema:"enum=json,enum=compact,enum=csv,enum=markdown,description=Output format (default: json). json returns the full payload; compact keeps only key fields; csv and markdown render the key fields as a table"`
+	MaxItems        *int    `json:"max_items,omitempty" jsonschema:"description=Return at most this many results; the rest of the page is summarized and can be fetched with next_cursor"`
+	MaxOutputTokens *int    `json:"max_output_tokens,omitempty" jsonschema:"description=Approximate token budget for the output; results are truncated to fit and the rest of the page is summarized"`
+	Cursor          *string `json:"cursor,omitempty" jsonschema:"description=next_cursor returned by a previous call to continue where it stopped"`
 }
 
 type PluggyBillsTool struct {
@@ -31,31 +38,57 @@ func (t *PluggyBillsTool) Description() string {
 	return "Retrieves all bills associated with a specific account"
 }
 
+func (t *PluggyBillsTool) Access() internalMcp.Access {
+	return internalMcp.AccessRead
+}
+
 func (t *PluggyBillsTool) Handle() internalMcp.ToolHandlerFunc {
 	return t.handleGetBills
 }
 
-func (t *PluggyBillsTool) handleGetBills(args BillsArgs) (*mcp.ToolResponse, error) {
+func (t *PluggyBillsTool) handleGetBills(ctx context.Context, args BillsArgs) (*mcp.ToolResponse, error) {
 	if args.AccountID == "" {
-		errorMessage := "Account ID is required"
-		return mcp.NewToolResponse(mcp.NewTextContent(errorMessage)), fmt.Errorf(errorMessage)
+		return nil, validationError("Account ID is required")
 	}
 
-	logger.Info("Getting bills for account:", args.AccountID)
+	accountID, err := resolveID(ctx, args.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	format, err := output.ParseFormat(args.Format)
+	if err != nil {
+		return nil, validationError(err.Error())
+	}
 
-	bills, err := t.client.GetBills(args.AccountID)
+	budget, err := output.ParseBudget(args.MaxItems, args.MaxOutputTokens)
 	if err != nil {
-		errorMessage := fmt.Sprintf("Error getting bills: %v", err)
-		return mcp.NewToolResponse(mcp.NewTextContent(errorMessage)), fmt.Errorf(errorMessage)
+		return nil, validationError(err.Error())
 	}
 
-	billsJSON, err := json.Marshal(bills)
+	cursor, err := output.ParseCursor(args.Cursor, accountID)
 	if err != nil {
-		errorMessage := fmt.Sprintf("Error marshalling bills: %v", err)
-		return mcp.NewToolResponse(mcp.NewTextContent(errorMessage)), fmt.Errorf(errorMessage)
+		return nil, validationError(err.Error())
 	}
 
-	return mcp.NewToolResponse(mcp.NewTextContent(string(billsJSON))), nil
+	logger.Info("Getting bills", "account_id", args.AccountID)
+
+	bills, err := t.client.GetBills(ctx, accountID)
+	if err != nil {
+		return nil, pluggyError(ctx, "Error getting bills", err)
+	}
+
+	bills, err = sanitize(ctx, t.Name(), pseudonym.Bill, bills)
+	if err != nil {
+		return nil, internalError(fmt.Sprintf("Error sanitizing bills: %v", err))
+	}
+
+	text, err := output.List(format, output.Bills, bills, output.Window{Budget: budget, Cursor: cursor, Scope: accountID})
+	if err != nil {
+		return nil, internalError(fmt.Sprintf("Error rendering bills: %v", err))
+	}
+
+	return mcp.NewToolResponse(mcp.NewTextContent(text)), nil
 }
 
 type BillArgs struct {
@@ -78,28 +111,39 @@ func (t *PluggyBillTool) Description() string {
 	return "Retrieves detailed information for a specific bill"
 }
 
+func (t *PluggyBillTool) Access() internalMcp.Access {
+	return internalMcp.AccessRead
+}
+
 func (t *PluggyBillTool) Handle() internalMcp.ToolHandlerFunc {
 	return t.handleGetBill
 }
 
-func (t *PluggyBillTool) handleGetBill(args BillArgs) (*mcp.ToolResponse, error) {
+func (t *PluggyBillTool) handleGetBill(ctx context.Context, args BillArgs) (*mcp.ToolResponse, error) {
 	if args.BillID == "" {
-		errorMessage := "Bill ID is required"
-		return mcp.NewToolResponse(mcp.NewTextContent(errorMessage)), fmt.Errorf(errorMessage)
+		return nil, validationError("Bill ID is required")
 	}
 
-	logger.Info("Getting bill details for:", args.BillID)
+	billID, err := resolveID(ctx, args.BillID)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Getting bill details", "bill_id", args.BillID)
+
+	bill, err := t.client.GetBill(ctx, billID)
+	if err != nil {
+		return nil, pluggyError(ctx, "Error getting bill", err)
+	}
 
-	bill, err := t.client.GetBill(args.BillID)
+	bill, err = sanitize(ctx, t.Name(), pseudonym.Bill, bill)
 	if err != nil {
-		errorMessage := fmt.Sprintf("Error getting bill: %v", err)
-		return mcp.NewToolResponse(mcp.NewTextContent(errorMessage)), fmt.Errorf(errorMessage)
+		return nil, internalError(fmt.Sprintf("Error sanitizing bill: %v", err))
 	}
 
 	billJSON, err := json.Marshal(bill)
 	if err != nil {
-		errorMessage := fmt.Sprintf("Error marshalling bill: %v", err)
-		return mcp.NewToolResponse(mcp.NewTextContent(errorMessage)), fmt.Errorf(errorMessage)
+		return nil, internalError(fmt.Sprintf("Error marshalling bill: %v", err))
 	}
 
 	return mcp.NewToolResponse(mcp.NewTextContent(string(billJSON))), nil
@@ -1,6 +1,8 @@
 package tools
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 
 	mcp "github.com/metoro-io/mcp-golang"
@@ -23,7 +25,11 @@ func (t *PluggyConnectTokenTool) Name() string {
 }
 
 func (t *PluggyConnectTokenTool) Description() string {
-	return "Generates a new connect token for a specific Pluggy item"
+	return "Returns a Pluggy Connect token, reusing a cached one while it is still valid. Pass item_id to update an existing item or omit it to connect a new one"
+}
+
+func (t *PluggyConnectTokenTool) Access() internalMcp.Access {
+	return internalMcp.AccessSensitive
 }
 
 func (t *PluggyConnectTokenTool) Handle() internalMcp.ToolHandlerFunc {
@@ -31,22 +37,47 @@ func (t *PluggyConnectTokenTool) Handle() internalMcp.ToolHandlerFunc {
 }
 
 type ConnectTokenArgs struct {
-	ItemID string `json:"item_id" jsonschema:"required,description=The Pluggy item ID to generate a connect token for"`
+	ItemID           *string `json:"item_id,omitempty" jsonschema:"description=The Pluggy item ID to update. Omit to create a token for a new connection"`
+	WebhookURL       *string `json:"webhook_url,omitempty" jsonschema:"description=URL notified by Pluggy about item events created with this token"`
+	ClientUserID     *string `json:"client_user_id,omitempty" jsonschema:"description=Your own identifier for the user connecting the account"`
+	OAuthRedirectURI *string `json:"oauth_redirect_uri,omitempty" jsonschema:"description=Where OAuth connectors redirect the user after authorizing"`
 }
 
-func (t *PluggyConnectTokenTool) handleConnectToken(args ConnectTokenArgs) (*mcp.ToolResponse, error) {
-	if args.ItemID == "" {
-		errorMessage := "Invalid item_id parameter: must be a non-empty string"
-		return mcp.NewToolResponse(mcp.NewTextContent(errorMessage)), fmt.Errorf(errorMessage)
+func (t *PluggyConnectTokenTool) handleConnectToken(ctx context.Context, args ConnectTokenArgs) (*mcp.ToolResponse, error) {
+	opts := pluggy.ConnectTokenOptions{}
+
+	if args.ItemID != nil {
+		itemID, err := resolveID(ctx, *args.ItemID)
+		if err != nil {
+			return nil, err
+		}
+		opts.ItemID = itemID
+	}
+	if args.WebhookURL != nil {
+		opts.WebhookURL = *args.WebhookURL
+	}
+	if args.ClientUserID != nil {
+		opts.ClientUserID = *args.ClientUserID
+	}
+	if args.OAuthRedirectURI != nil {
+		opts.OAuthRedirectURI = *args.OAuthRedirectURI
+	}
+
+	if opts.ItemID != "" {
+		logger.Info("Generating connect token", "item_id", *args.ItemID)
+	} else {
+		logger.Info("Generating connect token for a new connection")
 	}
 
-	logger.Info("Generating connect token for item:", args.ItemID)
+	token, err := t.client.ConnectToken(ctx, opts)
+	if err != nil {
+		return nil, pluggyError(ctx, "Error generating connect token", err)
+	}
 
-	token, err := t.client.ConnectToken(args.ItemID)
+	tokenJSON, err := json.Marshal(token)
 	if err != nil {
-		errorMessage := fmt.Sprintf("Error generating connect token: %v", err)
-		return mcp.NewToolResponse(mcp.NewTextContent(errorMessage)), fmt.Errorf(errorMessage)
+		return nil, internalError(fmt.Sprintf("Error marshalling connect token: %v", err))
 	}
 
-	return mcp.NewToolResponse(mcp.NewTextContent(token)), nil
+	return mcp.NewToolResponse(mcp.NewTextContent(string(tokenJSON))), nil
 }
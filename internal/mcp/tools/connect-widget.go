@@ -0,0 +1,129 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	mcp "github.com/metoro-io/mcp-golang"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/connect"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/logger"
+	internalMcp "github.com/thunderjr/openfinance-mcp-server/internal/provider/mcp"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pluggy"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pseudonym"
+)
+
+type ConnectURLArgs struct {
+	ItemID *string `json:"item_id,omitempty" jsonschema:"description=The Pluggy item ID to update (e.g. to fix credentials). Omit to connect a new bank"`
+}
+
+type PluggyConnectURLTool struct {
+	host *connect.Server
+}
+
+func NewPluggyConnectURLTool(host *connect.Server) *PluggyConnectURLTool {
+	return &PluggyConnectURLTool{host}
+}
+
+func (t *PluggyConnectURLTool) Name() string {
+	return "pluggy_connect_url"
+}
+
+func (t *PluggyConnectURLTool) Description() string {
+	return "Returns a local link that opens Pluggy Connect so the user can connect a bank (or update an existing item). Share the URL with the user, then use pluggy_connect_status with the session ID to get the connected item ID"
+}
+
+func (t *PluggyConnectURLTool) Access() internalMcp.Access {
+	return internalMcp.AccessWrite
+}
+
+func (t *PluggyConnectURLTool) Handle() internalMcp.ToolHandlerFunc {
+	return t.handleConnectURL
+}
+
+func (t *PluggyConnectURLTool) handleConnectURL(ctx context.Context, args ConnectURLArgs) (*mcp.ToolResponse, error) {
+	opts := pluggy.ConnectTokenOptions{}
+	if args.ItemID != nil {
+		itemID, err := resolveID(ctx, *args.ItemID)
+		if err != nil {
+			return nil, err
+		}
+		opts.ItemID = itemID
+	}
+
+	session, err := t.host.NewSession(opts)
+	if err != nil {
+		return nil, pluggyError(ctx, "Error creating connect session", err)
+	}
+
+	logger.Info("Created connect session", "session_id", session.ID)
+
+	session, err = sanitize(ctx, t.Name(), pseudonym.Connect, session)
+	if err != nil {
+		return nil, internalError(fmt.Sprintf("Error sanitizing connect session: %v", err))
+	}
+
+	sessionJSON, err := json.Marshal(session)
+	if err != nil {
+		return nil, internalError(fmt.Sprintf("Error marshalling connect session: %v", err))
+	}
+
+	return mcp.NewToolResponse(mcp.NewTextContent(string(sessionJSON))), nil
+}
+
+type ConnectStatusArgs struct {
+	SessionID string `json:"session_id" jsonschema:"required,description=The session ID returned by pluggy_connect_url"`
+}
+
+type PluggyConnectStatusTool struct {
+	host *connect.Server
+}
+
+func NewPluggyConnectStatusTool(host *connect.Server) *PluggyConnectStatusTool {
+	return &PluggyConnectStatusTool{host}
+}
+
+func (t *PluggyConnectStatusTool) Name() string {
+	return "pluggy_connect_status"
+}
+
+func (t *PluggyConnectStatusTool) Description() string {
+	return "Returns the status of a Pluggy Connect session (PENDING, CONNECTED or FAILED) and the connected item ID once the user finishes"
+}
+
+func (t *PluggyConnectStatusTool) Access() internalMcp.Access {
+	return internalMcp.AccessRead
+}
+
+func (t *PluggyConnectStatusTool) Handle() internalMcp.ToolHandlerFunc {
+	return t.handleConnectStatus
+}
+
+func (t *PluggyConnectStatusTool) handleConnectStatus(ctx context.Context, args ConnectStatusArgs) (*mcp.ToolResponse, error) {
+	if args.SessionID == "" {
+		return nil, validationError("Session ID is required")
+	}
+
+	sessionID, err := resolveID(ctx, args.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, ok := t.host.Session(sessionID)
+	if !ok {
+		return nil, notFoundError("Unknown or expired connect session, create a new one with pluggy_connect_url")
+	}
+
+	session, err = sanitize(ctx, t.Name(), pseudonym.Connect, session)
+	if err != nil {
+		return nil, internalError(fmt.Sprintf("Error sanitizing connect session: %v", err))
+	}
+
+	sessionJSON, err := json.Marshal(session)
+	if err != nil {
+		return nil, internalError(fmt.Sprintf("Error marshalling connect session: %v", err))
+	}
+
+	return mcp.NewToolResponse(mcp.NewTextContent(string(sessionJSON))), nil
+}
@@ -0,0 +1,64 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	internalMcp "github.com/thunderjr/openfinance-mcp-server/internal/provider/mcp"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pluggy"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pseudonym"
+)
+
+func validationError(message string) error {
+	return internalMcp.NewToolError(internalMcp.ErrorValidation, message)
+}
+
+func notFoundError(message string) error {
+	return internalMcp.NewToolError(internalMcp.ErrorNotFound, message)
+}
+
+func internalError(message string) error {
+	return internalMcp.NewToolError(internalMcp.ErrorInternal, message)
+}
+
+// pluggyError reports a failed call to the Pluggy client, classified by the
+// status Pluggy answered with. Identifiers in the message, such as the ones in
+// request URLs, are replaced by the session's pseudonyms.
+func pluggyError(ctx context.Context, message string, err error) error {
+	text := pseudonym.Text(internalMcp.SessionID(ctx), fmt.Sprintf("%s: %v", message, err))
+	toolErr := internalMcp.NewToolError(internalMcp.ErrorUpstream, text)
+
+	// The server started without a working Pluggy or Redis and keeps trying
+	// to authorize in the background.
+	if errors.Is(err, pluggy.ErrNotAuthorized) {
+		toolErr.Message = pseudonym.Text(internalMcp.SessionID(ctx), fmt.Sprintf(
+			"%s: the server is not connected to Pluggy yet (%v), call server_health for details", message, err))
+		return toolErr
+	}
+
+	var apiErr *pluggy.APIError
+	if errors.As(err, &apiErr) {
+		toolErr.RequestID = apiErr.RequestID
+		switch {
+		case apiErr.StatusCode == http.StatusBadRequest || apiErr.StatusCode == http.StatusUnprocessableEntity:
+			toolErr.Code = internalMcp.ErrorValidation
+		case apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden:
+			toolErr.Code = internalMcp.ErrorAuth
+		case apiErr.StatusCode == http.StatusNotFound:
+			toolErr.Code = internalMcp.ErrorNotFound
+		case apiErr.StatusCode == http.StatusTooManyRequests:
+			toolErr.Code = internalMcp.ErrorRateLimited
+		}
+		toolErr.Retryable = toolErr.Code == internalMcp.ErrorRateLimited || apiErr.StatusCode >= 500
+		return toolErr
+	}
+
+	// Anything else that is not a network failure, like an undecodable
+	// response, will fail the same way again.
+	var netErr net.Error
+	toolErr.Retryable = errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded)
+	return toolErr
+}
@@ -0,0 +1,52 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	internalMcp "github.com/thunderjr/openfinance-mcp-server/internal/provider/mcp"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pluggy"
+)
+
+func TestPluggyError(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		wantCode      internalMcp.ErrorCode
+		wantRetryable bool
+		wantMessage   string
+		wantRequestID string
+	}{
+		{"bad request", &pluggy.APIError{Op: "get", StatusCode: http.StatusBadRequest, RequestID: "r1"}, internalMcp.ErrorValidation, false, "", "r1"},
+		{"unprocessable", &pluggy.APIError{StatusCode: http.StatusUnprocessableEntity}, internalMcp.ErrorValidation, false, "", ""},
+		{"unauthorized", &pluggy.APIError{StatusCode: http.StatusUnauthorized}, internalMcp.ErrorAuth, false, "", ""},
+		{"forbidden", &pluggy.APIError{StatusCode: http.StatusForbidden}, internalMcp.ErrorAuth, false, "", ""},
+		{"not found", &pluggy.APIError{StatusCode: http.StatusNotFound}, internalMcp.ErrorNotFound, false, "", ""},
+		{"rate limited", &pluggy.APIError{StatusCode: http.StatusTooManyRequests}, internalMcp.ErrorRateLimited, true, "", ""},
+		{"server error", &pluggy.APIError{StatusCode: http.StatusBadGateway}, internalMcp.ErrorUpstream, true, "", ""},
+		{"wrapped", fmt.Errorf("get accounts: %w", &pluggy.APIError{StatusCode: http.StatusNotFound}), internalMcp.ErrorNotFound, false, "", ""},
+		{"not authorized", pluggy.ErrNotAuthorized, internalMcp.ErrorUpstream, true, "call server_health", ""},
+		{"network", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, internalMcp.ErrorUpstream, true, "connection refused", ""},
+		{"deadline", context.DeadlineExceeded, internalMcp.ErrorUpstream, true, "", ""},
+		{"undecodable", errors.New("invalid character '<'"), internalMcp.ErrorUpstream, false, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var toolErr *internalMcp.ToolError
+			if !errors.As(pluggyError(context.Background(), "Error getting accounts", tt.err), &toolErr) {
+				t.Fatal("pluggyError did not return a ToolError")
+			}
+			if toolErr.Code != tt.wantCode || toolErr.Retryable != tt.wantRetryable || toolErr.RequestID != tt.wantRequestID {
+				t.Errorf("got %+v, want code %s, retryable %t, request ID %q", toolErr, tt.wantCode, tt.wantRetryable, tt.wantRequestID)
+			}
+			if !strings.HasPrefix(toolErr.Message, "Error getting accounts: ") || !strings.Contains(toolErr.Message, tt.wantMessage) {
+				t.Errorf("message = %q, want it to contain %q", toolErr.Message, tt.wantMessage)
+			}
+		})
+	}
+}
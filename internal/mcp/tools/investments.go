@@ -1,21 +1,40 @@
 package tools
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 
 	mcp "github.com/metoro-io/mcp-golang"
 
+	"github.com/thunderjr/openfinance-mcp-server/internal/mcp/output"
 	"github.com/thunderjr/openfinance-mcp-server/internal/provider/logger"
 	internalMcp "github.com/thunderjr/openfinance-mcp-server/internal/provider/mcp"
 	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pluggy"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pseudonym"
 )
 
 type InvestmentsArgs struct {
-	ItemID   string  `json:"item_id" jsonschema:"required,description=The ID of the item to retrieve investments for"`
-	Type     *string `json:"type,omitempty" jsonschema:"description=Filter investments by type (COE, EQUITY, ETF, FIXED_INCOME, MUTUAL_FUND, SECURITY, OTHER)"`
-	Page     *int    `json:"page,omitempty" jsonschema:"description=Page number for pagination (default: 1)"`
-	PageSize *int    `json:"page_size,omitempty" jsonschema:"description=Number of results per page (default: 20, max: 500)"`
+	ItemID          string  `json:"item_id" jsonschema:"required,description=The ID of the item to retrieve investments for"`
+	Type            *string `json:"type,omitempty" jsonschema:"description=Filter investments by type (COE, EQUITY, ETF, FIXED_INCOME, MUTUAL_FUND, SECURITY, OTHER)"`
+	Page            *int    `json:"page,omitempty" jsonschema:"description=Page number for pagination (default: 1)"`
+	PageSize        *int    `json:"page_size,omitempty" jsonschema:"description=Number of results per page (default: 20, max: 500)"`
+	Format          *string `json:"format,omitempty" jsonschema:"enum=json,enum=compact,enum=csv,enum=markdown,description=Output format (default: json). json returns the full payload; compact keeps only key fields; csv and markdown render the key fields as a table"`
+	MaxItems        *int    `json:"max_items,omitempty" jsonschema:"description=Return at most this many results; the rest of the page is summarized and can be fetched with next_cursor"`
+	MaxOutputTokens *int    `json:"max_output_tokens,omitempty" jsonschema:"description=Approximate token budget for the output; results are truncated to fit and the rest of the page is summarized"`
+	Cursor          *string `json:"cursor,omitempty" jsonschema:"description=next_cursor returned by a previous call to continue where it stopped. It carries the filters and replaces the page arguments; filters passed along with it must match"`
+}
+
+// investmentsQuery is the filter carried by a cursor.
+type investmentsQuery struct {
+	Type string `json:"type,omitempty"`
+}
+
+func newInvestmentsQuery(args InvestmentsArgs) investmentsQuery {
+	var q investmentsQuery
+	if args.Type != nil {
+		q.Type = *args.Type
+	}
+	return q
 }
 
 type PluggyInvestmentsTool struct {
@@ -31,47 +50,95 @@ func (t *PluggyInvestmentsTool) Name() string {
 }
 
 func (t *PluggyInvestmentsTool) Description() string {
-	return "Retrieves investments associated with a specific item with optional filters"
+	return "Retrieves investments associated with a specific item with optional filters. The result reports has_more; pass its next_cursor as cursor to get the following results"
+}
+
+func (t *PluggyInvestmentsTool) Access() internalMcp.Access {
+	return internalMcp.AccessRead
 }
 
 func (t *PluggyInvestmentsTool) Handle() internalMcp.ToolHandlerFunc {
 	return t.handleGetInvestments
 }
 
-func (t *PluggyInvestmentsTool) handleGetInvestments(args InvestmentsArgs) (*mcp.ToolResponse, error) {
+func (t *PluggyInvestmentsTool) handleGetInvestments(ctx context.Context, args InvestmentsArgs) (*mcp.ToolResponse, error) {
 	if args.ItemID == "" {
-		errorMessage := "Item ID is required"
-		return mcp.NewToolResponse(mcp.NewTextContent(errorMessage)), fmt.Errorf(errorMessage)
+		return nil, validationError("Item ID is required")
+	}
+
+	itemID, err := resolveID(ctx, args.ItemID)
+	if err != nil {
+		return nil, err
+	}
+
+	format, err := output.ParseFormat(args.Format)
+	if err != nil {
+		return nil, validationError(err.Error())
+	}
+
+	budget, err := output.ParseBudget(args.MaxItems, args.MaxOutputTokens)
+	if err != nil {
+		return nil, validationError(err.Error())
+	}
+
+	cursor, err := output.ParseCursor(args.Cursor, itemID)
+	if err != nil {
+		return nil, validationError(err.Error())
+	}
+
+	if cursor != nil {
+		if err := cursor.Matches(newInvestmentsQuery(args)); err != nil {
+			return nil, validationError(err.Error())
+		}
+		var query investmentsQuery
+		if err := cursor.Filter(&query); err != nil {
+			return nil, validationError(err.Error())
+		}
+		args.Type = &query.Type
 	}
 
 	filter := &pluggy.InvestmentsFilter{}
 
 	if args.Type != nil && *args.Type != "" {
 		filter.Type = pluggy.InvestmentType(*args.Type)
-		logger.Info("Filter investments by type:", filter.Type)
+		logger.Info("Filter investments", "type", filter.Type)
 	}
 
 	if args.Page != nil && *args.Page > 0 {
 		filter.Page = *args.Page
-		logger.Info("Investments page:", filter.Page)
+		logger.Info("Investments page", "page", filter.Page)
 	}
 
 	if args.PageSize != nil && *args.PageSize > 0 {
 		filter.PageSize = *args.PageSize
-		logger.Info("Investments page size:", filter.PageSize)
+		logger.Info("Investments page size", "page_size", filter.PageSize)
+	}
+
+	if cursor != nil {
+		filter.Page, filter.PageSize = cursor.Page(), cursor.PageSize
+		logger.Info("Continue from cursor", "offset", cursor.Offset)
+	}
+
+	investments, err := t.client.GetInvestments(ctx, itemID, filter)
+	if err != nil {
+		return nil, pluggyError(ctx, "Error getting investments", err)
 	}
 
-	investments, err := t.client.GetInvestments(args.ItemID, filter)
+	investments, err = sanitize(ctx, t.Name(), pseudonym.Investment, investments)
 	if err != nil {
-		errorMessage := fmt.Sprintf("Error getting investments: %v", err)
-		return mcp.NewToolResponse(mcp.NewTextContent(errorMessage)), fmt.Errorf(errorMessage)
+		return nil, internalError(fmt.Sprintf("Error sanitizing investments: %v", err))
 	}
 
-	investmentsJSON, err := json.Marshal(investments)
+	text, err := output.List(format, output.Investments, investments, output.Window{
+		Budget:   budget,
+		Cursor:   cursor,
+		Scope:    itemID,
+		Query:    investmentsQuery{Type: string(filter.Type)},
+		PageSize: filter.PageSize,
+	})
 	if err != nil {
-		errorMessage := fmt.Sprintf("Error marshalling investments: %v", err)
-		return mcp.NewToolResponse(mcp.NewTextContent(errorMessage)), fmt.Errorf(errorMessage)
+		return nil, internalError(fmt.Sprintf("Error rendering investments: %v", err))
 	}
 
-	return mcp.NewToolResponse(mcp.NewTextContent(string(investmentsJSON))), nil
+	return mcp.NewToolResponse(mcp.NewTextContent(text)), nil
 }
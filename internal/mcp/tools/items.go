@@ -1,6 +1,7 @@
 package tools
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/thunderjr/openfinance-mcp-server/internal/provider/logger"
 	internalMcp "github.com/thunderjr/openfinance-mcp-server/internal/provider/mcp"
 	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pluggy"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pseudonym"
 )
 
 type ItemArgs struct {
@@ -31,29 +33,85 @@ func (t *PluggyItemTool) Description() string {
 	return "Retrieves detailed information for a specific item"
 }
 
+func (t *PluggyItemTool) Access() internalMcp.Access {
+	return internalMcp.AccessRead
+}
+
 func (t *PluggyItemTool) Handle() internalMcp.ToolHandlerFunc {
 	return t.handleGetItem
 }
 
-func (t *PluggyItemTool) handleGetItem(args ItemArgs) (*mcp.ToolResponse, error) {
+func (t *PluggyItemTool) handleGetItem(ctx context.Context, args ItemArgs) (*mcp.ToolResponse, error) {
 	if args.ItemID == "" {
-		errorMessage := "Item ID is required"
-		return mcp.NewToolResponse(mcp.NewTextContent(errorMessage)), fmt.Errorf(errorMessage)
+		return nil, validationError("Item ID is required")
+	}
+
+	itemID, err := resolveID(ctx, args.ItemID)
+	if err != nil {
+		return nil, err
 	}
 
-	logger.Info("Getting item details for:", args.ItemID)
+	logger.Info("Getting item details", "item_id", args.ItemID)
 
-	item, err := t.client.GetItem(args.ItemID)
+	item, err := t.client.GetItem(ctx, itemID)
 	if err != nil {
-		errorMessage := fmt.Sprintf("Error getting item: %v", err)
-		return mcp.NewToolResponse(mcp.NewTextContent(errorMessage)), fmt.Errorf(errorMessage)
+		return nil, pluggyError(ctx, "Error getting item", err)
+	}
+
+	item, err = sanitize(ctx, t.Name(), pseudonym.Item, item)
+	if err != nil {
+		return nil, internalError(fmt.Sprintf("Error sanitizing item: %v", err))
 	}
 
 	itemJSON, err := json.Marshal(item)
 	if err != nil {
-		errorMessage := fmt.Sprintf("Error marshalling item: %v", err)
-		return mcp.NewToolResponse(mcp.NewTextContent(errorMessage)), fmt.Errorf(errorMessage)
+		return nil, internalError(fmt.Sprintf("Error marshalling item: %v", err))
 	}
 
 	return mcp.NewToolResponse(mcp.NewTextContent(string(itemJSON))), nil
 }
+
+type KnownItemsArgs struct{}
+
+type PluggyKnownItemsTool struct {
+	client *pluggy.Client
+}
+
+func NewPluggyKnownItemsTool(client *pluggy.Client) *PluggyKnownItemsTool {
+	return &PluggyKnownItemsTool{client}
+}
+
+func (t *PluggyKnownItemsTool) Name() string {
+	return "get_known_items"
+}
+
+func (t *PluggyKnownItemsTool) Description() string {
+	return "Lists the item IDs registered with this server, e.g. banks connected through pluggy_connect_url"
+}
+
+func (t *PluggyKnownItemsTool) Access() internalMcp.Access {
+	return internalMcp.AccessRead
+}
+
+func (t *PluggyKnownItemsTool) Handle() internalMcp.ToolHandlerFunc {
+	return t.handleGetKnownItems
+}
+
+func (t *PluggyKnownItemsTool) handleGetKnownItems(ctx context.Context, args KnownItemsArgs) (*mcp.ToolResponse, error) {
+	items, err := t.client.KnownItems(ctx)
+	if err != nil {
+		return nil, pluggyError(ctx, "Error getting known items", err)
+	}
+
+	items, err = sanitize(ctx, t.Name(), pseudonym.Item, items)
+	if err != nil {
+		return nil, internalError(fmt.Sprintf("Error sanitizing known items: %v", err))
+	}
+
+	itemsJSON, err := json.Marshal(items)
+	if err != nil {
+		return nil, internalError(fmt.Sprintf("Error marshalling known items: %v", err))
+	}
+
+	return mcp.NewToolResponse(mcp.NewTextContent(string(itemsJSON))), nil
+}
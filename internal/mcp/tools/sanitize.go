@@ -0,0 +1,29 @@
+package tools
+
+import (
+	"context"
+
+	internalMcp "github.com/thunderjr/openfinance-mcp-server/internal/provider/mcp"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pseudonym"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/redact"
+)
+
+// sanitize prepares Pluggy data for the model: personal data is redacted and,
+// when enabled, identifiers are replaced by the session's pseudonyms.
+func sanitize[T any](ctx context.Context, tool string, kind pseudonym.Kind, v T) (T, error) {
+	v, err := redact.Apply(tool, v)
+	if err != nil {
+		return v, err
+	}
+	return pseudonym.Apply(internalMcp.SessionID(ctx), kind, v)
+}
+
+// resolveID returns the Pluggy identifier behind an argument, which may be one
+// of the session's pseudonyms.
+func resolveID(ctx context.Context, value string) (string, error) {
+	id, err := pseudonym.Resolve(internalMcp.SessionID(ctx), value)
+	if err != nil {
+		return "", notFoundError(err.Error())
+	}
+	return id, nil
+}
@@ -0,0 +1,51 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	mcp "github.com/metoro-io/mcp-golang"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/health"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/logger"
+	internalMcp "github.com/thunderjr/openfinance-mcp-server/internal/provider/mcp"
+)
+
+type ServerHealthArgs struct{}
+
+type ServerHealthTool struct {
+	checker *health.Checker
+}
+
+func NewServerHealthTool(checker *health.Checker) *ServerHealthTool {
+	return &ServerHealthTool{checker}
+}
+
+func (t *ServerHealthTool) Name() string {
+	return "server_health"
+}
+
+func (t *ServerHealthTool) Description() string {
+	return "Checks the Pluggy credentials and API key, Redis, the rate limit, the status of the connected banks and the health of their institutions. Each check passes, warns or fails with a hint on how to fix it. Use it when other tools fail"
+}
+
+func (t *ServerHealthTool) Access() internalMcp.Access {
+	return internalMcp.AccessRead
+}
+
+func (t *ServerHealthTool) Handle() internalMcp.ToolHandlerFunc {
+	return t.handleServerHealth
+}
+
+func (t *ServerHealthTool) handleServerHealth(ctx context.Context, args ServerHealthArgs) (*mcp.ToolResponse, error) {
+	report := t.checker.Run(ctx)
+	logger.Info("Server health", "status", report.Status)
+
+	reportJSON, err := json.Marshal(report)
+	if err != nil {
+		return nil, internalError(fmt.Sprintf("Error marshalling health report: %v", err))
+	}
+
+	return mcp.NewToolResponse(mcp.NewTextContent(string(reportJSON))), nil
+}
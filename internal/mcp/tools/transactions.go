@@ -1,25 +1,56 @@
 package tools
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"time"
 
 	mcp "github.com/metoro-io/mcp-golang"
 
+	"github.com/thunderjr/openfinance-mcp-server/internal/mcp/output"
 	"github.com/thunderjr/openfinance-mcp-server/internal/provider/logger"
 	internalMcp "github.com/thunderjr/openfinance-mcp-server/internal/provider/mcp"
 	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pluggy"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pseudonym"
 )
 
 type TransactionsArgs struct {
-	AccountID   string    `json:"account_id" jsonschema:"required,description=The ID of the account to retrieve transactions for"`
-	From        *string   `json:"from,omitempty" jsonschema:"description=Filter transactions from this date (format: yyyy-mm-dd)"`
-	To          *string   `json:"to,omitempty" jsonschema:"description=Filter transactions to this date (format: yyyy-mm-dd)"`
-	Page        *int      `json:"page,omitempty" jsonschema:"description=Page number for pagination (default: 1)"`
-	PageSize    *int      `json:"page_size,omitempty" jsonschema:"description=Number of results per page (default: 20, max: 500)"`
-	CreatedFrom *string   `json:"created_from,omitempty" jsonschema:"description=Filter transactions created from this date (ISO 8601 format)"`
-	IDs         *[]string `json:"ids,omitempty" jsonschema:"description=Filter transactions by specific IDs"`
+	AccountID       string    `json:"account_id" jsonschema:"required,description=The ID of the account to retrieve transactions for"`
+	From            *string   `json:"from,omitempty" jsonschema:"description=Filter transactions from this date (format: yyyy-mm-dd)"`
+	To              *string   `json:"to,omitempty" jsonschema:"description=Filter transactions to this date (format: yyyy-mm-dd)"`
+	Page            *int      `json:"page,omitempty" jsonschema:"description=Page number for pagination (default: 1)"`
+	PageSize        *int      `json:"page_size,omitempty" jsonschema:"description=Number of results per page (default: 20, max: 500)"`
+	CreatedFrom     *string   `json:"created_from,omitempty" jsonschema:"description=Filter transactions created from this date (ISO 8601 format)"`
+	IDs             *[]string `json:"ids,omitempty" jsonschema:"description=Filter transactions by specific IDs"`
+	Format          *string   `json:"format,omitempty" jsonschema:"enum=json,enum=compact,enum=csv,enum=markdown,description=Output format (default: json). json returns the full payload; compact keeps only key fields; csv and markdown render the key fields as a table"`
+	MaxItems        *int      `json:"max_items,omitempty" jsonschema:"description=Return at most this many results; the rest of the page is summarized and can be fetched with next_cursor"`
+	MaxOutputTokens *int      `json:"max_output_tokens,omitempty" jsonschema:"description=Approximate token budget for the output; results are truncated to fit and the rest of the page is summarized"`
+	Cursor          *string   `json:"cursor,omitempty" jsonschema:"description=next_cursor returned by a previous call to continue where it stopped. It carries the filters and replaces the page arguments; filters passed along with it must match"`
+}
+
+// transactionsQuery is the filter carried by a cursor.
+type transactionsQuery struct {
+	From        string   `json:"from,omitempty"`
+	To          string   `json:"to,omitempty"`
+	CreatedFrom string   `json:"created_from,omitempty"`
+	IDs         []string `json:"ids,omitempty"`
+}
+
+func newTransactionsQuery(args TransactionsArgs) transactionsQuery {
+	var q transactionsQuery
+	if args.From != nil {
+		q.From = *args.From
+	}
+	if args.To != nil {
+		q.To = *args.To
+	}
+	if args.CreatedFrom != nil {
+		q.CreatedFrom = *args.CreatedFrom
+	}
+	if args.IDs != nil {
+		q.IDs = *args.IDs
+	}
+	return q
 }
 
 type PluggyTransactionsTool struct {
@@ -35,17 +66,51 @@ func (t *PluggyTransactionsTool) Name() string {
 }
 
 func (t *PluggyTransactionsTool) Description() string {
-	return "Retrieves transactions for a specific account with optional filters"
+	return "Retrieves transactions for a specific account with optional filters. The result reports has_more; pass its next_cursor as cursor to get the following results"
+}
+
+func (t *PluggyTransactionsTool) Access() internalMcp.Access {
+	return internalMcp.AccessRead
 }
 
 func (t *PluggyTransactionsTool) Handle() internalMcp.ToolHandlerFunc {
 	return t.handleGetTransactions
 }
 
-func (t *PluggyTransactionsTool) handleGetTransactions(args TransactionsArgs) (*mcp.ToolResponse, error) {
+func (t *PluggyTransactionsTool) handleGetTransactions(ctx context.Context, args TransactionsArgs) (*mcp.ToolResponse, error) {
 	if args.AccountID == "" {
-		errorMessage := "Account ID is required"
-		return mcp.NewToolResponse(mcp.NewTextContent(errorMessage)), fmt.Errorf(errorMessage)
+		return nil, validationError("Account ID is required")
+	}
+
+	accountID, err := resolveID(ctx, args.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	format, err := output.ParseFormat(args.Format)
+	if err != nil {
+		return nil, validationError(err.Error())
+	}
+
+	budget, err := output.ParseBudget(args.MaxItems, args.MaxOutputTokens)
+	if err != nil {
+		return nil, validationError(err.Error())
+	}
+
+	cursor, err := output.ParseCursor(args.Cursor, accountID)
+	if err != nil {
+		return nil, validationError(err.Error())
+	}
+
+	if cursor != nil {
+		if err := cursor.Matches(newTransactionsQuery(args)); err != nil {
+			return nil, validationError(err.Error())
+		}
+		var query transactionsQuery
+		if err := cursor.Filter(&query); err != nil {
+			return nil, validationError(err.Error())
+		}
+		args.From, args.To, args.CreatedFrom, args.IDs = &query.From, &query.To, &query.CreatedFrom, &query.IDs
 	}
 
 	filter := &pluggy.TransactionFilter{}
@@ -54,10 +119,9 @@ func (t *PluggyTransactionsTool) handleGetTransactions(args TransactionsArgs) (*
 		from, err := time.Parse("2006-01-02", *args.From)
 		if err == nil {
 			filter.From = from
-			logger.Info("Filter transactions from:", filter.From)
+			logger.Info("Filter transactions", "from", filter.From)
 		} else {
-			errorMessage := fmt.Sprintf("Invalid 'from' date format: %v", err)
-			return mcp.NewToolResponse(mcp.NewTextContent(errorMessage)), fmt.Errorf(errorMessage)
+			return nil, validationError(fmt.Sprintf("Invalid 'from' date format: %v", err))
 		}
 	}
 
@@ -65,50 +129,68 @@ func (t *PluggyTransactionsTool) handleGetTransactions(args TransactionsArgs) (*
 		to, err := time.Parse("2006-01-02", *args.To)
 		if err == nil {
 			filter.To = to
-			logger.Info("Filter transactions to:", filter.To)
+			logger.Info("Filter transactions", "to", filter.To)
 		} else {
-			errorMessage := fmt.Sprintf("Invalid 'to' date format: %v", err)
-			return mcp.NewToolResponse(mcp.NewTextContent(errorMessage)), fmt.Errorf(errorMessage)
+			return nil, validationError(fmt.Sprintf("Invalid 'to' date format: %v", err))
 		}
 	}
 
 	if args.Page != nil && *args.Page > 0 {
 		filter.Page = *args.Page
-		logger.Info("Transaction page:", filter.Page)
+		logger.Info("Transaction page", "page", filter.Page)
 	}
 
 	if args.PageSize != nil && *args.PageSize > 0 {
 		filter.PageSize = *args.PageSize
-		logger.Info("Transaction page size:", filter.PageSize)
+		logger.Info("Transaction page size", "page_size", filter.PageSize)
+	}
+
+	if cursor != nil {
+		filter.Page, filter.PageSize = cursor.Page(), cursor.PageSize
+		logger.Info("Continue from cursor", "offset", cursor.Offset)
 	}
 
 	if args.CreatedFrom != nil && *args.CreatedFrom != "" {
 		createdFrom, err := time.Parse(time.RFC3339, *args.CreatedFrom)
 		if err == nil {
 			filter.CreatedAtFrom = createdFrom
-			logger.Info("Filter transactions created from:", filter.CreatedAtFrom)
+			logger.Info("Filter transactions", "created_from", filter.CreatedAtFrom)
 		} else {
-			errorMessage := fmt.Sprintf("Invalid 'created_from' date format: %v", err)
-			return mcp.NewToolResponse(mcp.NewTextContent(errorMessage)), fmt.Errorf(errorMessage)
+			return nil, validationError(fmt.Sprintf("Invalid 'created_from' date format: %v", err))
 		}
 	}
 
 	if args.IDs != nil && len(*args.IDs) > 0 {
-		filter.IDs = *args.IDs
-		logger.Info("Filter by IDs:", filter.IDs)
+		for _, value := range *args.IDs {
+			id, err := resolveID(ctx, value)
+			if err != nil {
+				return nil, err
+			}
+			filter.IDs = append(filter.IDs, id)
+		}
+		logger.Info("Filter transactions", "ids", *args.IDs)
+	}
+
+	transactions, err := t.client.GetTransactions(ctx, accountID, filter)
+	if err != nil {
+		return nil, pluggyError(ctx, "Error getting transactions", err)
 	}
 
-	transactions, err := t.client.GetTransactions(args.AccountID, filter)
+	transactions, err = sanitize(ctx, t.Name(), pseudonym.Transaction, transactions)
 	if err != nil {
-		errorMessage := fmt.Sprintf("Error getting transactions: %v", err)
-		return mcp.NewToolResponse(mcp.NewTextContent(errorMessage)), fmt.Errorf(errorMessage)
+		return nil, internalError(fmt.Sprintf("Error sanitizing transactions: %v", err))
 	}
 
-	transactionsJSON, err := json.Marshal(transactions)
+	text, err := output.List(format, output.Transactions, transactions, output.Window{
+		Budget:   budget,
+		Cursor:   cursor,
+		Scope:    accountID,
+		Query:    newTransactionsQuery(args),
+		PageSize: filter.PageSize,
+	})
 	if err != nil {
-		errorMessage := fmt.Sprintf("Error marshalling transactions: %v", err)
-		return mcp.NewToolResponse(mcp.NewTextContent(errorMessage)), fmt.Errorf(errorMessage)
+		return nil, internalError(fmt.Sprintf("Error rendering transactions: %v", err))
 	}
 
-	return mcp.NewToolResponse(mcp.NewTextContent(string(transactionsJSON))), nil
+	return mcp.NewToolResponse(mcp.NewTextContent(text)), nil
 }
@@ -1,22 +1,27 @@
 package tools
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	mcp "github.com/metoro-io/mcp-golang"
 
 	"github.com/thunderjr/openfinance-mcp-server/internal/provider/logger"
 	internalMcp "github.com/thunderjr/openfinance-mcp-server/internal/provider/mcp"
 	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pluggy"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pseudonym"
 )
 
 type PluggyWaitItemUpdatedTool struct {
-	client *pluggy.Client
+	client     *pluggy.Client
+	connectURL bool // whether pluggy_connect_url is registered
 }
 
 func NewPluggyWaitItemUpdatedTool(client *pluggy.Client) *PluggyWaitItemUpdatedTool {
-	return &PluggyWaitItemUpdatedTool{client}
+	return &PluggyWaitItemUpdatedTool{client: client}
 }
 
 func (t *PluggyWaitItemUpdatedTool) Name() string {
@@ -24,7 +29,16 @@ func (t *PluggyWaitItemUpdatedTool) Name() string {
 }
 
 func (t *PluggyWaitItemUpdatedTool) Description() string {
-	return "Waits for an item to complete updating and returns final item status"
+	return "Waits for an item to finish updating and returns its final status with an explanation. Returns early when the item needs the user (e.g. WAITING_USER_INPUT or LOGIN_ERROR) and with timed_out once timeout_seconds expire; call it again to keep waiting"
+}
+
+func (t *PluggyWaitItemUpdatedTool) Access() internalMcp.Access {
+	return internalMcp.AccessRead
+}
+
+// Timeout lets the wait run for as long as its timeout_seconds allow.
+func (t *PluggyWaitItemUpdatedTool) Timeout() time.Duration {
+	return maxWaitTimeout + 30*time.Second
 }
 
 func (t *PluggyWaitItemUpdatedTool) Handle() internalMcp.ToolHandlerFunc {
@@ -32,34 +46,114 @@ func (t *PluggyWaitItemUpdatedTool) Handle() internalMcp.ToolHandlerFunc {
 }
 
 type WaitItemUpdatedArgs struct {
-	ItemID string `json:"item_id" jsonschema:"required,description=The Pluggy item ID to wait for update completion"`
+	ItemID         string `json:"item_id" jsonschema:"required,description=The Pluggy item ID to wait for update completion"`
+	TimeoutSeconds *int   `json:"timeout_seconds,omitempty" jsonschema:"description=Stop waiting after this many seconds and return the current status (default: 120; max: 600)"`
 }
 
-func (t *PluggyWaitItemUpdatedTool) handleWaitItemUpdated(args WaitItemUpdatedArgs) (*mcp.ToolResponse, error) {
+const (
+	defaultWaitTimeout = 120 * time.Second
+	maxWaitTimeout     = 600 * time.Second
+)
+
+type waitItemResult struct {
+	Status          string       `json:"status"`
+	ExecutionStatus string       `json:"execution_status,omitempty"`
+	TimedOut        bool         `json:"timed_out,omitempty"`
+	Explanation     string       `json:"explanation"`
+	Item            *pluggy.Item `json:"item"`
+}
+
+func (t *PluggyWaitItemUpdatedTool) handleWaitItemUpdated(ctx context.Context, args WaitItemUpdatedArgs) (*mcp.ToolResponse, error) {
 	if args.ItemID == "" {
-		errorMessage := "Invalid item_id parameter: must be a non-empty string"
-		return mcp.NewToolResponse(mcp.NewTextContent(errorMessage)), fmt.Errorf(errorMessage)
+		return nil, validationError("Invalid item_id parameter: must be a non-empty string")
 	}
 
-	logger.Info("Waiting for item to update:", args.ItemID)
+	timeout := defaultWaitTimeout
+	if args.TimeoutSeconds != nil {
+		timeout = time.Duration(*args.TimeoutSeconds) * time.Second
+		if timeout <= 0 || timeout > maxWaitTimeout {
+			return nil, validationError(fmt.Sprintf("Invalid timeout_seconds %d: must be between 1 and %d", *args.TimeoutSeconds, int(maxWaitTimeout.Seconds())))
+		}
+	}
 
-	err := t.client.WaitUpdated(args.ItemID)
+	itemID, err := resolveID(ctx, args.ItemID)
 	if err != nil {
-		errorMessage := fmt.Sprintf("Error waiting for item update: %v", err)
-		return mcp.NewToolResponse(mcp.NewTextContent(errorMessage)), fmt.Errorf(errorMessage)
+		return nil, err
+	}
+
+	logger.Info("Waiting for item to update", "item_id", args.ItemID, "timeout", timeout)
+
+	item, err := t.client.WaitUpdated(ctx, itemID, pluggy.WaitOptions{
+		Timeout: timeout,
+		OnChange: func(item *pluggy.Item) {
+			internalMcp.Progress(ctx, fmt.Sprintf("Item status %s, execution status %s", item.Status, item.ExecutionStatus))
+		},
+	})
+	timedOut := errors.Is(err, pluggy.ErrStillUpdating)
+	if err != nil && !timedOut {
+		if ctx.Err() != nil {
+			logger.Info("Stopped waiting for item, the client canceled", "item_id", args.ItemID)
+		}
+		return nil, pluggyError(ctx, "Error waiting for item update", err)
 	}
 
-	item, err := t.client.GetItem(args.ItemID)
+	result := waitItemResult{
+		Status:          item.Status,
+		ExecutionStatus: item.ExecutionStatus,
+		TimedOut:        timedOut,
+		Explanation:     t.explainItemStatus(item, timedOut, timeout),
+	}
+
+	result.Item, err = sanitize(ctx, t.Name(), pseudonym.Item, item)
 	if err != nil {
-		errorMessage := fmt.Sprintf("Error getting updated item: %v", err)
-		return mcp.NewToolResponse(mcp.NewTextContent(errorMessage)), fmt.Errorf(errorMessage)
+		return nil, internalError(fmt.Sprintf("Error sanitizing item: %v", err))
 	}
 
-	strItem, err := json.Marshal(item)
+	resultJSON, err := json.Marshal(result)
 	if err != nil {
-		errorMessage := fmt.Sprintf("Error marshalling updated item: %v", err)
-		return mcp.NewToolResponse(mcp.NewTextContent(errorMessage)), fmt.Errorf(errorMessage)
+		return nil, internalError(fmt.Sprintf("Error marshalling updated item: %v", err))
+	}
+
+	return mcp.NewToolResponse(mcp.NewTextContent(string(resultJSON))), nil
+}
+
+// SetConnectURL tells whether explanations can send the user to
+// pluggy_connect_url, which is only registered with a Connect host and a
+// profile allowing it.
+func (t *PluggyWaitItemUpdatedTool) SetConnectURL(enabled bool) {
+	t.connectURL = enabled
+}
+
+// explainItemStatus tells the model what the final state of a wait means and
+// what to do next.
+func (t *PluggyWaitItemUpdatedTool) explainItemStatus(item *pluggy.Item, timedOut bool, timeout time.Duration) string {
+	connect := "Pluggy Connect"
+	if t.connectURL {
+		connect = "Pluggy Connect (pluggy_connect_url with this item_id)"
+	}
+
+	reason := ""
+	if item.Error != nil && item.Error.Message != "" {
+		reason = " Pluggy says: " + item.Error.Message
 	}
 
-	return mcp.NewToolResponse(mcp.NewTextContent(string(strItem))), nil
+	if timedOut {
+		return fmt.Sprintf("The item is still updating after %s (execution status %s). Call pluggy_wait_item_updated again to keep waiting.", timeout, item.ExecutionStatus)
+	}
+
+	switch pluggy.ItemStatus(item.Status) {
+	case pluggy.ItemStatusUpdated:
+		if item.ExecutionStatus == "PARTIAL_SUCCESS" {
+			return "The item finished updating but some products could not be collected, so part of its data may be stale." + reason
+		}
+		return "The item finished updating and its data is up to date."
+	case pluggy.ItemStatusWaitingUserInput:
+		return "The institution is waiting for the user, e.g. for an MFA code or an authorization in their bank app. Ask the user to finish it in " + connect + " and wait again." + reason
+	case pluggy.ItemStatusError:
+		return fmt.Sprintf("Pluggy could not log in to the institution (execution status %s), usually because the credentials changed or the consent expired. Ask the user to update the connection in %s.%s", item.ExecutionStatus, connect, reason)
+	case pluggy.ItemStatusOutdated:
+		return fmt.Sprintf("The last update failed (execution status %s), so the item keeps the data of its previous update. It may be a temporary problem at the institution; try again later.%s", item.ExecutionStatus, reason)
+	default:
+		return fmt.Sprintf("The item stopped updating with status %s and execution status %s.%s", item.Status, item.ExecutionStatus, reason)
+	}
 }
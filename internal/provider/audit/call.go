@@ -0,0 +1,56 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Call collects what a tool call did while it runs, such as the Pluggy
+// endpoints it hit.
+type Call struct {
+	Start     time.Time
+	Tool      string
+	Arguments json.RawMessage
+	Session   string
+	Client    string
+
+	mu        sync.Mutex
+	endpoints []string
+}
+
+type callKey struct{}
+
+// WithCall returns a context whose Pluggy requests are recorded in call.
+func WithCall(ctx context.Context, call *Call) context.Context {
+	return context.WithValue(ctx, callKey{}, call)
+}
+
+// CallFrom returns the call recorded in ctx, if any.
+func CallFrom(ctx context.Context) (*Call, bool) {
+	call, ok := ctx.Value(callKey{}).(*Call)
+	return call, ok
+}
+
+// Endpoint records that the call behind ctx hit endpoint, e.g.
+// "GET /accounts". It does nothing outside of a tool call.
+func Endpoint(ctx context.Context, endpoint string) {
+	call, ok := CallFrom(ctx)
+	if !ok {
+		return
+	}
+
+	call.mu.Lock()
+	defer call.mu.Unlock()
+	if !slices.Contains(call.endpoints, endpoint) {
+		call.endpoints = append(call.endpoints, endpoint)
+	}
+}
+
+func (c *Call) Endpoints() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.endpoints)
+}
@@ -0,0 +1,81 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Entry is one line of the audit trail, written when a tool call returns.
+type Entry struct {
+	Time        time.Time       `json:"time"`
+	Tool        string          `json:"tool"`
+	Arguments   json.RawMessage `json:"arguments,omitempty"`
+	Session     string          `json:"session"`
+	Client      string          `json:"client,omitempty"`
+	Endpoints   []string        `json:"endpoints,omitempty"`
+	LatencyMS   int64           `json:"latency_ms"`
+	ResultBytes int             `json:"result_bytes"`
+	Outcome     Outcome         `json:"outcome"`
+	ErrorCode   string          `json:"error_code,omitempty"`
+}
+
+type Outcome string
+
+const (
+	OutcomeOK    Outcome = "ok"
+	OutcomeError Outcome = "error"
+)
+
+// Options tell where the audit trail goes and when its file is rotated.
+type Options struct {
+	Path       string // empty disables the audit trail
+	MaxSizeMB  int    // rotate once the file reaches this size
+	MaxAgeDays int    // remove rotated files older than this, 0 keeps them
+	MaxBackups int    // keep at most this many rotated files, 0 keeps them all
+}
+
+// Log appends entries to a JSONL file, separate from the free-form log.
+type Log struct {
+	mu  sync.Mutex
+	out io.WriteCloser
+}
+
+// Open returns the audit log described by opts, or nil when it is disabled.
+func Open(opts Options) *Log {
+	if opts.Path == "" {
+		return nil
+	}
+	return &Log{out: &lumberjack.Logger{
+		Filename:   opts.Path,
+		MaxSize:    opts.MaxSizeMB,
+		MaxAge:     opts.MaxAgeDays,
+		MaxBackups: opts.MaxBackups,
+		LocalTime:  true,
+	}}
+}
+
+func (l *Log) Write(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("audit: error encoding entry: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.out.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("audit: error writing entry: %w", err)
+	}
+	return nil
+}
+
+func (l *Log) Close() error {
+	if l == nil {
+		return nil
+	}
+	return l.out.Close()
+}
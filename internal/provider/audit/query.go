@@ -0,0 +1,91 @@
+package audit
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Query selects audit entries. Zero fields match everything.
+type Query struct {
+	Since   time.Time
+	Until   time.Time
+	Tool    string
+	Session string
+	Outcome Outcome
+}
+
+func (q Query) matches(e Entry) bool {
+	return (q.Since.IsZero() || !e.Time.Before(q.Since)) &&
+		(q.Until.IsZero() || e.Time.Before(q.Until)) &&
+		(q.Tool == "" || e.Tool == q.Tool) &&
+		(q.Session == "" || e.Session == q.Session) &&
+		(q.Outcome == "" || e.Outcome == q.Outcome)
+}
+
+// Read returns the entries matching q from the audit log at path and its
+// rotated files, oldest first.
+func Read(path string, q Query) ([]Entry, error) {
+	ext := filepath.Ext(path)
+	backups, err := filepath.Glob(strings.TrimSuffix(path, ext) + "-*" + ext + "*")
+	if err != nil {
+		return nil, fmt.Errorf("audit: error listing rotated files: %w", err)
+	}
+
+	var entries []Entry
+	for _, file := range append(backups, path) {
+		found, err := readFile(file, q)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, found...)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Time.Before(entries[j].Time) })
+	return entries, nil
+}
+
+func readFile(path string, q Query) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("audit: error reading %s: %w", path, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	var entries []Entry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("audit: %s:%d: %w", path, line, err)
+		}
+		if q.matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("audit: error reading %s: %w", path, err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,129 @@
+package cassette
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+)
+
+const redacted = "REDACTED"
+
+var secretHeaders = []string{"X-Api-Key", "Authorization", "Cookie", "Set-Cookie"}
+
+// secretFields are replaced wherever they appear in a JSON body.
+var secretFields = map[string]bool{
+	"apiKey":       true,
+	"accessToken":  true,
+	"clientId":     true,
+	"clientSecret": true,
+}
+
+// piiFields are replaced wherever they appear in a JSON body.
+var piiFields = map[string]bool{
+	"taxNumber":      true,
+	"accountNumber":  true,
+	"branchNumber":   true,
+	"routingNumber":  true,
+	"transferNumber": true,
+	"cardNumber":     true,
+	"cnpj":           true,
+	"issuerCNPJ":     true,
+	"owner":          true,
+}
+
+// piiNestedFields are replaced only below the given parent key, where the
+// generic field name carries personal data.
+var piiNestedFields = map[string]map[string]bool{
+	"payer":          {"name": true},
+	"receiver":       {"name": true},
+	"documentNumber": {"value": true},
+}
+
+// accountFields are replaced on objects that look like a Pluggy account.
+var accountFields = map[string]bool{
+	"number": true,
+}
+
+func scrubHeader(h http.Header) http.Header {
+	out := h.Clone()
+	for _, k := range secretHeaders {
+		if out.Get(k) != "" {
+			out.Set(k, redacted)
+		}
+	}
+	return out
+}
+
+func scrubURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	q := u.Query()
+	for k := range q {
+		if secretFields[k] || piiFields[k] {
+			q.Set(k, redacted)
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// scrubBody returns the body with secrets and personal data replaced. Non-JSON
+// bodies are dropped entirely since they cannot be scrubbed field by field.
+func scrubBody(body []byte) json.RawMessage {
+	if len(body) == 0 {
+		return nil
+	}
+
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		data, _ := json.Marshal(redacted)
+		return data
+	}
+
+	data, err := json.Marshal(scrubValue("", v))
+	if err != nil {
+		data, _ = json.Marshal(redacted)
+	}
+	return data
+}
+
+func scrubValue(parent string, v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		_, isAccount := val["itemId"]
+		if _, ok := val["subtype"]; !ok {
+			isAccount = false
+		}
+
+		for k, child := range val {
+			switch {
+			case child == nil:
+			case secretFields[k], piiFields[k], piiNestedFields[parent][k], isAccount && accountFields[k]:
+				val[k] = redactValue(child)
+			default:
+				val[k] = scrubValue(k, child)
+			}
+		}
+		return val
+	case []any:
+		for i, child := range val {
+			val[i] = scrubValue(parent, child)
+		}
+		return val
+	default:
+		return v
+	}
+}
+
+func redactValue(v any) any {
+	switch v.(type) {
+	case string:
+		return redacted
+	case map[string]any, []any:
+		return scrubValue("", v)
+	default:
+		return v
+	}
+}
@@ -0,0 +1,128 @@
+package cassette
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestScrubHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		value  string
+	}{
+		{"api key", "X-API-KEY", "eyJhbGciOiJIUzI1NiJ9.secret"},
+		{"authorization", "Authorization", "Bearer secret"},
+		{"cookie", "Cookie", "session=secret"},
+		{"set cookie", "Set-Cookie", "session=secret; HttpOnly"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.Header{}
+			h.Set(tt.header, tt.value)
+			h.Set("Content-Type", "application/json")
+
+			out := scrubHeader(h)
+			if got := out.Get(tt.header); got != redacted {
+				t.Errorf("%s = %q, want %q", tt.header, got, redacted)
+			}
+			if got := out.Get("Content-Type"); got != "application/json" {
+				t.Errorf("Content-Type = %q, want it kept", got)
+			}
+			if h.Get(tt.header) != tt.value {
+				t.Errorf("scrubHeader modified the original header")
+			}
+		})
+	}
+}
+
+func TestScrubURL(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{"secret query", "https://api.pluggy.ai/auth?clientSecret=s3cr3t&x=1", "https://api.pluggy.ai/auth?clientSecret=REDACTED&x=1"},
+		{"pii query", "https://api.pluggy.ai/identity?taxNumber=12345678909", "https://api.pluggy.ai/identity?taxNumber=REDACTED"},
+		{"plain query", "https://api.pluggy.ai/transactions?accountId=a1&page=2", "https://api.pluggy.ai/transactions?accountId=a1&page=2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scrubURL(tt.raw); got != tt.want {
+				t.Errorf("scrubURL(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScrubBody(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		secrets []string
+		kept    []string
+	}{
+		{
+			name:    "auth request",
+			body:    `{"clientId":"my-client-id","clientSecret":"my-client-secret"}`,
+			secrets: []string{"my-client-id", "my-client-secret"},
+		},
+		{
+			name:    "auth response",
+			body:    `{"apiKey":"eyJhbGciOiJIUzI1NiJ9.api-key"}`,
+			secrets: []string{"api-key"},
+		},
+		{
+			name:    "connect token response",
+			body:    `{"accessToken":"eyJhbGciOiJIUzI1NiJ9.connect-token"}`,
+			secrets: []string{"connect-token"},
+		},
+		{
+			name:    "account",
+			body:    `{"results":[{"id":"acc-1","itemId":"item-1","subtype":"CHECKING_ACCOUNT","number":"0001/12345-0","owner":"Maria Silva","taxNumber":"123.456.789-09","balance":10.5}]}`,
+			secrets: []string{"12345-0", "Maria Silva", "123.456.789-09"},
+			kept:    []string{"acc-1", "item-1", "10.5"},
+		},
+		{
+			name:    "transaction payer",
+			body:    `{"paymentData":{"payer":{"name":"Joao Souza","documentNumber":{"type":"CPF","value":"98765432100"}}},"description":"Coffee"}`,
+			secrets: []string{"Joao Souza", "98765432100"},
+			kept:    []string{"CPF", "Coffee"},
+		},
+		{
+			name: "number outside an account",
+			body: `{"number":42,"installments":{"number":3}}`,
+			kept: []string{"42", "3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(scrubBody([]byte(tt.body)))
+			if !json.Valid([]byte(got)) {
+				t.Fatalf("scrubBody returned invalid JSON: %s", got)
+			}
+			for _, s := range tt.secrets {
+				if strings.Contains(got, s) {
+					t.Errorf("scrubbed body still contains %q: %s", s, got)
+				}
+			}
+			for _, s := range tt.kept {
+				if !strings.Contains(got, s) {
+					t.Errorf("scrubbed body lost %q: %s", s, got)
+				}
+			}
+		})
+	}
+}
+
+func TestScrubBodyNonJSON(t *testing.T) {
+	got := string(scrubBody([]byte("clientSecret=my-client-secret")))
+	if got != `"REDACTED"` {
+		t.Errorf("scrubBody(non-JSON) = %s, want the whole body redacted", got)
+	}
+	if scrubBody(nil) != nil {
+		t.Errorf("scrubBody(nil) should stay empty")
+	}
+}
@@ -0,0 +1,163 @@
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// pluggy answers every request with the given body and counts the calls.
+func pluggy(body string, calls *int) http.RoundTripper {
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		*calls++
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})
+}
+
+func get(t *testing.T, rt http.RoundTripper, url string) (*http.Response, error) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-API-KEY", "live-api-key")
+	return rt.RoundTrip(req)
+}
+
+func TestParseMode(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Mode
+		wantErr bool
+	}{
+		{"", ModeOff, false},
+		{"record", ModeRecord, false},
+		{" Replay ", ModeReplay, false},
+		{"rewind", ModeOff, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseMode(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseMode(%q) = %q, %v; want %q, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestRecordReplay(t *testing.T) {
+	dir := t.TempDir()
+	calls := 0
+	body := `{"id":"item-1","status":"UPDATED"}`
+
+	recorder := NewTransport(ModeRecord, dir, pluggy(body, &calls))
+	for i := 0; i < 2; i++ {
+		res, err := get(t, recorder, "https://api.pluggy.ai/items/item-1")
+		if err != nil {
+			t.Fatalf("record: %v", err)
+		}
+		got, _ := io.ReadAll(res.Body)
+		if string(got) != body {
+			t.Errorf("record returned %s, want the live body", got)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("recorder made %d live calls, want 2", calls)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 2 {
+		t.Fatalf("recorded %d files, want 2", len(files))
+	}
+	for _, f := range files {
+		data, _ := os.ReadFile(f)
+		if bytes.Contains(data, []byte("live-api-key")) {
+			t.Errorf("%s contains the API key", filepath.Base(f))
+		}
+	}
+
+	player := NewTransport(ModeReplay, dir, roundTripFunc(func(*http.Request) (*http.Response, error) {
+		t.Fatal("replay must not reach the network")
+		return nil, nil
+	}))
+	for i := 0; i < 2; i++ {
+		res, err := get(t, player, "https://api.pluggy.ai/items/item-1")
+		if err != nil {
+			t.Fatalf("replay %d: %v", i, err)
+		}
+		got, _ := io.ReadAll(res.Body)
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, got); err != nil {
+			t.Fatalf("replay %d returned invalid JSON: %v", i, err)
+		}
+		if compact.String() != body || res.StatusCode != http.StatusOK {
+			t.Errorf("replay %d = %d %s, want 200 %s", i, res.StatusCode, got, body)
+		}
+	}
+}
+
+func TestReplayExhausted(t *testing.T) {
+	dir := t.TempDir()
+	calls := 0
+	if _, err := get(t, NewTransport(ModeRecord, dir, pluggy(`{}`, &calls)), "https://api.pluggy.ai/items/item-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	player := NewTransport(ModeReplay, dir, nil)
+	if _, err := get(t, player, "https://api.pluggy.ai/items/item-1"); err != nil {
+		t.Fatalf("first replay: %v", err)
+	}
+	_, err := get(t, player, "https://api.pluggy.ai/items/item-1")
+	if !errors.Is(err, ErrExhausted) {
+		t.Errorf("second replay error = %v, want ErrExhausted", err)
+	}
+
+	_, err = get(t, player, "https://api.pluggy.ai/items/item-2")
+	if err == nil || errors.Is(err, ErrExhausted) {
+		t.Errorf("unrecorded request error = %v, want a missing interaction error", err)
+	}
+}
+
+func TestKey(t *testing.T) {
+	tr := NewTransport(ModeReplay, "", nil)
+	key := func(raw string) string {
+		req, err := http.NewRequest(http.MethodGet, raw, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tr.key(req, nil)
+	}
+
+	tests := []struct {
+		name string
+		a, b string
+		same bool
+	}{
+		{"query order", "https://api.pluggy.ai/transactions?accountId=a&page=1", "https://api.pluggy.ai/transactions?page=1&accountId=a", true},
+		{"dates", "https://api.pluggy.ai/transactions?accountId=a&from=2024-01-01&to=2024-01-31", "https://api.pluggy.ai/transactions?accountId=a&from=2025-06-01&to=2025-06-30", true},
+		{"datetimes", "https://api.pluggy.ai/transactions?from=2024-01-01T00:00:00Z", "https://api.pluggy.ai/transactions?from=2025-02-03T10:11:12-03:00", true},
+		{"account", "https://api.pluggy.ai/transactions?accountId=a", "https://api.pluggy.ai/transactions?accountId=b", false},
+		{"page", "https://api.pluggy.ai/transactions?page=1", "https://api.pluggy.ai/transactions?page=2", false},
+		{"path", "https://api.pluggy.ai/items/a", "https://api.pluggy.ai/items/b", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := key(tt.a) == key(tt.b); got != tt.same {
+				t.Errorf("key(%q) == key(%q) is %v, want %v", tt.a, tt.b, got, tt.same)
+			}
+		})
+	}
+}
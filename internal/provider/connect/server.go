@@ -0,0 +1,304 @@
+package connect
+
+import (
+	"context"
+	"crypto/rand"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/logger"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pluggy"
+)
+
+const pluggyConnectScript = "https://cdn.pluggy.ai/pluggy-connect/v2.7.0/pluggy-connect.js"
+
+// Sessions outlive the connect token they start with because the page mints
+// a fresh token every time it is opened.
+const sessionLifetime = 2 * time.Hour
+
+// Opening the page mints a connect token, so writes leave room for a slow
+// Pluggy response.
+const (
+	readTimeout  = 30 * time.Second
+	writeTimeout = 60 * time.Second
+	idleTimeout  = 2 * time.Minute
+)
+
+//go:embed page.html
+var pageHTML string
+
+var page = template.Must(template.New("connect").Parse(pageHTML))
+
+type SessionStatus string
+
+const (
+	SessionPending   SessionStatus = "PENDING"
+	SessionConnected SessionStatus = "CONNECTED"
+	SessionFailed    SessionStatus = "FAILED"
+)
+
+type Session struct {
+	ID        string        `json:"id"`
+	URL       string        `json:"url"`
+	ItemID    string        `json:"itemId,omitempty"`
+	Status    SessionStatus `json:"status"`
+	Error     string        `json:"error,omitempty"`
+	CreatedAt time.Time     `json:"createdAt"`
+
+	options pluggy.ConnectTokenOptions
+}
+
+// Server hosts the Pluggy Connect widget on a local page, so a chat user can
+// connect a bank by clicking a link instead of embedding the widget somewhere.
+type Server struct {
+	client    *pluggy.Client
+	addr      string
+	publicURL string
+	baseURL   string
+	mu        sync.Mutex
+	sessions  map[string]*Session
+}
+
+// NewServer returns a widget host listening on addr. Links point to
+// publicURL when set, which is how users reach a host behind a proxy or on
+// another machine, and to the listener itself otherwise.
+func NewServer(client *pluggy.Client, addr, publicURL string) *Server {
+	return &Server{
+		client:    client,
+		addr:      addr,
+		publicURL: strings.TrimSuffix(publicURL, "/"),
+		sessions:  make(map[string]*Session),
+	}
+}
+
+// Start binds the listener and serves in the background.
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("connect: error listening on %s: %w", s.addr, err)
+	}
+	s.baseURL = s.publicURL
+	if s.baseURL == "" {
+		addr := listener.Addr().(*net.TCPAddr)
+		if addr.IP.IsUnspecified() {
+			logger.Warn("[connect] widget host bound to every interface but links point to the loopback, set connect.public_url for remote users")
+		}
+		s.baseURL = "http://" + linkHost(addr)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /connect/{session}", s.handlePage)
+	mux.HandleFunc("POST /connect/{session}/success", s.handleSuccess)
+	mux.HandleFunc("POST /connect/{session}/error", s.handleError)
+
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       readTimeout,
+		WriteTimeout:      writeTimeout,
+		IdleTimeout:       idleTimeout,
+	}
+
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Errorf("[connect] widget host stopped: %v", err)
+		}
+	}()
+
+	logger.Infof("[connect] widget host listening on %s", s.baseURL)
+	return nil
+}
+
+// linkHost is the host:port of addr that links can point to: wildcard binds
+// such as ":8090" or "0.0.0.0:8090" are reached through the loopback.
+func linkHost(addr *net.TCPAddr) string {
+	ip := addr.IP
+	if ip == nil || ip.IsUnspecified() {
+		ip = net.IPv4(127, 0, 0, 1)
+	}
+	return net.JoinHostPort(ip.String(), strconv.Itoa(addr.Port))
+}
+
+// NewSession returns a session whose URL opens Pluggy Connect, updating
+// opts.ItemID when set or connecting a new item otherwise.
+func (s *Server) NewSession(opts pluggy.ConnectTokenOptions) (*Session, error) {
+	id, err := newSessionID()
+	if err != nil {
+		return nil, err
+	}
+	if opts.ItemID == "" && opts.ClientUserID == "" {
+		// Pluggy stamps the item with it, which is how handleSuccess tells
+		// the item created through this session from any other.
+		opts.ClientUserID = "connect-" + id
+	}
+
+	session := &Session{
+		ID:        id,
+		URL:       fmt.Sprintf("%s/connect/%s", s.baseURL, id),
+		ItemID:    opts.ItemID,
+		Status:    SessionPending,
+		CreatedAt: time.Now(),
+		options:   opts,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.evictExpired()
+	s.sessions[id] = session
+
+	return session, nil
+}
+
+func (s *Server) Session(id string) (Session, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[id]
+	if !ok {
+		return Session{}, false
+	}
+	return *session, true
+}
+
+func (s *Server) handlePage(w http.ResponseWriter, r *http.Request) {
+	session, ok := s.Session(r.PathValue("session"))
+	if !ok {
+		http.Error(w, "This connect link has expired, ask your assistant for a new one.", http.StatusNotFound)
+		return
+	}
+
+	token, err := s.client.ConnectToken(r.Context(), session.options)
+	if err != nil {
+		logger.Errorf("[connect] error creating connect token: %v", err)
+		http.Error(w, "Could not create a Pluggy Connect token, please try again.", http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	err = page.Execute(w, map[string]string{
+		"Script":       pluggyConnectScript,
+		"ConnectToken": token.AccessToken,
+		"ItemID":       session.options.ItemID,
+		"CallbackPath": "/connect/" + session.ID,
+	})
+	if err != nil {
+		logger.Errorf("[connect] error rendering page: %v", err)
+	}
+}
+
+func (s *Server) handleSuccess(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		ItemID string `json:"itemId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.ItemID == "" {
+		http.Error(w, "itemId is required", http.StatusBadRequest)
+		return
+	}
+
+	session, ok := s.Session(r.PathValue("session"))
+	if !ok {
+		http.Error(w, "unknown connect session", http.StatusNotFound)
+		return
+	}
+
+	// Anyone holding the link can post here, so the item is only trusted once
+	// Pluggy confirms it belongs to this session.
+	if status, err := s.confirmItem(r.Context(), session, body.ItemID); err != nil {
+		logger.Warn("[connect] rejected item", "item_id", body.ItemID, "error", err)
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	if err := s.client.RegisterItem(r.Context(), body.ItemID); err != nil {
+		logger.Errorf("[connect] error registering item %s: %v", body.ItemID, err)
+	}
+
+	err := s.update(session.ID, func(session *Session) {
+		session.Status = SessionConnected
+		session.ItemID = body.ItemID
+		session.Error = ""
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	logger.Info("[connect] item connected", "item_id", body.ItemID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// confirmItem checks with Pluggy that itemID is the item the session updates,
+// or one created with the session's connect token. It returns the HTTP status
+// to answer with when it is not.
+func (s *Server) confirmItem(ctx context.Context, session Session, itemID string) (int, error) {
+	if session.options.ItemID != "" && itemID != session.options.ItemID {
+		return http.StatusForbidden, errors.New("itemId is not the item this connect session updates")
+	}
+
+	item, err := s.client.GetItem(ctx, itemID)
+	if err != nil {
+		return http.StatusBadGateway, fmt.Errorf("could not confirm the item with Pluggy: %w", err)
+	}
+	if session.options.ItemID == "" && item.ClientUserID != session.options.ClientUserID {
+		return http.StatusForbidden, errors.New("itemId was not created by this connect session")
+	}
+	return 0, nil
+}
+
+func (s *Server) handleError(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Message string `json:"message"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+
+	err := s.update(r.PathValue("session"), func(session *Session) {
+		if session.Status != SessionConnected {
+			session.Status = SessionFailed
+			session.Error = body.Message
+		}
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	logger.Warn("[connect] Pluggy Connect reported an error", "error", body.Message)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) update(id string, fn func(*Session)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[id]
+	if !ok {
+		return errors.New("unknown connect session")
+	}
+	fn(session)
+	return nil
+}
+
+func (s *Server) evictExpired() {
+	for id, session := range s.sessions {
+		if time.Since(session.CreatedAt) > sessionLifetime {
+			delete(s.sessions, id)
+		}
+	}
+}
+
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("connect: error generating session id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
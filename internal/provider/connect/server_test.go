@@ -0,0 +1,193 @@
+package connect
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pluggy"
+)
+
+// items answers GET /items/{id} with the given clientUserId per item.
+type items map[string]string
+
+func (it items) RoundTrip(req *http.Request) (*http.Response, error) {
+	id := strings.TrimPrefix(req.URL.Path, "/items/")
+	clientUserID, ok := it[id]
+	status, body := http.StatusOK, `{"id":"`+id+`","clientUserId":"`+clientUserID+`"}`
+	if !ok {
+		status, body = http.StatusNotFound, `{"code":404,"message":"Item not found"}`
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func newTestServer(t *testing.T, pluggyItems items) (*Server, *pluggy.Client) {
+	t.Helper()
+	cache := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+
+	// Replay mode needs no API key; the transport is then swapped for the
+	// fake Pluggy.
+	client := pluggy.NewClient(pluggy.NewAuth(cache, nil), pluggy.Options{CassetteMode: "replay"})
+	client.Transport = pluggyItems
+
+	s := NewServer(client, "127.0.0.1:0", "")
+	s.baseURL = "http://connect.test"
+	return s, client
+}
+
+func post(s *Server, path, body string) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /connect/{session}/success", s.handleSuccess)
+	mux.HandleFunc("POST /connect/{session}/error", s.handleError)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+	return rec
+}
+
+func TestHandleSuccess(t *testing.T) {
+	tests := []struct {
+		name       string
+		updating   string // item the session updates, empty for a new connection
+		posted     string
+		owner      string // clientUserId Pluggy reports for the posted item; "session" for the session's own
+		wantStatus int
+	}{
+		{name: "new item from this session", posted: "item-new", owner: "session", wantStatus: http.StatusNoContent},
+		{name: "new item from someone else", posted: "item-other", owner: "someone-else", wantStatus: http.StatusForbidden},
+		{name: "new item without a client user", posted: "item-other", owner: "", wantStatus: http.StatusForbidden},
+		{name: "unknown item", posted: "item-missing", wantStatus: http.StatusBadGateway},
+		{name: "updated item", updating: "item-1", posted: "item-1", owner: "anyone", wantStatus: http.StatusNoContent},
+		{name: "another item than the updated one", updating: "item-1", posted: "item-2", owner: "anyone", wantStatus: http.StatusForbidden},
+		{name: "missing item id", posted: "", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pluggyItems := items{}
+			s, client := newTestServer(t, pluggyItems)
+
+			session, err := s.NewSession(pluggy.ConnectTokenOptions{ItemID: tt.updating})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.posted != "" && tt.name != "unknown item" {
+				owner := tt.owner
+				if owner == "session" {
+					owner = session.options.ClientUserID
+				}
+				pluggyItems[tt.posted] = owner
+			}
+
+			rec := post(s, "/connect/"+session.ID+"/success", `{"itemId":"`+tt.posted+`"}`)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d (%s), want %d", rec.Code, strings.TrimSpace(rec.Body.String()), tt.wantStatus)
+			}
+
+			got, _ := s.Session(session.ID)
+			known, err := client.KnownItems(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantStatus == http.StatusNoContent {
+				if got.Status != SessionConnected || got.ItemID != tt.posted {
+					t.Errorf("session = %s %q, want CONNECTED %q", got.Status, got.ItemID, tt.posted)
+				}
+				if len(known) != 1 || known[0].ID != tt.posted {
+					t.Errorf("known items = %v, want %q registered", known, tt.posted)
+				}
+				return
+			}
+			if got.Status != SessionPending {
+				t.Errorf("session status = %s, want it still PENDING", got.Status)
+			}
+			if len(known) != 0 {
+				t.Errorf("known items = %v, want none registered", known)
+			}
+		})
+	}
+}
+
+func TestHandleSuccessUnknownSession(t *testing.T) {
+	s, _ := newTestServer(t, items{"item-1": ""})
+	if rec := post(s, "/connect/nope/success", `{"itemId":"item-1"}`); rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+}
+
+func TestNewSessionClientUserID(t *testing.T) {
+	s, _ := newTestServer(t, items{})
+
+	fresh, _ := s.NewSession(pluggy.ConnectTokenOptions{})
+	other, _ := s.NewSession(pluggy.ConnectTokenOptions{})
+	if fresh.options.ClientUserID == "" || fresh.options.ClientUserID == other.options.ClientUserID {
+		t.Errorf("new connections should get their own clientUserId, got %q and %q", fresh.options.ClientUserID, other.options.ClientUserID)
+	}
+
+	update, _ := s.NewSession(pluggy.ConnectTokenOptions{ItemID: "item-1"})
+	if update.options.ClientUserID != "" {
+		t.Errorf("updates should keep the connect token cacheable, got clientUserId %q", update.options.ClientUserID)
+	}
+
+	own, _ := s.NewSession(pluggy.ConnectTokenOptions{ClientUserID: "user-1"})
+	if own.options.ClientUserID != "user-1" {
+		t.Errorf("clientUserId = %q, want the caller's", own.options.ClientUserID)
+	}
+}
+
+func TestLinks(t *testing.T) {
+	tests := []struct {
+		name      string
+		addr      string
+		publicURL string
+		wantBase  string
+	}{
+		{"loopback", "127.0.0.1:0", "", "http://127.0.0.1:"},
+		{"wildcard", "0.0.0.0:0", "", "http://127.0.0.1:"},
+		{"public url", "0.0.0.0:0", "https://finance.example.com/", "https://finance.example.com/connect/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer(nil, tt.addr, tt.publicURL)
+			if err := s.Start(); err != nil {
+				t.Fatal(err)
+			}
+			session, err := s.NewSession(pluggy.ConnectTokenOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(session.URL, tt.wantBase) {
+				t.Errorf("URL = %q, want it to start with %q", session.URL, tt.wantBase)
+			}
+		})
+	}
+}
+
+func TestLinkHost(t *testing.T) {
+	tests := []struct {
+		ip   net.IP
+		want string
+	}{
+		{nil, "127.0.0.1:8090"},
+		{net.IPv4zero, "127.0.0.1:8090"},
+		{net.IPv6unspecified, "127.0.0.1:8090"},
+		{net.ParseIP("192.168.0.10"), "192.168.0.10:8090"},
+		{net.IPv6loopback, "[::1]:8090"},
+	}
+	for _, tt := range tests {
+		if got := linkHost(&net.TCPAddr{IP: tt.ip, Port: 8090}); got != tt.want {
+			t.Errorf("linkHost(%v) = %q, want %q", tt.ip, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Envelope values look like "enc:v1:<key id>:<wrapped data key>:<ciphertext>".
+// Every value gets its own random data key, which is in turn sealed with the
+// keyring key, so rotating the keyring only rewraps small data keys.
+const prefix = "enc:v1:"
+
+var ErrUnknownKey = errors.New("encryption: value was sealed with a key that is not in the keyring")
+
+// IsEncrypted reports whether value is an envelope rather than plaintext.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// Encrypt seals plaintext with the primary key. A nil keyring stores values
+// as-is, which keeps encryption optional.
+func (kr *Keyring) Encrypt(plaintext string) (string, error) {
+	if kr == nil {
+		return plaintext, nil
+	}
+
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", fmt.Errorf("encryption: error generating data key: %w", err)
+	}
+
+	primary := kr.keys[0]
+	wrapped, err := seal(primary.material, dataKey)
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := seal(dataKey, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+
+	return prefix + primary.id + ":" +
+		base64.RawStdEncoding.EncodeToString(wrapped) + ":" +
+		base64.RawStdEncoding.EncodeToString(ciphertext), nil
+}
+
+// Decrypt opens an envelope. Plaintext values written before encryption was
+// enabled are returned unchanged so they keep working until migrated.
+func (kr *Keyring) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	if kr == nil {
+		return "", errors.New("encryption: value is encrypted but no encryption key is configured")
+	}
+
+	parts := strings.Split(strings.TrimPrefix(value, prefix), ":")
+	if len(parts) != 3 {
+		return "", errors.New("encryption: malformed envelope")
+	}
+
+	k, ok := kr.lookup(parts[0])
+	if !ok {
+		return "", fmt.Errorf("%w (key id %q)", ErrUnknownKey, parts[0])
+	}
+
+	wrapped, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("encryption: malformed data key: %w", err)
+	}
+	ciphertext, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", fmt.Errorf("encryption: malformed ciphertext: %w", err)
+	}
+
+	dataKey, err := open(k.material, wrapped)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(dataKey, ciphertext)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// NeedsMigration reports whether value is plaintext or sealed with a key other
+// than the primary one.
+func (kr *Keyring) NeedsMigration(value string) bool {
+	if kr == nil {
+		return false
+	}
+	return !strings.HasPrefix(value, prefix+kr.PrimaryID()+":")
+}
+
+func seal(k, plaintext []byte) ([]byte, error) {
+	aead, err := newAEAD(k)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("encryption: error generating nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(k, sealed []byte) ([]byte, error) {
+	aead, err := newAEAD(k)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("encryption: sealed value too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("encryption: error decrypting value: %w", err)
+	}
+	return plaintext, nil
+}
+
+func newAEAD(k []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(k)
+	if err != nil {
+		return nil, fmt.Errorf("encryption: error creating cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
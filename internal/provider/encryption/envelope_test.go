@@ -0,0 +1,173 @@
+package encryption
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func newKey(t *testing.T) string {
+	t.Helper()
+	material := make([]byte, keySize)
+	if _, err := rand.Read(material); err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(material)
+}
+
+func mustLoad(t *testing.T, value string) *Keyring {
+	t.Helper()
+	kr, err := Load("", value)
+	if err != nil {
+		t.Fatalf("Load(%q): %v", value, err)
+	}
+	return kr
+}
+
+func TestRoundTrip(t *testing.T) {
+	kr := mustLoad(t, "k1:"+newKey(t))
+
+	tests := []struct {
+		name      string
+		plaintext string
+	}{
+		{"empty", ""},
+		{"api key", "eyJhbGciOiJIUzI1NiJ9.payload.signature"},
+		{"json", `[{"id":"item-1","registeredAt":"2026-10-18T14:02:11Z"}]`},
+		{"unicode", "conta poupança ☕"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sealed, err := kr.Encrypt(tt.plaintext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !IsEncrypted(sealed) || !strings.HasPrefix(sealed, "enc:v1:k1:") {
+				t.Errorf("Encrypt() = %q, want an envelope sealed with k1", sealed)
+			}
+			if tt.plaintext != "" && strings.Contains(sealed, tt.plaintext) {
+				t.Errorf("envelope contains the plaintext")
+			}
+
+			got, err := kr.Decrypt(sealed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.plaintext {
+				t.Errorf("Decrypt(Encrypt(%q)) = %q", tt.plaintext, got)
+			}
+		})
+	}
+}
+
+func TestEncryptUsesFreshDataKeys(t *testing.T) {
+	kr := mustLoad(t, newKey(t))
+	a, _ := kr.Encrypt("same")
+	b, _ := kr.Encrypt("same")
+	if a == b {
+		t.Errorf("two envelopes of the same value are identical")
+	}
+}
+
+func TestNilKeyring(t *testing.T) {
+	var kr *Keyring
+
+	sealed, err := kr.Encrypt("plain")
+	if err != nil || sealed != "plain" {
+		t.Errorf("nil Encrypt() = %q, %v; want the plaintext", sealed, err)
+	}
+	if kr.NeedsMigration("plain") {
+		t.Errorf("nil keyring should never migrate")
+	}
+
+	other := mustLoad(t, newKey(t))
+	envelope, _ := other.Encrypt("secret")
+	if _, err := kr.Decrypt(envelope); err == nil {
+		t.Errorf("nil keyring decrypted an envelope")
+	}
+}
+
+func TestDecryptPlaintext(t *testing.T) {
+	kr := mustLoad(t, newKey(t))
+	got, err := kr.Decrypt("written before encryption")
+	if err != nil || got != "written before encryption" {
+		t.Errorf("Decrypt(plaintext) = %q, %v; want it unchanged", got, err)
+	}
+}
+
+func TestTamper(t *testing.T) {
+	kr := mustLoad(t, "k1:"+newKey(t))
+	sealed, err := kr.Encrypt("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(strings.TrimPrefix(sealed, prefix), ":")
+
+	flip := func(part string) string {
+		data, err := base64.RawStdEncoding.DecodeString(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data[len(data)-1] ^= 0x01
+		return base64.RawStdEncoding.EncodeToString(data)
+	}
+
+	tests := []struct {
+		name     string
+		envelope string
+	}{
+		{"flipped ciphertext", prefix + parts[0] + ":" + parts[1] + ":" + flip(parts[2])},
+		{"flipped data key", prefix + parts[0] + ":" + flip(parts[1]) + ":" + parts[2]},
+		{"truncated ciphertext", prefix + parts[0] + ":" + parts[1] + ":" + parts[2][:8]},
+		{"swapped parts", prefix + parts[0] + ":" + parts[2] + ":" + parts[1]},
+		{"missing part", prefix + parts[0] + ":" + parts[1]},
+		{"bad base64", prefix + parts[0] + ":" + parts[1] + ":!!!"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := kr.Decrypt(tt.envelope); err == nil {
+				t.Errorf("Decrypt() = %q, want an error", got)
+			}
+		})
+	}
+}
+
+func TestRotation(t *testing.T) {
+	oldKey, newKeyValue := "old:"+newKey(t), "new:"+newKey(t)
+
+	before := mustLoad(t, oldKey)
+	sealed, err := before.Encrypt("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated := mustLoad(t, newKeyValue+","+oldKey)
+	if rotated.PrimaryID() != "new" {
+		t.Fatalf("PrimaryID() = %q, want the first key", rotated.PrimaryID())
+	}
+	if !rotated.NeedsMigration(sealed) || !rotated.NeedsMigration("plaintext") {
+		t.Errorf("values sealed with the old key or not sealed should need migration")
+	}
+	got, err := rotated.Decrypt(sealed)
+	if err != nil || got != "secret" {
+		t.Fatalf("rotated Decrypt() = %q, %v; want the old value", got, err)
+	}
+
+	resealed, err := rotated.Encrypt(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotated.NeedsMigration(resealed) {
+		t.Errorf("a value sealed with the primary key should not need migration")
+	}
+
+	dropped := mustLoad(t, newKeyValue)
+	if _, err := dropped.Decrypt(sealed); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Decrypt() with the old key removed = %v, want ErrUnknownKey", err)
+	}
+	if got, err := dropped.Decrypt(resealed); err != nil || got != "secret" {
+		t.Errorf("Decrypt() of the migrated value = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,107 @@
+package encryption
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const keySize = 32
+
+type key struct {
+	id       string
+	material []byte
+}
+
+// Keyring holds the key-encryption keys. The first key is the primary one and
+// encrypts every new value; the others are kept only to decrypt values written
+// before a rotation.
+type Keyring struct {
+	keys []key
+}
+
+// Load parses keys from a file (one per line) and from a comma separated
+// value, file keys first. It returns nil when neither has any. Each entry is either "<id>:<base64 key>" or a bare
+// base64 key, whose id is then derived from the key itself.
+func Load(path, value string) (*Keyring, error) {
+	var entries []string
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("encryption: error reading key file: %w", err)
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				entries = append(entries, line)
+			}
+		}
+	}
+
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	kr := &Keyring{}
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		k, err := parseKey(entry)
+		if err != nil {
+			return nil, err
+		}
+		if seen[k.id] {
+			return nil, fmt.Errorf("encryption: duplicate key id %q", k.id)
+		}
+		seen[k.id] = true
+		kr.keys = append(kr.keys, k)
+	}
+	return kr, nil
+}
+
+func parseKey(entry string) (key, error) {
+	id, encoded, found := strings.Cut(entry, ":")
+	if !found {
+		id, encoded = "", entry
+	}
+
+	material, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return key{}, fmt.Errorf("encryption: key is not valid base64: %w", err)
+	}
+	if len(material) != keySize {
+		return key{}, fmt.Errorf("encryption: key must be %d bytes, got %d", keySize, len(material))
+	}
+
+	if id == "" {
+		sum := sha256.Sum256(material)
+		id = hex.EncodeToString(sum[:4])
+	}
+	if strings.ContainsAny(id, ":. ") {
+		return key{}, fmt.Errorf("encryption: invalid key id %q", id)
+	}
+
+	return key{id: id, material: material}, nil
+}
+
+// PrimaryID is the id of the key used for new values.
+func (kr *Keyring) PrimaryID() string {
+	return kr.keys[0].id
+}
+
+func (kr *Keyring) lookup(id string) (key, bool) {
+	for _, k := range kr.keys {
+		if k.id == id {
+			return k, true
+		}
+	}
+	return key{}, false
+}
@@ -0,0 +1,74 @@
+package encryption
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	k1, k2 := newKey(t), newKey(t)
+
+	tests := []struct {
+		name    string
+		file    string
+		value   string
+		wantIDs []string
+		wantErr bool
+	}{
+		{name: "nothing configured"},
+		{name: "value with id", value: "k1:" + k1, wantIDs: []string{"k1"}},
+		{name: "value list", value: "k1:" + k1 + ", k2:" + k2, wantIDs: []string{"k1", "k2"}},
+		{name: "file before value", file: "# keys\nk2:" + k2 + "\n\n", value: "k1:" + k1, wantIDs: []string{"k2", "k1"}},
+		{name: "bare key gets a derived id", value: k1, wantIDs: []string{""}},
+		{name: "duplicate id", value: "k1:" + k1 + ",k1:" + k2, wantErr: true},
+		{name: "not base64", value: "k1:not-base64!", wantErr: true},
+		{name: "short key", value: "k1:c2hvcnQ=", wantErr: true},
+		{name: "invalid id", value: "k.1:" + k1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := ""
+			if tt.file != "" {
+				path = filepath.Join(t.TempDir(), "keys")
+				if err := os.WriteFile(path, []byte(tt.file), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			kr, err := Load(path, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if tt.wantIDs == nil {
+				if kr != nil {
+					t.Errorf("Load() = %v, want nil", kr)
+				}
+				return
+			}
+			if len(kr.keys) != len(tt.wantIDs) {
+				t.Fatalf("Load() has %d keys, want %d", len(kr.keys), len(tt.wantIDs))
+			}
+			for i, id := range tt.wantIDs {
+				if id == "" {
+					if len(kr.keys[i].id) != 8 {
+						t.Errorf("derived id = %q, want 8 hex characters", kr.keys[i].id)
+					}
+					continue
+				}
+				if kr.keys[i].id != id {
+					t.Errorf("key %d id = %q, want %q", i, kr.keys[i].id, id)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	if _, err := Load(filepath.Join(t.TempDir(), "missing"), ""); err == nil {
+		t.Errorf("Load() with a missing key file should fail")
+	}
+}
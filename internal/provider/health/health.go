@@ -0,0 +1,323 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/cassette"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pluggy"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/redis"
+)
+
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+// worse orders statuses from pass to fail.
+func worse(a, b Status) Status {
+	rank := map[Status]int{StatusPass: 0, StatusWarn: 1, StatusFail: 2}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
+
+// Check is the outcome of one check, with a hint on how to fix it when it
+// did not pass.
+type Check struct {
+	Name    string `json:"name"`
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+	Hint    string `json:"hint,omitempty"`
+}
+
+type Report struct {
+	Status Status  `json:"status"` // the worst status of the checks
+	Checks []Check `json:"checks"`
+}
+
+func NewReport(checks ...Check) Report {
+	report := Report{Status: StatusPass, Checks: checks}
+	for _, check := range checks {
+		report.Status = worse(report.Status, check.Status)
+	}
+	return report
+}
+
+const (
+	slowRedis        = 100 * time.Millisecond
+	lowRateLimitPart = 10 // warn below this percent of the hourly requests
+
+	// Every item checked costs a Pluggy request, so a run checks the most
+	// recently registered ones, a few at a time, and reuses recent answers.
+	maxItemChecks    = 20
+	itemChecksAtOnce = 4
+	itemStatusTTL    = time.Minute
+)
+
+// Checker checks the dependencies of the server: Pluggy credentials and API
+// key, Redis, the rate limit and the items registered with the server.
+type Checker struct {
+	opts   pluggy.Options
+	cache  *redis.Client
+	client *pluggy.Client // nil when it could not be created, as on a bad encryption key
+
+	mu    sync.Mutex
+	items map[string]fetchedItem // item ID -> its last answer from Pluggy
+}
+
+type fetchedItem struct {
+	item *pluggy.Item
+	at   time.Time
+}
+
+func NewChecker(opts pluggy.Options, cache *redis.Client, client *pluggy.Client) *Checker {
+	return &Checker{opts: opts, cache: cache, client: client, items: map[string]fetchedItem{}}
+}
+
+func (c *Checker) replay() bool {
+	return cassette.Mode(c.opts.CassetteMode) == cassette.ModeReplay
+}
+
+// Run runs every check.
+func (c *Checker) Run(ctx context.Context) Report {
+	checks := []Check{c.Credentials(ctx), c.Redis(ctx), c.ApiKey(ctx), c.RateLimit()}
+	return NewReport(append(checks, c.Items(ctx)...)...)
+}
+
+func (c *Checker) Credentials(ctx context.Context) Check {
+	check := Check{Name: "pluggy_credentials"}
+	switch {
+	case c.replay():
+		check.Status, check.Message = StatusPass, "Replaying cassettes, no credentials needed"
+	case c.opts.ClientID == "" || c.opts.ClientSecret == "":
+		check.Status, check.Message = StatusFail, "Pluggy client ID or secret is missing"
+		check.Hint = "Set PLUGGY_CLIENT_ID and PLUGGY_CLIENT_SECRET, or pluggy.client_id and pluggy.client_secret in the configuration file"
+	case c.client == nil:
+		check.Status, check.Message = StatusWarn, "Not checked, the Pluggy client could not be created"
+	default:
+		// An authorized client already proved the credentials; otherwise this
+		// is one more authorization attempt, through the client's own limits.
+		err := c.client.Ready()
+		if err != nil {
+			err = c.client.Authorize(ctx)
+		}
+		check.Status, check.Message, check.Hint = fromPluggy(err, "Pluggy accepted the client ID and secret",
+			"Copy the client ID and secret again from the Pluggy dashboard (https://dashboard.pluggy.ai)")
+	}
+	return check
+}
+
+func (c *Checker) Redis(ctx context.Context) Check {
+	check := Check{Name: "redis"}
+
+	start := time.Now()
+	err := c.cache.Ping(ctx).Err()
+	latency := time.Since(start).Round(time.Millisecond)
+
+	switch {
+	case err != nil && c.replay():
+		check.Status, check.Message = StatusWarn, fmt.Sprintf("Redis is unreachable: %v", err)
+		check.Hint = "Known items cannot be listed; start Redis or fix REDIS_HOST and REDIS_PORT"
+	case err != nil:
+		check.Status, check.Message = StatusFail, fmt.Sprintf("Redis is unreachable: %v", err)
+		check.Hint = "Start Redis or fix REDIS_HOST, REDIS_PORT, REDIS_PASSWORD and REDIS_DB (redis.* in the configuration file)"
+	case latency > slowRedis:
+		check.Status, check.Message = StatusWarn, fmt.Sprintf("Redis answered in %s", latency)
+		check.Hint = "Run Redis close to the server, known items and connect tokens are read from it during tool calls"
+	default:
+		check.Status, check.Message = StatusPass, fmt.Sprintf("Redis answered in %s", latency)
+	}
+	return check
+}
+
+func (c *Checker) ApiKey(ctx context.Context) Check {
+	check := Check{Name: "pluggy_api_key"}
+	switch {
+	case c.replay():
+		check.Status, check.Message = StatusPass, "Replaying cassettes, no API key needed"
+	case c.client == nil:
+		check.Status, check.Message = StatusFail, "Not checked, the Pluggy client could not be created"
+		check.Hint = "Fix the encryption key, see the configuration check"
+	case c.client.Ready() != nil:
+		check.Status, check.Message = StatusFail, c.client.Ready().Error()
+		check.Hint = "Fix the Pluggy credentials and Redis checks first; the server keeps retrying to authorize in the background"
+	default:
+		err := c.client.CheckApiKey(ctx)
+		check.Status, check.Message, check.Hint = fromPluggy(err, "Pluggy accepted the API key",
+			"Pluggy rejected the API key even after renewing it: check that the client ID and secret belong to this Pluggy application")
+		if renewErr := c.client.RenewalError(); err == nil && renewErr != nil {
+			check.Status, check.Message = StatusWarn, fmt.Sprintf("Pluggy accepted the API key, but renewing it failed: %v", renewErr)
+			check.Hint = "The key serves until it expires while the server keeps retrying to renew it; check the Pluggy status and the credentials"
+		}
+	}
+	return check
+}
+
+func (c *Checker) RateLimit() Check {
+	check := Check{Name: "rate_limit"}
+	if c.client == nil {
+		check.Status, check.Message = StatusWarn, "Not checked, the Pluggy client could not be created"
+		return check
+	}
+
+	limit := c.client.RateLimit()
+	check.Message = fmt.Sprintf("%d of %d transaction and investment requests left this hour", limit.Remaining, limit.Limit)
+	check.Status = StatusPass
+	if limit.Remaining*100 < limit.Limit*lowRateLimitPart {
+		check.Status = StatusWarn
+		check.Hint = fmt.Sprintf("Transaction and investment requests will wait for the limit to reset in %s; fetch fewer pages or larger ones", limit.ResetIn.Round(time.Second))
+	}
+	return check
+}
+
+var itemStatusChecks = map[pluggy.ItemStatus]struct {
+	status Status
+	hint   string
+}{
+	pluggy.ItemStatusUpdated:          {StatusPass, ""},
+	pluggy.ItemStatusUpdating:         {StatusPass, ""},
+	pluggy.ItemStatusOutdated:         {StatusWarn, "Data is stale: refresh the item (items refresh) or wait for its next automatic sync"},
+	pluggy.ItemStatusWaitingUserInput: {StatusWarn, "The institution asked for an MFA code: finish the connection in Pluggy Connect"},
+	pluggy.ItemStatusError:            {StatusFail, "The institution rejected the credentials: reconnect the bank through Pluggy Connect"},
+}
+
+var connectorHealthChecks = map[string]struct {
+	status Status
+	hint   string
+}{
+	"ONLINE":   {StatusPass, ""},
+	"UNSTABLE": {StatusWarn, "Pluggy reports the institution as unstable, syncs may fail or be slow until it recovers"},
+	"OFFLINE":  {StatusFail, "Pluggy reports the institution as offline, data cannot be refreshed until it recovers"},
+}
+
+// Items checks the status of every known item and the health of their
+// connectors. Items are named by their institution, never by ID.
+func (c *Checker) Items(ctx context.Context) []Check {
+	items := Check{Name: "item_status"}
+	connectors := Check{Name: "connector_health"}
+
+	if c.client == nil {
+		items.Status, items.Message = StatusWarn, "Not checked, the Pluggy client could not be created"
+		return []Check{items}
+	}
+	if c.client.Ready() != nil {
+		items.Status, items.Message = StatusWarn, "Not checked, the server is not authorized with Pluggy yet"
+		items.Hint = "See the pluggy_api_key check"
+		return []Check{items}
+	}
+
+	known, err := c.client.KnownItems(ctx)
+	if err != nil {
+		items.Status, items.Message = StatusFail, fmt.Sprintf("Error reading the known items: %v", err)
+		items.Hint = "Fix the Redis check first"
+		return []Check{items}
+	}
+	if len(known) == 0 {
+		items.Status, items.Message = StatusWarn, "No items are registered with this server"
+		items.Hint = "Connect a bank with pluggy_connect_url or the connect_new_bank prompt"
+		return []Check{items}
+	}
+
+	skipped := max(len(known)-maxItemChecks, 0)
+	fetched, errs := c.fetchItems(ctx, known[skipped:])
+
+	items.Status, connectors.Status = StatusPass, StatusPass
+	var itemLines, connectorLines, itemHints, connectorHints []string
+	for i, item := range fetched {
+		if errs[i] != nil {
+			items.Status = StatusFail
+			itemLines = append(itemLines, fmt.Sprintf("unreadable item: %v", errs[i]))
+			continue
+		}
+
+		name := item.Connector.Name
+		state := itemStatusChecks[pluggy.ItemStatus(item.Status)]
+		if state.status == "" {
+			state.status = StatusWarn
+		}
+		items.Status = worse(items.Status, state.status)
+		itemLines = append(itemLines, fmt.Sprintf("%s: %s", name, item.Status))
+		if state.hint != "" && !slices.Contains(itemHints, state.hint) {
+			itemHints = append(itemHints, state.hint)
+		}
+
+		connector := connectorHealthChecks[item.Connector.Health.Status]
+		if connector.status == "" {
+			connector.status = StatusWarn
+		}
+		connectors.Status = worse(connectors.Status, connector.status)
+		connectorLines = append(connectorLines, fmt.Sprintf("%s: %s", name, item.Connector.Health.Status))
+		if connector.hint != "" && !slices.Contains(connectorHints, connector.hint) {
+			connectorHints = append(connectorHints, connector.hint)
+		}
+	}
+
+	if skipped > 0 {
+		itemLines = append(itemLines, fmt.Sprintf("%d older items not checked", skipped))
+	}
+	items.Message, items.Hint = strings.Join(itemLines, ", "), strings.Join(itemHints, ". ")
+	connectors.Message, connectors.Hint = strings.Join(connectorLines, ", "), strings.Join(connectorHints, ". ")
+	if len(connectorLines) == 0 {
+		return []Check{items}
+	}
+	return []Check{items, connectors}
+}
+
+// fetchItems gets the known items from Pluggy, itemChecksAtOnce at a time,
+// reusing the ones fetched within itemStatusTTL.
+func (c *Checker) fetchItems(ctx context.Context, known []pluggy.KnownItem) ([]*pluggy.Item, []error) {
+	items, errs := make([]*pluggy.Item, len(known)), make([]error, len(known))
+	slots := make(chan struct{}, itemChecksAtOnce)
+
+	var wg sync.WaitGroup
+	for i, k := range known {
+		c.mu.Lock()
+		cached, ok := c.items[k.ID]
+		c.mu.Unlock()
+		if ok && time.Since(cached.at) < itemStatusTTL {
+			items[i] = cached.item
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			items[i], errs[i] = c.client.GetItem(ctx, k.ID)
+			if errs[i] == nil {
+				c.mu.Lock()
+				c.items[k.ID] = fetchedItem{items[i], time.Now()}
+				c.mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return items, errs
+}
+
+// fromPluggy turns the error of a Pluggy request into a check result, hinting
+// rejected for the 401 and 403 answers.
+func fromPluggy(err error, passed, rejected string) (Status, string, string) {
+	if err == nil {
+		return StatusPass, passed, ""
+	}
+
+	var apiErr *pluggy.APIError
+	if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden) {
+		return StatusFail, err.Error(), rejected
+	}
+	return StatusFail, err.Error(), "Check that this machine can reach https://api.pluggy.ai"
+}
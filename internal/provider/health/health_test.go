@@ -0,0 +1,150 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pluggy"
+)
+
+// fakeItems answers GET /items/{id} with the item's status and connector
+// health, and counts the requests.
+type fakeItems struct {
+	items map[string][2]string // item ID -> status, connector health
+
+	requests  atomic.Int32
+	mu        sync.Mutex
+	inFlight  int
+	maxAtOnce int
+}
+
+func (f *fakeItems) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.requests.Add(1)
+	f.mu.Lock()
+	f.inFlight++
+	f.maxAtOnce = max(f.maxAtOnce, f.inFlight)
+	f.mu.Unlock()
+	defer func() {
+		f.mu.Lock()
+		f.inFlight--
+		f.mu.Unlock()
+	}()
+
+	id := strings.TrimPrefix(req.URL.Path, "/items/")
+	state, ok := f.items[id]
+	status, body := http.StatusOK, fmt.Sprintf(`{"id":%q,"status":%q,"connector":{"name":"Bank %s","health":{"status":%q}}}`, id, state[0], id, state[1])
+	if !ok {
+		status, body = http.StatusNotFound, `{"code":404,"message":"Item not found"}`
+	}
+	return &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+}
+
+func newTestChecker(t *testing.T, fake *fakeItems, known ...string) *Checker {
+	t.Helper()
+	cache := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	opts := pluggy.Options{CassetteMode: "replay"}
+	client := pluggy.NewClient(pluggy.NewAuth(cache, nil), opts)
+	client.Transport = fake
+	for _, id := range known {
+		if err := client.RegisterItem(context.Background(), id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewChecker(opts, cache, client)
+}
+
+func TestItems(t *testing.T) {
+	tests := []struct {
+		name           string
+		items          map[string][2]string
+		known          []string
+		wantItems      Status
+		wantConnectors Status // empty when the check is left out
+		wantMessage    string
+	}{
+		{
+			name:        "none registered",
+			wantItems:   StatusWarn,
+			wantMessage: "No items are registered with this server",
+		},
+		{
+			name:           "healthy",
+			items:          map[string][2]string{"a": {"UPDATED", "ONLINE"}, "b": {"UPDATING", "ONLINE"}},
+			known:          []string{"a", "b"},
+			wantItems:      StatusPass,
+			wantConnectors: StatusPass,
+			wantMessage:    "Bank a: UPDATED, Bank b: UPDATING",
+		},
+		{
+			name:           "worst status wins",
+			items:          map[string][2]string{"a": {"UPDATED", "UNSTABLE"}, "b": {"LOGIN_ERROR", "ONLINE"}},
+			known:          []string{"a", "b"},
+			wantItems:      StatusFail,
+			wantConnectors: StatusWarn,
+			wantMessage:    "Bank a: UPDATED, Bank b: LOGIN_ERROR",
+		},
+		{
+			name:           "unreadable item",
+			items:          map[string][2]string{"a": {"OUTDATED", "ONLINE"}},
+			known:          []string{"a", "gone"},
+			wantItems:      StatusFail,
+			wantConnectors: StatusPass,
+			wantMessage:    "Bank a: OUTDATED, unreadable item",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := newTestChecker(t, &fakeItems{items: tt.items}, tt.known...)
+			checks := checker.Items(context.Background())
+
+			if checks[0].Status != tt.wantItems || !strings.HasPrefix(checks[0].Message, tt.wantMessage) {
+				t.Errorf("item_status = %s %q, want %s %q", checks[0].Status, checks[0].Message, tt.wantItems, tt.wantMessage)
+			}
+			if tt.wantConnectors == "" {
+				if len(checks) != 1 {
+					t.Errorf("checks = %+v, want only item_status", checks)
+				}
+				return
+			}
+			if len(checks) != 2 || checks[1].Status != tt.wantConnectors {
+				t.Errorf("checks = %+v, want connector_health %s", checks, tt.wantConnectors)
+			}
+		})
+	}
+}
+
+func TestItemsAreCappedAndReused(t *testing.T) {
+	fake := &fakeItems{items: map[string][2]string{}}
+	var known []string
+	for i := range maxItemChecks + 5 {
+		id := fmt.Sprintf("item-%02d", i)
+		fake.items[id] = [2]string{"UPDATED", "ONLINE"}
+		known = append(known, id)
+	}
+	checker := newTestChecker(t, fake, known...)
+
+	checks := checker.Items(context.Background())
+	if got := fake.requests.Load(); got != maxItemChecks {
+		t.Errorf("first run made %d requests, want %d", got, maxItemChecks)
+	}
+	if !strings.Contains(checks[0].Message, "5 older items not checked") || strings.Contains(checks[0].Message, "Bank item-00") {
+		t.Errorf("message = %q, want the latest items and the count of the others", checks[0].Message)
+	}
+	if fake.maxAtOnce > itemChecksAtOnce {
+		t.Errorf("%d items were fetched at once, want at most %d", fake.maxAtOnce, itemChecksAtOnce)
+	}
+
+	checker.Items(context.Background())
+	if got := fake.requests.Load(); got != maxItemChecks {
+		t.Errorf("second run made %d more requests, want the items reused", got-maxItemChecks)
+	}
+}
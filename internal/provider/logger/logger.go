@@ -1,107 +1,180 @@
 package logger
 
 import (
+	"context"
+	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
+// Options tell how much is logged, in which format and where to.
+type Options struct {
+	Level      string   // debug, info, warn or error, info by default
+	Format     string   // text or json, text by default
+	File       string   // a file, or stderr or stdout; empty is stderr
+	MaxSizeMB  int      // rotate the file once it reaches this size
+	MaxAgeDays int      // remove rotated files older than this, 0 keeps them
+	MaxBackups int      // keep at most this many rotated files, 0 keeps them all
+	Secrets    []string // scrubbed wherever they appear, on top of what looks secret
+}
+
 var (
-	instance *log.Logger
+	instance *slog.Logger
 	once     sync.Once
-	logFile  *os.File
+	output   io.Closer
 )
 
-func Init(logFilePath string) error {
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if s == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return level, fmt.Errorf("invalid log level %q, expected debug, info, warn or error", s)
+	}
+	return level, nil
+}
+
+func ParseFormat(s string) (string, error) {
+	switch s {
+	case "", "text":
+		return "text", nil
+	case "json":
+		return "json", nil
+	default:
+		return "", fmt.Errorf("invalid log format %q, expected text or json", s)
+	}
+}
+
+// Init sets up the logger once; later calls are ignored. Anything logged
+// before it goes to stderr as text.
+func Init(opts Options) error {
 	var err error
 	once.Do(func() {
-		initLogger(logFilePath, &err)
+		instance, err = newLogger(opts)
+		if err == nil {
+			// The standard log package goes through the same handler.
+			slog.SetDefault(instance)
+		}
 	})
 	return err
 }
 
-func initLogger(logFilePath string, err *error) {
-	var output io.Writer = os.Stdout
-
-	if logFilePath != "" {
-		logFile, *err = os.OpenFile(logFilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
-		if *err == nil {
-			output = logFile
+func newLogger(opts Options) (*slog.Logger, error) {
+	level, err := ParseLevel(opts.Level)
+	if err != nil {
+		return nil, err
+	}
+	format, err := ParseFormat(opts.Format)
+	if err != nil {
+		return nil, err
+	}
+	addSecrets(opts.Secrets...)
+
+	var w io.Writer
+	switch opts.File {
+	case "", "stderr":
+		// stdout carries the protocol of the stdio transport.
+		w = os.Stderr
+	case "stdout":
+		w = os.Stdout
+	default:
+		file := &lumberjack.Logger{
+			Filename:   opts.File,
+			MaxSize:    opts.MaxSizeMB,
+			MaxAge:     opts.MaxAgeDays,
+			MaxBackups: opts.MaxBackups,
+			LocalTime:  true,
 		}
+		w, output = file, file
 	}
 
-	instance = log.New(output, "", log.LstdFlags|log.Lshortfile)
+	handlerOpts := &slog.HandlerOptions{
+		AddSource:   true,
+		Level:       level,
+		ReplaceAttr: replaceAttr,
+	}
+	var handler slog.Handler = slog.NewTextHandler(w, handlerOpts)
+	if format == "json" {
+		handler = slog.NewJSONHandler(w, handlerOpts)
+	}
+	return slog.New(scrubHandler{handler}), nil
+}
 
-	log.SetOutput(output)
+// replaceAttr keeps the file name and line of the source, as the log
+// package's Lshortfile did, and writes durations as "1.5s" rather than
+// nanoseconds in JSON.
+func replaceAttr(groups []string, a slog.Attr) slog.Attr {
+	if source, ok := a.Value.Any().(*slog.Source); ok && a.Key == slog.SourceKey && len(groups) == 0 {
+		return slog.String(slog.SourceKey, fmt.Sprintf("%s:%d", filepath.Base(source.File), source.Line))
+	}
+	if a.Value.Kind() == slog.KindDuration {
+		return slog.String(a.Key, a.Value.Duration().String())
+	}
+	return a
 }
 
 func Close() error {
-	if logFile != nil {
-		return logFile.Close()
+	if output != nil {
+		return output.Close()
 	}
 	return nil
 }
 
-func Debug(v ...interface{}) {
-	ensureInitialized()
-	instance.Println(v...)
+// Debug, Info, Warn and Error log msg with key/value fields, as in
+// Info("tool call", "tool", name, "duration", d).
+func Debug(msg string, args ...any) { write(slog.LevelDebug, msg, args...) }
+func Info(msg string, args ...any)  { write(slog.LevelInfo, msg, args...) }
+func Warn(msg string, args ...any)  { write(slog.LevelWarn, msg, args...) }
+func Error(msg string, args ...any) { write(slog.LevelError, msg, args...) }
+
+func Debugf(format string, v ...any) { write(slog.LevelDebug, fmt.Sprintf(format, v...)) }
+func Infof(format string, v ...any)  { write(slog.LevelInfo, fmt.Sprintf(format, v...)) }
+func Warnf(format string, v ...any)  { write(slog.LevelWarn, fmt.Sprintf(format, v...)) }
+func Errorf(format string, v ...any) { write(slog.LevelError, fmt.Sprintf(format, v...)) }
+
+// Fatal logs an error and exits.
+func Fatal(msg string, args ...any) {
+	write(slog.LevelError, msg, args...)
+	os.Exit(1)
 }
 
-func Debugf(format string, v ...interface{}) {
-	ensureInitialized()
-	instance.Printf(format, v...)
+func Fatalf(format string, v ...any) {
+	write(slog.LevelError, fmt.Sprintf(format, v...))
+	os.Exit(1)
 }
 
-func Info(v ...interface{}) {
+func write(level slog.Level, msg string, args ...any) {
 	ensureInitialized()
-	instance.Println(v...)
-}
-
-func Infof(format string, v ...interface{}) {
-	ensureInitialized()
-	instance.Printf(format, v...)
-}
-
-func Warn(v ...interface{}) {
-	ensureInitialized()
-	instance.Println(v...)
-}
-
-func Warnf(format string, v ...interface{}) {
-	ensureInitialized()
-	instance.Printf(format, v...)
-}
-
-func Error(v ...interface{}) {
-	ensureInitialized()
-	instance.Println(v...)
-}
+	ctx := context.Background()
+	if !instance.Enabled(ctx, level) {
+		return
+	}
 
-func Errorf(format string, v ...interface{}) {
-	ensureInitialized()
-	instance.Printf(format, v...)
-}
+	// Skip runtime.Callers, write and the exported function, so the source
+	// is the caller of the logger.
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
 
-func Fatal(v ...interface{}) {
-	ensureInitialized()
-	instance.Fatal(v...)
-}
-
-func Fatalf(format string, v ...interface{}) {
-	ensureInitialized()
-	instance.Fatalf(format, v...)
+	record := slog.NewRecord(time.Now(), level, strings.TrimSuffix(msg, "\n"), pcs[0])
+	record.Add(args...)
+	_ = instance.Handler().Handle(ctx, record)
 }
 
 func ensureInitialized() {
 	if instance == nil {
-		var err error
-		once.Do(func() {
-			initLogger("", &err)
-		})
-
-		if instance == nil {
-			instance = log.New(os.Stdout, "", log.LstdFlags|log.Lshortfile)
-		}
+		_ = Init(Options{})
+	}
+	if instance == nil {
+		// Init failed on invalid options, which its caller reports.
+		instance, _ = newLogger(Options{})
 	}
 }
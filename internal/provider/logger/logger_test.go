@@ -0,0 +1,133 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    slog.Level
+		wantErr bool
+	}{
+		{"", slog.LevelInfo, false},
+		{"debug", slog.LevelDebug, false},
+		{"WARN", slog.LevelWarn, false},
+		{"error", slog.LevelError, false},
+		{"verbose", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.in)
+		if (err != nil) != tt.wantErr || (err == nil && got != tt.want) {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v, error %t", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		in, want string
+		wantErr  bool
+	}{
+		{"", "text", false},
+		{"text", "text", false},
+		{"json", "json", false},
+		{"xml", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseFormat(%q) = %q, %v; want %q, error %t", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestNewLogger(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   Options
+		want   []string // in the output
+		absent []string
+	}{
+		{
+			name:   "json at info",
+			opts:   Options{Level: "info", Format: "json"},
+			want:   []string{`"level":"INFO"`, `"msg":"tool call"`, `"tool":"get_accounts"`, `"duration":"1.5s"`, `"source":"logger_test.go:`},
+			absent: []string{"debug line"},
+		},
+		{
+			name: "text at debug",
+			opts: Options{Level: "debug"},
+			want: []string{"level=DEBUG", `msg="debug line"`, "level=INFO", "tool=get_accounts", "duration=1.5s"},
+		},
+		{
+			name:   "warn threshold",
+			opts:   Options{Level: "warn", Format: "json"},
+			absent: []string{"tool call", "debug line"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.File = filepath.Join(t.TempDir(), "server.log")
+			l, err := newLogger(tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			l.Debug("debug line")
+			l.Info("tool call", "tool", "get_accounts", "duration", 1500*time.Millisecond)
+			Close()
+
+			data, err := os.ReadFile(tt.opts.File)
+			if err != nil && !os.IsNotExist(err) {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(data), want) {
+					t.Errorf("log misses %q:\n%s", want, data)
+				}
+			}
+			for _, absent := range tt.absent {
+				if strings.Contains(string(data), absent) {
+					t.Errorf("log has %q:\n%s", absent, data)
+				}
+			}
+		})
+	}
+}
+
+func TestNewLoggerInvalidOptions(t *testing.T) {
+	for _, opts := range []Options{{Level: "loud"}, {Format: "xml"}} {
+		if _, err := newLogger(opts); err == nil {
+			t.Errorf("newLogger(%+v) should fail", opts)
+		}
+	}
+}
+
+func TestJSONLinesParse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.log")
+	l, err := newLogger(Options{File: path, Format: "json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Log(context.Background(), slog.LevelError, "failed", "error", "boom", "attempt", 2)
+	Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var line map[string]any
+	if err := json.Unmarshal(data, &line); err != nil {
+		t.Fatalf("%v: %s", err, data)
+	}
+	if line["level"] != "ERROR" || line["error"] != "boom" || line["attempt"] != float64(2) {
+		t.Errorf("line = %v", line)
+	}
+}
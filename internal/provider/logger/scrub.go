@@ -0,0 +1,202 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const scrubbed = "[REDACTED]"
+
+// secretKeys are the names of fields holding secrets or personal data.
+const secretKeys = `(?:x-api-key|api_?key|client_?secret|access_?token|connect_?token|auth_?token|password|authorization|tax_?number|cpf|cnpj|(?:account|branch|routing|transfer|card)_?number)`
+
+var secretKey = regexp.MustCompile(`(?i)^` + secretKeys + `$`)
+
+// scrubPatterns find secrets and personal data by their shape, wherever they
+// appear in a message, a field or an error.
+var scrubPatterns = []struct {
+	re    *regexp.Regexp
+	repl  string
+	valid func(match string) bool // when set, only the matches it accepts are scrubbed
+}{
+	// Pluggy API keys and connect tokens are JWTs.
+	{regexp.MustCompile(`eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`), scrubbed, nil},
+	// Values of secret or personal fields, in JSON bodies, headers and
+	// key=value text alike.
+	{regexp.MustCompile(`(?i)("?\b` + secretKeys + `"?\s*[:=]\s*)("[^"]*"|bearer\s+\S+|[^\s",}]+)`), `${1}"` + scrubbed + `"`, nil},
+	// CPF and CNPJ, formatted or as bare digits. Their check digits tell
+	// them from other numbers of the same length, such as timestamps.
+	{regexp.MustCompile(`\b\d{3}\.?\d{3}\.?\d{3}-?\d{2}\b`), scrubbed, validCPF},
+	{regexp.MustCompile(`\b\d{2}\.?\d{3}\.?\d{3}/?\d{4}-?\d{2}\b`), scrubbed, validCNPJ},
+}
+
+// validCPF reports whether the 11 digits of s end with the check digits of
+// the 9 before them.
+func validCPF(s string) bool {
+	d := digits(s)
+	if len(d) != 11 || repeated(d) {
+		return false
+	}
+	return checkDigit(d[:9], 10) == d[9] && checkDigit(d[:10], 11) == d[10]
+}
+
+// validCNPJ reports whether the 14 digits of s end with the check digits of
+// the 12 before them.
+func validCNPJ(s string) bool {
+	d := digits(s)
+	if len(d) != 14 || repeated(d) {
+		return false
+	}
+	return cnpjCheckDigit(d[:12]) == d[12] && cnpjCheckDigit(d[:13]) == d[13]
+}
+
+func checkDigit(d []int, weight int) int {
+	sum := 0
+	for i, n := range d {
+		sum += n * (weight - i)
+	}
+	if r := sum * 10 % 11; r < 10 {
+		return r
+	}
+	return 0
+}
+
+func cnpjCheckDigit(d []int) int {
+	sum, weight := 0, len(d)-7
+	for _, n := range d {
+		sum += n * weight
+		if weight--; weight < 2 {
+			weight = 9
+		}
+	}
+	if r := sum % 11; r >= 2 {
+		return 11 - r
+	}
+	return 0
+}
+
+func digits(s string) []int {
+	d := make([]int, 0, len(s))
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			d = append(d, int(r-'0'))
+		}
+	}
+	return d
+}
+
+// repeated reports whether every digit is the same, which passes the checks
+// but is no real document.
+func repeated(d []int) bool {
+	for _, n := range d[1:] {
+		if n != d[0] {
+			return false
+		}
+	}
+	return true
+}
+
+var (
+	secretsMu sync.RWMutex
+	secrets   []string
+)
+
+// addSecrets registers values, such as the configured client secret, that
+// are scrubbed wherever they appear in the log.
+func addSecrets(values ...string) {
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	for _, v := range values {
+		// Short values would scrub unrelated text.
+		if len(v) >= 6 {
+			secrets = append(secrets, v)
+		}
+	}
+}
+
+// Scrub removes secrets and personal data from s.
+func Scrub(s string) string {
+	secretsMu.RLock()
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, scrubbed)
+	}
+	secretsMu.RUnlock()
+
+	for _, p := range scrubPatterns {
+		if p.valid == nil {
+			s = p.re.ReplaceAllString(s, p.repl)
+			continue
+		}
+		s = p.re.ReplaceAllStringFunc(s, func(match string) string {
+			if p.valid(match) {
+				return p.repl
+			}
+			return match
+		})
+	}
+	return s
+}
+
+// scrubHandler scrubs the message and every field of a record before the
+// handler it wraps writes it.
+type scrubHandler struct {
+	next slog.Handler
+}
+
+func (h scrubHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h scrubHandler) Handle(ctx context.Context, r slog.Record) error {
+	out := slog.NewRecord(r.Time, r.Level, Scrub(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(scrubAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, out)
+}
+
+func (h scrubHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	scrubbedAttrs := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		scrubbedAttrs[i] = scrubAttr(a)
+	}
+	return scrubHandler{h.next.WithAttrs(scrubbedAttrs)}
+}
+
+func (h scrubHandler) WithGroup(name string) slog.Handler {
+	return scrubHandler{h.next.WithGroup(name)}
+}
+
+func scrubAttr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	if secretKey.MatchString(a.Key) && v.Kind() != slog.KindGroup {
+		return slog.String(a.Key, scrubbed)
+	}
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, Scrub(v.String()))
+	case slog.KindGroup:
+		group := v.Group()
+		attrs := make([]any, len(group))
+		for i, child := range group {
+			attrs[i] = scrubAttr(child)
+		}
+		return slog.Group(a.Key, attrs...)
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return slog.String(a.Key, Scrub(err.Error()))
+		}
+		// Other values keep their type unless their text holds something
+		// to scrub.
+		text := fmt.Sprint(v.Any())
+		if clean := Scrub(text); clean != text {
+			return slog.String(a.Key, clean)
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
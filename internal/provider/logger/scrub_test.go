@@ -0,0 +1,142 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestScrub(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"plain text", "fetched 3 accounts", "fetched 3 accounts"},
+		{"jwt", "token eyJhbGciOiJIUzI1.eyJzdWIiOiIx.c2lnbmF0dXJl sent", "token [REDACTED] sent"},
+		{"json field", `{"apiKey":"abc","id":"1"}`, `{"apiKey":"[REDACTED]","id":"1"}`},
+		{"header", "Authorization: Bearer abc.def", `Authorization: "[REDACTED]"`},
+		{"key=value", "accountNumber=12345-6 branch=1", `accountNumber="[REDACTED]" branch=1`},
+		{"number field kept", `{"number":"0001","total":3}`, `{"number":"0001","total":3}`},
+		{"formatted cpf", "owner 529.982.247-25", "owner [REDACTED]"},
+		{"bare cpf", "owner 52998224725", "owner [REDACTED]"},
+		{"cpf with wrong check digits", "owner 529.982.247-26", "owner 529.982.247-26"},
+		{"repeated digits", "owner 111.111.111-11", "owner 111.111.111-11"},
+		{"formatted cnpj", "payer 11.222.333/0001-81", "payer [REDACTED]"},
+		{"bare cnpj", "payer 11222333000181", "payer [REDACTED]"},
+		{"cnpj with wrong check digits", "payer 11.222.333/0001-80", "payer 11.222.333/0001-80"},
+		{"timestamp", "took until 17295120001", "took until 17295120001"},
+		{"long id", "item 12345678901234", "item 12345678901234"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Scrub(tt.in); got != tt.want {
+				t.Errorf("Scrub(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidDocuments(t *testing.T) {
+	tests := []struct {
+		in        string
+		cpf, cnpj bool
+	}{
+		{"529.982.247-25", true, false},
+		{"52998224725", true, false},
+		{"52998224724", false, false},
+		{"00000000000", false, false},
+		{"11.222.333/0001-81", false, true},
+		{"11444777000161", false, true},
+		{"11444777000162", false, false},
+		{"00000000000000", false, false},
+	}
+	for _, tt := range tests {
+		if got := validCPF(tt.in); got != tt.cpf {
+			t.Errorf("validCPF(%q) = %t, want %t", tt.in, got, tt.cpf)
+		}
+		if got := validCNPJ(tt.in); got != tt.cnpj {
+			t.Errorf("validCNPJ(%q) = %t, want %t", tt.in, got, tt.cnpj)
+		}
+	}
+}
+
+func TestScrubAttr(t *testing.T) {
+	tests := []struct {
+		name string
+		attr slog.Attr
+		want string
+	}{
+		{"secret key", slog.String("client_secret", "s3cret"), "[REDACTED]"},
+		{"secret key of another kind", slog.Int("cpf", 52998224725), "[REDACTED]"},
+		{"string value", slog.String("msg", "cpf 529.982.247-25"), "cpf [REDACTED]"},
+		{"error value", slog.Any("error", errors.New(`{"accessToken":"abc"}`)), `{"accessToken":"[REDACTED]"}`},
+		{"other value kept", slog.Int("count", 52998224725), "52998224725"},
+		{"group", slog.Group("req", slog.String("password", "hunter22")), "[password=[REDACTED]]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scrubAttr(tt.attr).Value.String(); got != tt.want {
+				t.Errorf("scrubAttr(%v) = %q, want %q", tt.attr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScrubHandler(t *testing.T) {
+	addSecrets("short", "configured-secret-value")
+
+	tests := []struct {
+		name    string
+		log     func(l *slog.Logger)
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "message",
+			log:     func(l *slog.Logger) { l.Info("using configured-secret-value") },
+			want:    []string{"using [REDACTED]"},
+			notWant: []string{"configured-secret-value"},
+		},
+		{
+			name:    "short secrets are not registered",
+			log:     func(l *slog.Logger) { l.Info("a short message") },
+			want:    []string{"a short message"},
+			notWant: []string{"[REDACTED]"},
+		},
+		{
+			name:    "with attrs",
+			log:     func(l *slog.Logger) { l.With("api_key", "abc").Info("call") },
+			want:    []string{"api_key=[REDACTED]"},
+			notWant: []string{"abc"},
+		},
+		{
+			name:    "with group",
+			log:     func(l *slog.Logger) { l.WithGroup("pluggy").Info("call", "taxNumber", "529.982.247-25") },
+			want:    []string{"pluggy.taxNumber=[REDACTED]"},
+			notWant: []string{"529.982.247-25"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			tt.log(slog.New(scrubHandler{slog.NewTextHandler(&out, nil)}))
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("log misses %q:\n%s", want, out.String())
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(out.String(), notWant) {
+					t.Errorf("log has %q:\n%s", notWant, out.String())
+				}
+			}
+		})
+	}
+
+	h := scrubHandler{slog.NewTextHandler(&bytes.Buffer{}, &slog.HandlerOptions{Level: slog.LevelWarn})}
+	if h.Enabled(context.Background(), slog.LevelInfo) {
+		t.Errorf("the handler should follow the level of the one it wraps")
+	}
+}
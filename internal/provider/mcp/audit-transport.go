@@ -0,0 +1,197 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/metoro-io/mcp-golang/transport"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/audit"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/logger"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/redact"
+)
+
+// AuditTransport writes an audit entry for every tool call and resource read
+// to log. It has to wrap the transport before ToolRegistry.Transport, so it
+// records the error results once they follow the ToolError contract, and
+// before ResourceRegistry.Transport, so it sees the resource requests.
+//
+// Calls are matched with their replies by request ID rather than through the
+// context: the MCP library sends the error responses of failed handlers on a
+// background context.
+func AuditTransport(next transport.Transport, log *audit.Log) transport.Transport {
+	if log == nil {
+		return next
+	}
+	return &auditTransport{Transport: next, log: log, clients: map[string]string{}}
+}
+
+type auditTransport struct {
+	transport.Transport
+	log *audit.Log
+
+	mu      sync.Mutex
+	clients map[string]string // session -> client name and version
+
+	pending sync.Map // transport.RequestId -> *audit.Call
+}
+
+func (t *auditTransport) SetMessageHandler(handler func(ctx context.Context, message *transport.BaseJsonRpcMessage)) {
+	t.Transport.SetMessageHandler(func(ctx context.Context, message *transport.BaseJsonRpcMessage) {
+		if message.Type == transport.BaseMessageTypeJSONRPCRequestType {
+			switch message.JsonRpcRequest.Method {
+			case "initialize":
+				t.initialize(ctx, message.JsonRpcRequest.Params)
+			case "tools/call":
+				ctx = t.track(ctx, message.JsonRpcRequest.Id, t.call(ctx, message.JsonRpcRequest.Params))
+			case "resources/list", "resources/read":
+				ctx = t.track(ctx, message.JsonRpcRequest.Id, t.resourceCall(ctx, message.JsonRpcRequest.Method, message.JsonRpcRequest.Params))
+			}
+		}
+		handler(ctx, message)
+	})
+}
+
+// track keeps call until the reply to request id is sent and returns a
+// context recording the Pluggy endpoints it hits.
+func (t *auditTransport) track(ctx context.Context, id transport.RequestId, call *audit.Call) context.Context {
+	t.pending.Store(id, call)
+	return audit.WithCall(ctx, call)
+}
+
+func (t *auditTransport) initialize(ctx context.Context, raw json.RawMessage) {
+	var params struct {
+		ClientInfo struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"clientInfo"`
+	}
+	if err := json.Unmarshal(raw, &params); err != nil || params.ClientInfo.Name == "" {
+		return
+	}
+
+	client := params.ClientInfo.Name
+	if params.ClientInfo.Version != "" {
+		client += "/" + params.ClientInfo.Version
+	}
+
+	t.mu.Lock()
+	t.clients[SessionID(ctx)] = client
+	t.mu.Unlock()
+}
+
+func (t *auditTransport) call(ctx context.Context, raw json.RawMessage) *audit.Call {
+	var params struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	_ = json.Unmarshal(raw, &params)
+
+	call := &audit.Call{
+		Start:   time.Now(),
+		Tool:    params.Name,
+		Session: SessionID(ctx),
+	}
+
+	if len(params.Arguments) > 0 {
+		args, err := redact.JSON("audit", params.Arguments)
+		if err != nil {
+			logger.Errorf("[audit] error redacting the arguments of %s: %v", params.Name, err)
+		} else {
+			call.Arguments = args
+		}
+	}
+
+	t.mu.Lock()
+	call.Client = t.clients[call.Session]
+	t.mu.Unlock()
+
+	return call
+}
+
+// resourceCall records a resource request under its method, the URI read
+// being its argument.
+func (t *auditTransport) resourceCall(ctx context.Context, method string, raw json.RawMessage) *audit.Call {
+	call := &audit.Call{
+		Start:   time.Now(),
+		Tool:    method,
+		Session: SessionID(ctx),
+	}
+
+	var params struct {
+		URI string `json:"uri"`
+	}
+	if json.Unmarshal(raw, &params) == nil && params.URI != "" {
+		call.Arguments, _ = json.Marshal(params)
+	}
+
+	t.mu.Lock()
+	call.Client = t.clients[call.Session]
+	t.mu.Unlock()
+
+	return call
+}
+
+func (t *auditTransport) Send(ctx context.Context, message *transport.BaseJsonRpcMessage) error {
+	switch message.Type {
+	case transport.BaseMessageTypeJSONRPCResponseType:
+		if call, ok := t.pending.LoadAndDelete(message.JsonRpcResponse.Id); ok {
+			t.write(call.(*audit.Call), message.JsonRpcResponse.Result, false)
+		}
+	case transport.BaseMessageTypeJSONRPCErrorType:
+		if call, ok := t.pending.LoadAndDelete(message.JsonRpcError.Id); ok {
+			t.write(call.(*audit.Call), nil, true)
+		}
+	}
+	return t.Transport.Send(ctx, message)
+}
+
+func (t *auditTransport) write(call *audit.Call, result json.RawMessage, protocolError bool) {
+	entry := audit.Entry{
+		Time:        call.Start,
+		Tool:        call.Tool,
+		Arguments:   call.Arguments,
+		Session:     call.Session,
+		Client:      call.Client,
+		Endpoints:   call.Endpoints(),
+		LatencyMS:   time.Since(call.Start).Milliseconds(),
+		ResultBytes: len(result),
+		Outcome:     audit.OutcomeOK,
+	}
+
+	code, failed := resultErrorCode(result)
+	switch {
+	case protocolError:
+		entry.Outcome = audit.OutcomeError
+		entry.ErrorCode = "protocol"
+	case string(result) == "null":
+		// The MCP server answers calls to unknown tools with an empty result.
+		entry.Outcome = audit.OutcomeError
+		entry.ErrorCode = "unknown_tool"
+	case failed:
+		entry.Outcome = audit.OutcomeError
+		entry.ErrorCode = string(code)
+	}
+
+	if err := t.log.Write(entry); err != nil {
+		logger.Error("[audit] error writing entry", "tool", entry.Tool, "error", err)
+	}
+}
+
+// resultErrorCode returns the code of a tool result carrying a ToolError.
+func resultErrorCode(raw json.RawMessage) (ErrorCode, bool) {
+	var result toolResult
+	if err := json.Unmarshal(raw, &result); err != nil || !result.IsError {
+		return "", false
+	}
+
+	var body struct {
+		Error ToolError `json:"error"`
+	}
+	if len(result.Content) == 1 && json.Unmarshal([]byte(result.Content[0].Text), &body) == nil && body.Error.Code != "" {
+		return body.Error.Code, true
+	}
+	return ErrorInternal, true
+}
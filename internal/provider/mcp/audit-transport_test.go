@@ -0,0 +1,148 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/metoro-io/mcp-golang/transport"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/audit"
+)
+
+func TestAuditTransport(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		reply     *transport.BaseJsonRpcMessage // sent for request 1 on a background context
+		want      *audit.Entry
+		endpoints []string
+	}{
+		{
+			name:      "result",
+			method:    "tools/call",
+			reply:     transport.NewBaseMessageResponse(&transport.BaseJSONRPCResponse{Id: 1, Result: json.RawMessage(`{"content":[{"type":"text","text":"[]"}]}`)}),
+			want:      &audit.Entry{Tool: "get_accounts", Outcome: audit.OutcomeOK},
+			endpoints: []string{"GET /accounts", "GET /items/{id}"},
+		},
+		{
+			name:   "tool error",
+			method: "tools/call",
+			reply: transport.NewBaseMessageResponse(&transport.BaseJSONRPCResponse{Id: 1, Result: json.RawMessage(
+				`{"content":[{"type":"text","text":"{\"error\":{\"code\":\"not_found\",\"message\":\"no such item\"}}"}],"isError":true}`,
+			)}),
+			want: &audit.Entry{Tool: "get_accounts", Outcome: audit.OutcomeError, ErrorCode: "not_found"},
+		},
+		{
+			name:   "protocol error",
+			method: "tools/call",
+			reply:  transport.NewBaseMessageError(&transport.BaseJSONRPCError{Id: 1, Error: transport.BaseJSONRPCErrorInner{Code: -32603, Message: "handler failed"}}),
+			want:   &audit.Entry{Tool: "get_accounts", Outcome: audit.OutcomeError, ErrorCode: "protocol"},
+		},
+		{
+			name:   "unknown tool",
+			method: "tools/call",
+			reply:  transport.NewBaseMessageResponse(&transport.BaseJSONRPCResponse{Id: 1, Result: json.RawMessage(`null`)}),
+			want:   &audit.Entry{Tool: "get_accounts", Outcome: audit.OutcomeError, ErrorCode: "unknown_tool"},
+		},
+		{
+			name:   "failed resource read",
+			method: "resources/read",
+			reply:  transport.NewBaseMessageError(&transport.BaseJSONRPCError{Id: 1, Error: transport.BaseJSONRPCErrorInner{Message: "upstream failed"}}),
+			want:   &audit.Entry{Tool: "resources/read", Outcome: audit.OutcomeError, ErrorCode: "protocol"},
+		},
+		{
+			name:   "other method",
+			method: "tools/list",
+			reply:  transport.NewBaseMessageResponse(&transport.BaseJSONRPCResponse{Id: 1, Result: json.RawMessage(`{"tools":[]}`)}),
+		},
+		{
+			name:   "reply to another request",
+			method: "tools/call",
+			reply:  transport.NewBaseMessageResponse(&transport.BaseJSONRPCResponse{Id: 2, Result: json.RawMessage(`{}`)}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "audit.jsonl")
+			log := audit.Open(audit.Options{Path: path})
+			defer log.Close()
+
+			base := newFakeTransport()
+			tr := AuditTransport(base, log)
+			tr.SetMessageHandler(func(ctx context.Context, _ *transport.BaseJsonRpcMessage) {
+				for _, endpoint := range tt.endpoints {
+					audit.Endpoint(ctx, endpoint)
+				}
+			})
+
+			ctx := context.WithValue(context.Background(), sessionKey{}, "session-1")
+			params := map[string]any{"name": "get_accounts", "uri": "openfinance://accounts/acc_1"}
+			base.receive(t, ctx, 1, tt.method, params)
+
+			// The MCP library sends the error responses of failed handlers
+			// without the request's context.
+			if err := tr.Send(context.Background(), tt.reply); err != nil {
+				t.Fatal(err)
+			}
+
+			entries, err := audit.Read(path, audit.Query{})
+			if err != nil && !os.IsNotExist(err) {
+				t.Fatal(err)
+			}
+			if tt.want == nil {
+				if len(entries) != 0 {
+					t.Errorf("entries = %+v, want none", entries)
+				}
+				return
+			}
+			if len(entries) != 1 {
+				t.Fatalf("entries = %+v, want one", entries)
+			}
+			got := entries[0]
+			if got.Tool != tt.want.Tool || got.Outcome != tt.want.Outcome || got.ErrorCode != tt.want.ErrorCode || got.Session != "session-1" {
+				t.Errorf("entry = %+v, want %+v", got, tt.want)
+			}
+			if !reflect.DeepEqual(got.Endpoints, tt.endpoints) {
+				t.Errorf("endpoints = %v, want %v", got.Endpoints, tt.endpoints)
+			}
+
+			// The call is forgotten once answered.
+			if err := tr.Send(context.Background(), tt.reply); err != nil {
+				t.Fatal(err)
+			}
+			if entries, _ := audit.Read(path, audit.Query{}); len(entries) != 1 {
+				t.Errorf("a second reply wrote %d entries, want the first one only", len(entries))
+			}
+		})
+	}
+}
+
+func TestAuditTransportClientAndArguments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log := audit.Open(audit.Options{Path: path})
+	defer log.Close()
+
+	base := newFakeTransport()
+	tr := AuditTransport(base, log)
+	tr.SetMessageHandler(func(context.Context, *transport.BaseJsonRpcMessage) {})
+
+	ctx := context.Background()
+	base.receive(t, ctx, 1, "initialize", map[string]any{"clientInfo": map[string]string{"name": "inspector", "version": "1.0"}})
+	base.receive(t, ctx, 2, "tools/call", map[string]any{"name": "get_accounts", "arguments": map[string]string{"item_id": "item_1"}})
+	if err := tr.Send(ctx, transport.NewBaseMessageResponse(&transport.BaseJSONRPCResponse{Id: 2, Result: json.RawMessage(`{}`)})); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"client":"inspector/1.0"`) || !strings.Contains(string(data), `"item_id":"item_1"`) {
+		t.Errorf("entry = %s, want the client and the arguments", data)
+	}
+}
@@ -0,0 +1,683 @@
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/metoro-io/mcp-golang/transport"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/logger"
+)
+
+const maxMessageSize = 4 << 20
+
+// Sessions idle for longer are forgotten, as their pseudonyms are; the client
+// then gets a 404 and starts a new one.
+const (
+	sessionIdleTimeout = 24 * time.Hour
+	sessionSweepPeriod = 10 * time.Minute
+)
+
+type HTTPTransportOptions struct {
+	Addr      string // e.g. "127.0.0.1:8080"
+	TLSCert   string // enables HTTPS together with TLSKey
+	TLSKey    string
+	AuthToken string // required as "Authorization: Bearer <token>" when set
+}
+
+// HTTPTransport serves MCP over HTTP so several clients can share one server.
+// It speaks the streamable HTTP transport on /mcp and the older HTTP+SSE
+// transport on /sse and /messages.
+//
+// All clients share a single protocol instance, so request IDs are rewritten
+// to server-unique ones on the way in and restored on the way out. The IDs
+// that cancel notifications refer to are rewritten the same way.
+//
+// Session IDs are only accepted from the client they were issued to: a
+// session carries its pseudonyms and its requests, so guessing or replaying
+// another client's ID must not reach them.
+type HTTPTransport struct {
+	opts   HTTPTransportOptions
+	server *http.Server
+	done   chan struct{}
+
+	mu             sync.RWMutex
+	messageHandler func(ctx context.Context, message *transport.BaseJsonRpcMessage)
+	errorHandler   func(error)
+	closeHandler   func()
+
+	nextID     atomic.Int64
+	pending    sync.Map // internal request id -> *pendingRequest
+	internalID sync.Map // clientRequest -> internal request id
+	sessions   sync.Map // session id -> *session
+	streams    sync.Map // *sseSession -> session id
+}
+
+// session is a session ID the server issued, and to whom.
+type session struct {
+	credential [sha256.Size]byte // hash of the bearer token it was issued to
+	lastSeen   atomic.Int64      // unix nanoseconds
+}
+
+func (s *session) touch() {
+	s.lastSeen.Store(time.Now().UnixNano())
+}
+
+type pendingRequest struct {
+	client     clientRequest
+	originalID json.RawMessage // clients may use string ids, the protocol only numbers
+	sink       sink
+}
+
+// clientRequest is a request as its client knows it. IDs are only unique
+// within a session.
+type clientRequest struct {
+	session string
+	id      string
+}
+
+// sink is where messages addressed to one client end up: the response stream
+// of a streamable HTTP POST or a long-lived SSE session.
+type sink interface {
+	deliver(data []byte) error
+}
+
+type sinkKey struct{}
+
+type sessionKey struct{}
+
+// SessionID returns the MCP session of the client behind ctx. Stdio has a
+// single implicit session.
+func SessionID(ctx context.Context) string {
+	if id, ok := ctx.Value(sessionKey{}).(string); ok && id != "" {
+		return id
+	}
+	return "stdio"
+}
+
+func NewHTTPTransport(opts HTTPTransportOptions) *HTTPTransport {
+	return &HTTPTransport{opts: opts, done: make(chan struct{})}
+}
+
+func (t *HTTPTransport) Start(ctx context.Context) error {
+	listener, err := net.Listen("tcp", t.opts.Addr)
+	if err != nil {
+		return fmt.Errorf("http transport: error listening on %s: %w", t.opts.Addr, err)
+	}
+
+	t.server = &http.Server{
+		Handler:           t.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go t.sweepSessions()
+	go func() {
+		var err error
+		if t.opts.TLSCert != "" {
+			err = t.server.ServeTLS(listener, t.opts.TLSCert, t.opts.TLSKey)
+		} else {
+			err = t.server.Serve(listener)
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			t.handleError(fmt.Errorf("http transport: %w", err))
+		}
+	}()
+
+	scheme := "http"
+	if t.opts.TLSCert != "" {
+		scheme = "https"
+	}
+	logger.Infof("[mcp] HTTP transport listening on %s://%s/mcp", scheme, listener.Addr())
+	return nil
+}
+
+func (t *HTTPTransport) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /mcp", t.authorize(t.handleStreamablePost))
+	mux.HandleFunc("GET /mcp", t.authorize(t.handleStream))
+	mux.HandleFunc("DELETE /mcp", t.authorize(t.handleDelete))
+	mux.HandleFunc("GET /sse", t.authorize(t.handleStream))
+	mux.HandleFunc("POST /messages", t.authorize(t.handleSSEPost))
+	return mux
+}
+
+func (t *HTTPTransport) Send(ctx context.Context, message *transport.BaseJsonRpcMessage) error {
+	switch message.Type {
+	case transport.BaseMessageTypeJSONRPCResponseType:
+		return t.sendReply(message, &message.JsonRpcResponse.Id)
+	case transport.BaseMessageTypeJSONRPCErrorType:
+		return t.sendReply(message, &message.JsonRpcError.Id)
+	case transport.BaseMessageTypeJSONRPCNotificationType:
+		data, err := json.Marshal(message)
+		if err != nil {
+			return err
+		}
+		// Notifications tied to a request (e.g. progress) go to that client
+		// and those of a session to its streams. Only list changes, which
+		// say nothing about anyone's data, go to every stream.
+		if s, ok := ctx.Value(sinkKey{}).(sink); ok {
+			return s.deliver(data)
+		}
+		sessionID, _ := ctx.Value(sessionKey{}).(string)
+		if sessionID == "" && !strings.HasSuffix(message.JsonRpcNotification.Method, "/list_changed") {
+			logger.Debug("[mcp] dropped a notification with no session", "method", message.JsonRpcNotification.Method)
+			return nil
+		}
+		t.streams.Range(func(s, id any) bool {
+			if sessionID == "" || id == sessionID {
+				_ = s.(*sseSession).deliver(data)
+			}
+			return true
+		})
+		return nil
+	default:
+		return fmt.Errorf("http transport: server-initiated %s messages are not supported", message.Type)
+	}
+}
+
+func (t *HTTPTransport) sendReply(message *transport.BaseJsonRpcMessage, id *transport.RequestId) error {
+	v, ok := t.pending.LoadAndDelete(*id)
+	if !ok {
+		return fmt.Errorf("http transport: no pending request with id %d", *id)
+	}
+	p := v.(*pendingRequest)
+	t.internalID.Delete(p.client)
+
+	data, err := withID(message, p.originalID)
+	if err != nil {
+		return err
+	}
+	return p.sink.deliver(data)
+}
+
+func (t *HTTPTransport) Close() error {
+	var err error
+	if t.server != nil {
+		err = t.server.Close()
+	}
+	select {
+	case <-t.done:
+	default:
+		close(t.done)
+	}
+	t.streams.Range(func(s, _ any) bool {
+		s.(*sseSession).close()
+		return true
+	})
+
+	t.mu.RLock()
+	handler := t.closeHandler
+	t.mu.RUnlock()
+	if handler != nil {
+		handler()
+	}
+	return err
+}
+
+func (t *HTTPTransport) SetCloseHandler(handler func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closeHandler = handler
+}
+
+func (t *HTTPTransport) SetErrorHandler(handler func(error)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.errorHandler = handler
+}
+
+func (t *HTTPTransport) SetMessageHandler(handler func(ctx context.Context, message *transport.BaseJsonRpcMessage)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.messageHandler = handler
+}
+
+func (t *HTTPTransport) handleError(err error) {
+	t.mu.RLock()
+	handler := t.errorHandler
+	t.mu.RUnlock()
+
+	logger.Error("[mcp] HTTP transport error", "error", err)
+	if handler != nil {
+		handler(err)
+	}
+}
+
+func (t *HTTPTransport) authorize(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if t.opts.AuthToken != "" {
+			token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !found || subtle.ConstantTimeCompare([]byte(token), []byte(t.opts.AuthToken)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="openfinance-mcp"`)
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+// newSession issues a session ID to the client behind r.
+func (t *HTTPTransport) newSession(r *http.Request) (string, error) {
+	id, err := newSessionID()
+	if err != nil {
+		return "", err
+	}
+	s := &session{credential: credential(r)}
+	s.touch()
+	t.sessions.Store(id, s)
+	return id, nil
+}
+
+// session reports whether id was issued to the client behind r, and keeps it
+// alive if so. Unknown IDs and IDs of other clients look the same to the
+// caller.
+func (t *HTTPTransport) session(r *http.Request, id string) bool {
+	v, ok := t.sessions.Load(id)
+	if !ok {
+		return false
+	}
+	s := v.(*session)
+	want := credential(r)
+	if subtle.ConstantTimeCompare(s.credential[:], want[:]) != 1 {
+		return false
+	}
+	s.touch()
+	return true
+}
+
+// endSession forgets id and closes its streams.
+func (t *HTTPTransport) endSession(id string) {
+	t.sessions.Delete(id)
+	t.streams.Range(func(s, sessionID any) bool {
+		if sessionID == id {
+			s.(*sseSession).close()
+		}
+		return true
+	})
+}
+
+func (t *HTTPTransport) sweepSessions() {
+	ticker := time.NewTicker(sessionSweepPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			t.expireSessions(time.Now())
+		case <-t.done:
+			return
+		}
+	}
+}
+
+func (t *HTTPTransport) expireSessions(now time.Time) {
+	t.sessions.Range(func(id, v any) bool {
+		if now.Sub(time.Unix(0, v.(*session).lastSeen.Load())) > sessionIdleTimeout {
+			t.endSession(id.(string))
+		}
+		return true
+	})
+}
+
+// credential identifies the client behind r by its bearer token.
+func credential(r *http.Request) [sha256.Size]byte {
+	return sha256.Sum256([]byte(r.Header.Get("Authorization")))
+}
+
+// dispatch hands one incoming message to the protocol. Requests are
+// registered as pending so their reply finds its way back to s.
+func (t *HTTPTransport) dispatch(ctx context.Context, body []byte, s sink) (isRequest bool, err error) {
+	var probe struct {
+		ID     *json.RawMessage `json:"id"`
+		Method string           `json:"method"`
+	}
+	if err := json.Unmarshal(body, &probe); err != nil {
+		return false, fmt.Errorf("invalid JSON-RPC message: %w", err)
+	}
+
+	var message *transport.BaseJsonRpcMessage
+	switch {
+	case probe.Method != "" && probe.ID != nil:
+		internalID := transport.RequestId(t.nextID.Add(1))
+		rewritten, err := withID(json.RawMessage(body), json.RawMessage(fmt.Sprint(internalID)))
+		if err != nil {
+			return false, fmt.Errorf("invalid JSON-RPC request: %w", err)
+		}
+
+		var request transport.BaseJSONRPCRequest
+		if err := json.Unmarshal(rewritten, &request); err != nil {
+			return false, fmt.Errorf("invalid JSON-RPC request: %w", err)
+		}
+
+		client := clientRequest{session: SessionID(ctx), id: string(*probe.ID)}
+		t.internalID.Store(client, internalID)
+		t.pending.Store(internalID, &pendingRequest{client: client, originalID: *probe.ID, sink: s})
+		message = transport.NewBaseMessageRequest(&request)
+		isRequest = true
+	case probe.Method != "":
+		if probe.Method == "notifications/cancelled" {
+			var ok bool
+			if body, ok = t.cancelledRequest(ctx, body); !ok {
+				// Not a request of this session, which must not cancel
+				// another client's.
+				return false, nil
+			}
+		}
+
+		var notification transport.BaseJSONRPCNotification
+		if err := json.Unmarshal(body, &notification); err != nil {
+			return false, fmt.Errorf("invalid JSON-RPC notification: %w", err)
+		}
+		// The library's unmarshaling drops the params, which cancellations
+		// need.
+		var params struct {
+			Params json.RawMessage `json:"params"`
+		}
+		if err := json.Unmarshal(body, &params); err == nil {
+			notification.Params = params.Params
+		}
+		message = transport.NewBaseMessageNotification(&notification)
+	default:
+		// Responses to server-initiated requests, which this server never sends.
+		return false, nil
+	}
+
+	t.mu.RLock()
+	handler := t.messageHandler
+	t.mu.RUnlock()
+	if handler != nil {
+		handler(context.WithValue(ctx, sinkKey{}, s), message)
+	}
+	return isRequest, nil
+}
+
+// cancelledRequest rewrites the request a cancel notification refers to into
+// its internal ID, or reports false when the session has no such request
+// pending.
+func (t *HTTPTransport) cancelledRequest(ctx context.Context, body []byte) ([]byte, bool) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, false
+	}
+	var params map[string]json.RawMessage
+	if err := json.Unmarshal(fields["params"], &params); err != nil || params["requestId"] == nil {
+		return nil, false
+	}
+
+	v, ok := t.internalID.Load(clientRequest{session: SessionID(ctx), id: string(params["requestId"])})
+	if !ok {
+		return nil, false
+	}
+	params["requestId"] = json.RawMessage(fmt.Sprint(v))
+
+	var err error
+	if fields["params"], err = json.Marshal(params); err != nil {
+		return nil, false
+	}
+	if body, err = json.Marshal(fields); err != nil {
+		return nil, false
+	}
+	return body, true
+}
+
+// handleStreamablePost answers a single JSON-RPC message. The reply is sent as
+// plain JSON, or as an SSE stream carrying progress notifications first when
+// the client accepts it.
+func (t *HTTPTransport) handleStreamablePost(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxMessageSize))
+	if err != nil {
+		http.Error(w, "error reading body", http.StatusBadRequest)
+		return
+	}
+
+	sessionID := r.Header.Get("Mcp-Session-Id")
+	issued := sessionID == ""
+	if issued {
+		if sessionID, err = t.newSession(r); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	} else if !t.session(r, sessionID) {
+		http.Error(w, "unknown session", http.StatusNotFound)
+		return
+	}
+	ctx := context.WithValue(r.Context(), sessionKey{}, sessionID)
+
+	s := &postSink{messages: make(chan []byte, 16), stream: acceptsEventStream(r)}
+	isRequest, err := t.dispatch(ctx, body, s)
+	if !isRequest && issued {
+		// Nobody learns the ID without a reply to carry it.
+		t.sessions.Delete(sessionID)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !isRequest {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	w.Header().Set("Mcp-Session-Id", sessionID)
+	if s.stream {
+		writeEventStreamHeaders(w)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+	}
+
+	for {
+		select {
+		case data := <-s.messages:
+			if !s.stream {
+				if isReply(data) {
+					w.Write(data)
+					return
+				}
+				continue
+			}
+			writeEvent(w, "message", data)
+			if isReply(data) {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// handleStream opens a long-lived SSE stream. On /sse it starts a session of
+// the HTTP+SSE transport and announces where to POST messages; on /mcp it
+// carries the notifications of the streamable HTTP session in Mcp-Session-Id.
+func (t *HTTPTransport) handleStream(w http.ResponseWriter, r *http.Request) {
+	var id string
+	if r.URL.Path == "/sse" {
+		var err error
+		if id, err = t.newSession(r); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// The session lives as long as its stream.
+		defer t.sessions.Delete(id)
+	} else {
+		id = r.Header.Get("Mcp-Session-Id")
+		if id == "" {
+			http.Error(w, "Mcp-Session-Id is required", http.StatusBadRequest)
+			return
+		}
+		if !t.session(r, id) {
+			http.Error(w, "unknown session", http.StatusNotFound)
+			return
+		}
+	}
+
+	session := &sseSession{messages: make(chan []byte, 64), done: make(chan struct{})}
+	t.streams.Store(session, id)
+	defer t.streams.Delete(session)
+
+	writeEventStreamHeaders(w)
+	if r.URL.Path == "/sse" {
+		writeEvent(w, "endpoint", []byte("/messages?sessionId="+id))
+	}
+
+	keepAlive := time.NewTicker(25 * time.Second)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case data := <-session.messages:
+			writeEvent(w, "message", data)
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flush(w)
+		case <-session.done:
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// handleDelete ends the streamable HTTP session in Mcp-Session-Id.
+func (t *HTTPTransport) handleDelete(w http.ResponseWriter, r *http.Request) {
+	id := r.Header.Get("Mcp-Session-Id")
+	if id == "" || !t.session(r, id) {
+		http.Error(w, "unknown session", http.StatusNotFound)
+		return
+	}
+	t.endSession(id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (t *HTTPTransport) handleSSEPost(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("sessionId")
+	var stream *sseSession
+	if t.session(r, id) {
+		t.streams.Range(func(s, sessionID any) bool {
+			if sessionID == id {
+				stream = s.(*sseSession)
+				return false
+			}
+			return true
+		})
+	}
+	if stream == nil {
+		http.Error(w, "unknown session", http.StatusNotFound)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxMessageSize))
+	if err != nil {
+		http.Error(w, "error reading body", http.StatusBadRequest)
+		return
+	}
+
+	// The reply travels over the SSE stream, so the request must keep running
+	// after this POST is acknowledged.
+	ctx := context.WithValue(context.WithoutCancel(r.Context()), sessionKey{}, id)
+	if _, err := t.dispatch(ctx, body, stream); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+type postSink struct {
+	messages chan []byte
+	stream   bool
+}
+
+func (s *postSink) deliver(data []byte) error {
+	select {
+	case s.messages <- data:
+		return nil
+	case <-time.After(5 * time.Second):
+		return errors.New("http transport: client stopped reading")
+	}
+}
+
+type sseSession struct {
+	messages chan []byte
+	done     chan struct{}
+	once     sync.Once
+}
+
+func (s *sseSession) deliver(data []byte) error {
+	select {
+	case s.messages <- data:
+		return nil
+	case <-s.done:
+		return errors.New("http transport: session closed")
+	case <-time.After(5 * time.Second):
+		return errors.New("http transport: session stopped reading")
+	}
+}
+
+func (s *sseSession) close() {
+	s.once.Do(func() { close(s.done) })
+}
+
+// withID marshals a JSON-RPC message with its id replaced.
+func withID(message any, id json.RawMessage) ([]byte, error) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	fields["id"] = id
+	return json.Marshal(fields)
+}
+
+func isReply(data []byte) bool {
+	var probe struct {
+		Method string `json:"method"`
+	}
+	return json.Unmarshal(data, &probe) == nil && probe.Method == ""
+}
+
+func acceptsEventStream(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+func writeEventStreamHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flush(w)
+}
+
+func writeEvent(w http.ResponseWriter, event string, data []byte) {
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	flush(w)
+}
+
+func flush(w http.ResponseWriter) {
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("http transport: error generating session id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,338 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/metoro-io/mcp-golang/transport"
+)
+
+// echo is a protocol stand-in: it answers every request with the session it
+// ran in, and records the notifications it is handed.
+type echo struct {
+	t *HTTPTransport
+
+	mu            sync.Mutex
+	notifications []*transport.BaseJSONRPCNotification
+}
+
+func newEchoTransport(opts HTTPTransportOptions) (*HTTPTransport, *echo, *httptest.Server) {
+	t := NewHTTPTransport(opts)
+	e := &echo{t: t}
+	t.SetMessageHandler(e.handle)
+	return t, e, httptest.NewServer(t.handler())
+}
+
+func (e *echo) handle(ctx context.Context, message *transport.BaseJsonRpcMessage) {
+	switch message.Type {
+	case transport.BaseMessageTypeJSONRPCRequestType:
+		result, _ := json.Marshal(map[string]string{"session": SessionID(ctx)})
+		go e.t.Send(ctx, transport.NewBaseMessageResponse(&transport.BaseJSONRPCResponse{
+			Jsonrpc: "2.0",
+			Id:      message.JsonRpcRequest.Id,
+			Result:  result,
+		}))
+	case transport.BaseMessageTypeJSONRPCNotificationType:
+		e.mu.Lock()
+		e.notifications = append(e.notifications, message.JsonRpcNotification)
+		e.mu.Unlock()
+	}
+}
+
+func (e *echo) received() []*transport.BaseJSONRPCNotification {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]*transport.BaseJSONRPCNotification(nil), e.notifications...)
+}
+
+func request(t *testing.T, method, url, session, auth, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if session != "" {
+		req.Header.Set("Mcp-Session-Id", session)
+	}
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { res.Body.Close() })
+	return res
+}
+
+const ping = `{"jsonrpc":"2.0","id":"a-1","method":"ping"}`
+
+func TestStreamableSessions(t *testing.T) {
+	_, _, server := newEchoTransport(HTTPTransportOptions{})
+	defer server.Close()
+
+	first := request(t, http.MethodPost, server.URL+"/mcp", "", "Bearer alice", ping)
+	session := first.Header.Get("Mcp-Session-Id")
+	if first.StatusCode != http.StatusOK || session == "" {
+		t.Fatalf("first POST = %d with session %q, want 200 and a new session", first.StatusCode, session)
+	}
+	var reply struct {
+		ID     string `json:"id"`
+		Result struct {
+			Session string `json:"session"`
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(first.Body).Decode(&reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply.ID != "a-1" || reply.Result.Session != session {
+		t.Errorf("reply = %+v, want id a-1 in session %s", reply, session)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		session    string
+		auth       string
+		wantStatus int
+	}{
+		{"same client", http.MethodPost, session, "Bearer alice", http.StatusOK},
+		{"made up session", http.MethodPost, "0123456789abcdef0123456789abcdef", "Bearer alice", http.StatusNotFound},
+		{"another client's session", http.MethodPost, session, "Bearer mallory", http.StatusNotFound},
+		{"stream of another client's session", http.MethodGet, session, "Bearer mallory", http.StatusNotFound},
+		{"stream without a session", http.MethodGet, "", "Bearer alice", http.StatusBadRequest},
+		{"delete by another client", http.MethodDelete, session, "Bearer mallory", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := request(t, tt.method, server.URL+"/mcp", tt.session, tt.auth, ping)
+			if res.StatusCode != tt.wantStatus {
+				t.Errorf("%s = %d, want %d", tt.method, res.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+
+	if res := request(t, http.MethodDelete, server.URL+"/mcp", session, "Bearer alice", ""); res.StatusCode != http.StatusNoContent {
+		t.Fatalf("DELETE = %d, want 204", res.StatusCode)
+	}
+	if res := request(t, http.MethodPost, server.URL+"/mcp", session, "Bearer alice", ping); res.StatusCode != http.StatusNotFound {
+		t.Errorf("POST after DELETE = %d, want 404", res.StatusCode)
+	}
+}
+
+func TestNotificationWithoutSessionIssuesNone(t *testing.T) {
+	tr, _, server := newEchoTransport(HTTPTransportOptions{})
+	defer server.Close()
+
+	res := request(t, http.MethodPost, server.URL+"/mcp", "", "", `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	if res.StatusCode != http.StatusAccepted || res.Header.Get("Mcp-Session-Id") != "" {
+		t.Errorf("notification = %d with session %q, want 202 and no session", res.StatusCode, res.Header.Get("Mcp-Session-Id"))
+	}
+	tr.sessions.Range(func(id, _ any) bool {
+		t.Errorf("session %v was issued", id)
+		return true
+	})
+}
+
+func TestAuthToken(t *testing.T) {
+	_, _, server := newEchoTransport(HTTPTransportOptions{AuthToken: "s3cret"})
+	defer server.Close()
+
+	tests := []struct {
+		auth string
+		want int
+	}{
+		{"", http.StatusUnauthorized},
+		{"Bearer wrong", http.StatusUnauthorized},
+		{"s3cret", http.StatusUnauthorized},
+		{"Bearer s3cret", http.StatusOK},
+	}
+	for _, tt := range tests {
+		if res := request(t, http.MethodPost, server.URL+"/mcp", "", tt.auth, ping); res.StatusCode != tt.want {
+			t.Errorf("Authorization %q = %d, want %d", tt.auth, res.StatusCode, tt.want)
+		}
+	}
+}
+
+func TestCancelledRequest(t *testing.T) {
+	tr := NewHTTPTransport(HTTPTransportOptions{})
+	tr.internalID.Store(clientRequest{session: "alice", id: `"a-1"`}, transport.RequestId(7))
+
+	cancel := `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":"a-1","reason":"user"}}`
+	tests := []struct {
+		name    string
+		session string
+		body    string
+		wantOK  bool
+	}{
+		{"own request", "alice", cancel, true},
+		{"another session's request", "mallory", cancel, false},
+		{"unknown request", "alice", strings.Replace(cancel, "a-1", "a-2", 1), false},
+		{"no request id", "alice", `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{}}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), sessionKey{}, tt.session)
+			body, ok := tr.cancelledRequest(ctx, []byte(tt.body))
+			if ok != tt.wantOK {
+				t.Fatalf("cancelledRequest() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			var got struct {
+				Params struct {
+					RequestID json.RawMessage `json:"requestId"`
+					Reason    string          `json:"reason"`
+				} `json:"params"`
+			}
+			if err := json.Unmarshal(body, &got); err != nil {
+				t.Fatal(err)
+			}
+			if string(got.Params.RequestID) != "7" || got.Params.Reason != "user" {
+				t.Errorf("rewritten params = %s %q, want 7 and the reason kept", got.Params.RequestID, got.Params.Reason)
+			}
+		})
+	}
+}
+
+func TestNotificationRouting(t *testing.T) {
+	tr := NewHTTPTransport(HTTPTransportOptions{})
+	alice := &sseSession{messages: make(chan []byte, 8), done: make(chan struct{})}
+	bob := &sseSession{messages: make(chan []byte, 8), done: make(chan struct{})}
+	tr.streams.Store(alice, "alice")
+	tr.streams.Store(bob, "bob")
+
+	notify := func(ctx context.Context, method string) {
+		err := tr.Send(ctx, transport.NewBaseMessageNotification(&transport.BaseJSONRPCNotification{Jsonrpc: "2.0", Method: method}))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	inSession := func(id string) context.Context {
+		return context.WithValue(context.Background(), sessionKey{}, id)
+	}
+
+	notify(inSession("alice"), "notifications/message")
+	notify(context.Background(), "notifications/message")
+	notify(context.Background(), "notifications/tools/list_changed")
+
+	drain := func(s *sseSession) []string {
+		var methods []string
+		for {
+			select {
+			case data := <-s.messages:
+				var probe struct {
+					Method string `json:"method"`
+				}
+				_ = json.Unmarshal(data, &probe)
+				methods = append(methods, probe.Method)
+			default:
+				return methods
+			}
+		}
+	}
+
+	if got := strings.Join(drain(alice), ","); got != "notifications/message,notifications/tools/list_changed" {
+		t.Errorf("alice received %q, want her message and the list change", got)
+	}
+	if got := strings.Join(drain(bob), ","); got != "notifications/tools/list_changed" {
+		t.Errorf("bob received %q, want only the list change", got)
+	}
+}
+
+func TestSSESession(t *testing.T) {
+	_, _, server := newEchoTransport(HTTPTransportOptions{})
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/sse", nil)
+	req.Header.Set("Authorization", "Bearer alice")
+	stream, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Body.Close()
+
+	events := bufio.NewReader(stream.Body)
+	endpoint := ""
+	for endpoint == "" {
+		line, err := events.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading the endpoint event: %v", err)
+		}
+		if data, ok := strings.CutPrefix(strings.TrimSpace(line), "data: "); ok {
+			endpoint = data
+		}
+	}
+
+	if res := request(t, http.MethodPost, server.URL+endpoint, "", "Bearer mallory", ping); res.StatusCode != http.StatusNotFound {
+		t.Errorf("POST from another client = %d, want 404", res.StatusCode)
+	}
+	if res := request(t, http.MethodPost, server.URL+"/messages?sessionId=nope", "", "Bearer alice", ping); res.StatusCode != http.StatusNotFound {
+		t.Errorf("POST to an unknown session = %d, want 404", res.StatusCode)
+	}
+	res := request(t, http.MethodPost, server.URL+endpoint, "", "Bearer alice", ping)
+	if res.StatusCode != http.StatusAccepted {
+		t.Fatalf("POST = %d, want 202", res.StatusCode)
+	}
+	io.Copy(io.Discard, res.Body)
+
+	done := make(chan string)
+	go func() {
+		for {
+			line, err := events.ReadString('\n')
+			if err != nil {
+				close(done)
+				return
+			}
+			if data, ok := strings.CutPrefix(strings.TrimSpace(line), "data: "); ok {
+				done <- data
+				return
+			}
+		}
+	}()
+	select {
+	case data := <-done:
+		if !strings.Contains(data, `"id":"a-1"`) {
+			t.Errorf("reply = %s, want the client's id", data)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no reply on the SSE stream")
+	}
+}
+
+func TestExpireSessions(t *testing.T) {
+	tr := NewHTTPTransport(HTTPTransportOptions{})
+	idle, active := &session{}, &session{}
+	now := time.Now()
+	idle.lastSeen.Store(now.Add(-sessionIdleTimeout - time.Minute).UnixNano())
+	active.lastSeen.Store(now.Add(-time.Minute).UnixNano())
+	tr.sessions.Store("idle", idle)
+	tr.sessions.Store("active", active)
+
+	stream := &sseSession{messages: make(chan []byte, 1), done: make(chan struct{})}
+	tr.streams.Store(stream, "idle")
+
+	tr.expireSessions(now)
+
+	if _, ok := tr.sessions.Load("idle"); ok {
+		t.Errorf("idle session was kept")
+	}
+	if _, ok := tr.sessions.Load("active"); !ok {
+		t.Errorf("active session was dropped")
+	}
+	select {
+	case <-stream.done:
+	default:
+		t.Errorf("the idle session's stream was left open")
+	}
+}
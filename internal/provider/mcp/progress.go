@@ -0,0 +1,78 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/metoro-io/mcp-golang/transport"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/logger"
+)
+
+// progressReporter sends notifications/progress for one tool call.
+type progressReporter struct {
+	token json.RawMessage
+	send  func(ctx context.Context, message *transport.BaseJsonRpcMessage) error
+
+	mu       sync.Mutex
+	progress float64
+}
+
+type progressKey struct{}
+
+// Progress reports that the tool call behind ctx moved on, as a
+// notifications/progress with message. It does nothing when the client did
+// not ask for progress by sending a progress token.
+func Progress(ctx context.Context, message string) {
+	reporter, ok := ctx.Value(progressKey{}).(*progressReporter)
+	if !ok {
+		return
+	}
+
+	// Progress has to increase with every notification, even without a total.
+	reporter.mu.Lock()
+	reporter.progress++
+	progress := reporter.progress
+	reporter.mu.Unlock()
+
+	params, err := json.Marshal(map[string]any{
+		"progressToken": reporter.token,
+		"progress":      progress,
+		"message":       message,
+	})
+	if err != nil {
+		logger.Errorf("[mcp] error encoding progress: %v", err)
+		return
+	}
+
+	err = reporter.send(ctx, transport.NewBaseMessageNotification(&transport.BaseJSONRPCNotification{
+		Jsonrpc: "2.0",
+		Method:  "notifications/progress",
+		Params:  params,
+	}))
+	if err != nil {
+		logger.Errorf("[mcp] error sending progress: %v", err)
+	}
+}
+
+// SetMessageHandler hands tool calls that carry a progress token a context
+// Progress can report to.
+func (t *toolTransport) SetMessageHandler(handler func(ctx context.Context, message *transport.BaseJsonRpcMessage)) {
+	t.Transport.SetMessageHandler(func(ctx context.Context, message *transport.BaseJsonRpcMessage) {
+		if message.Type == transport.BaseMessageTypeJSONRPCRequestType && message.JsonRpcRequest.Method == "tools/call" {
+			var params struct {
+				Meta struct {
+					ProgressToken json.RawMessage `json:"progressToken"`
+				} `json:"_meta"`
+			}
+			if err := json.Unmarshal(message.JsonRpcRequest.Params, &params); err == nil && len(params.Meta.ProgressToken) > 0 && string(params.Meta.ProgressToken) != "null" {
+				ctx = context.WithValue(ctx, progressKey{}, &progressReporter{
+					token: params.Meta.ProgressToken,
+					send:  t.Transport.Send,
+				})
+			}
+		}
+		handler(ctx, message)
+	})
+}
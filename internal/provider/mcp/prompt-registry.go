@@ -0,0 +1,112 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	mcp "github.com/metoro-io/mcp-golang"
+	"github.com/metoro-io/mcp-golang/transport"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pseudonym"
+)
+
+// PromptHandlerFunc takes a struct whose fields are all string or *string,
+// which become the prompt arguments, and returns (*mcp.PromptResponse, error).
+type PromptHandlerFunc interface{}
+
+type PromptProvider interface {
+	Name() string
+	Description() string
+	Handle() PromptHandlerFunc
+}
+
+// PromptIdentifiers is implemented by prompts with arguments taking Pluggy
+// identifiers, by argument name. Their values are replaced by the session's
+// pseudonyms before the prompt sees them, so prompt text only holds those.
+type PromptIdentifiers interface {
+	Identifiers() map[string]pseudonym.Kind
+}
+
+type PromptRegistry struct {
+	handlers []PromptProvider
+}
+
+func NewPromptRegistry(handlers ...PromptProvider) *PromptRegistry {
+	return &PromptRegistry{handlers}
+}
+
+func (r *PromptRegistry) Register(s *mcp.Server) error {
+	for _, provider := range r.handlers {
+		err := s.RegisterPrompt(
+			provider.Name(),
+			provider.Description(),
+			provider.Handle(),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Transport works around the MCP server rejecting prompts/list requests sent
+// without params, which is how most clients send them, and pseudonymizes the
+// identifier arguments of prompts/get requests.
+func (r *PromptRegistry) Transport(next transport.Transport) transport.Transport {
+	return &promptTransport{next, r}
+}
+
+type promptTransport struct {
+	transport.Transport
+	registry *PromptRegistry
+}
+
+func (t *promptTransport) SetMessageHandler(handler func(ctx context.Context, message *transport.BaseJsonRpcMessage)) {
+	t.Transport.SetMessageHandler(func(ctx context.Context, message *transport.BaseJsonRpcMessage) {
+		if message.Type == transport.BaseMessageTypeJSONRPCRequestType &&
+			message.JsonRpcRequest.Method == "prompts/list" &&
+			len(message.JsonRpcRequest.Params) == 0 {
+			message.JsonRpcRequest.Params = json.RawMessage("{}")
+		}
+		if message.Type == transport.BaseMessageTypeJSONRPCRequestType &&
+			message.JsonRpcRequest.Method == "prompts/get" {
+			message.JsonRpcRequest.Params = t.registry.pseudonymize(ctx, message.JsonRpcRequest.Params)
+		}
+		handler(ctx, message)
+	})
+}
+
+// pseudonymize replaces the identifiers in the arguments of a prompts/get
+// request by the session's pseudonyms.
+func (r *PromptRegistry) pseudonymize(ctx context.Context, raw json.RawMessage) json.RawMessage {
+	var params struct {
+		Name      string            `json:"name"`
+		Arguments map[string]string `json:"arguments"`
+	}
+	if err := json.Unmarshal(raw, &params); err != nil || len(params.Arguments) == 0 {
+		return raw
+	}
+
+	for _, provider := range r.handlers {
+		ids, ok := provider.(PromptIdentifiers)
+		if !ok || provider.Name() != params.Name {
+			continue
+		}
+		// Arguments are advertised under their Go field names but decoded
+		// case-insensitively, so clients may send either.
+		for name, value := range params.Arguments {
+			for argument, kind := range ids.Identifiers() {
+				if strings.EqualFold(name, argument) {
+					params.Arguments[name] = pseudonym.Alias(SessionID(ctx), kind, value)
+				}
+			}
+		}
+	}
+
+	data, err := json.Marshal(params)
+	if err != nil {
+		return raw
+	}
+	return data
+}
@@ -0,0 +1,72 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	mcp "github.com/metoro-io/mcp-golang"
+	"github.com/metoro-io/mcp-golang/transport"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pseudonym"
+)
+
+type fakePrompt struct{}
+
+type fakePromptArgs struct {
+	Item  *string `json:"item,omitempty"`
+	Month *string `json:"month,omitempty"`
+}
+
+func (fakePrompt) Name() string        { return "review" }
+func (fakePrompt) Description() string { return "" }
+
+func (fakePrompt) Handle() PromptHandlerFunc {
+	return func(fakePromptArgs) (*mcp.PromptResponse, error) { return nil, nil }
+}
+
+func (fakePrompt) Identifiers() map[string]pseudonym.Kind {
+	return map[string]pseudonym.Kind{"item": pseudonym.Item}
+}
+
+func TestPromptTransport(t *testing.T) {
+	pseudonym.Enable()
+	ctx := context.WithValue(context.Background(), sessionKey{}, "prompt-session")
+	alias := pseudonym.Alias("prompt-session", pseudonym.Item, "0b0a7e52-item")
+
+	tests := []struct {
+		name   string
+		method string
+		params string
+		want   string
+	}{
+		{"list without params", "prompts/list", ``, `{}`},
+		{"list with params", "prompts/list", `{"cursor":"x"}`, `{"cursor":"x"}`},
+		{"identifier argument", "prompts/get", `{"name":"review","arguments":{"item":"0b0a7e52-item","month":"2024-01"}}`, `{"name":"review","arguments":{"item":"` + alias + `","month":"2024-01"}}`},
+		{"argument by field name", "prompts/get", `{"name":"review","arguments":{"Item":"0b0a7e52-item"}}`, `{"name":"review","arguments":{"Item":"` + alias + `"}}`},
+		{"pseudonym argument", "prompts/get", `{"name":"review","arguments":{"item":"item_7"}}`, `{"name":"review","arguments":{"item":"item_7"}}`},
+		{"other prompt", "prompts/get", `{"name":"other","arguments":{"item":"0b0a7e52-item"}}`, `{"name":"other","arguments":{"item":"0b0a7e52-item"}}`},
+		{"no arguments", "prompts/get", `{"name":"review"}`, `{"name":"review"}`},
+	}
+
+	base := newFakeTransport()
+	tr := NewPromptRegistry(fakePrompt{}).Transport(base)
+	var got json.RawMessage
+	tr.SetMessageHandler(func(_ context.Context, message *transport.BaseJsonRpcMessage) {
+		got = message.JsonRpcRequest.Params
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base.handler(ctx, transport.NewBaseMessageRequest(&transport.BaseJSONRPCRequest{
+				Jsonrpc: "2.0",
+				Id:      1,
+				Method:  tt.method,
+				Params:  json.RawMessage(tt.params),
+			}))
+			if string(got) != tt.want {
+				t.Errorf("params = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
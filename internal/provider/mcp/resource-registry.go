@@ -0,0 +1,238 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	mcp "github.com/metoro-io/mcp-golang"
+	"github.com/metoro-io/mcp-golang/transport"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/logger"
+)
+
+// ResourceProvider serves every URI matching an RFC 6570 style template such
+// as "openfinance://accounts/{account_id}/transactions{?month}". Tool is the
+// read tool serving the same data, which the tool policy must enable for the
+// resource to be served.
+type ResourceProvider interface {
+	URITemplate() string
+	Name() string
+	Description() string
+	MimeType() string
+	Tool() string
+	Read(ctx context.Context, uri string, params map[string]string) (*mcp.ResourceResponse, error)
+}
+
+// ResourceLister is implemented by providers that can enumerate concrete
+// resources, so clients see them in resources/list. Lists are built for each
+// request, as URIs may carry the session's pseudonyms.
+type ResourceLister interface {
+	List(ctx context.Context) ([]ResourceEntry, error)
+}
+
+type ResourceEntry struct {
+	URI         string
+	Name        string
+	Description string
+}
+
+type ResourceRegistry struct {
+	providers []ResourceProvider
+}
+
+func NewResourceRegistry(providers ...ResourceProvider) *ResourceRegistry {
+	return &ResourceRegistry{providers}
+}
+
+// SetPolicy keeps only the resources whose tool is enabled, so a resource
+// never hands out data the tool policy keeps from the model.
+func (r *ResourceRegistry) SetPolicy(enabled func(tool string) bool) {
+	providers := r.providers[:0]
+	for _, provider := range r.providers {
+		if !enabled(provider.Tool()) {
+			logger.Infof("[mcp] resource %s disabled by the tool policy (tool %s is disabled)", provider.Name(), provider.Tool())
+			continue
+		}
+		providers = append(providers, provider)
+	}
+	r.providers = providers
+}
+
+func (r *ResourceRegistry) Register(s *mcp.Server) error {
+	for _, provider := range r.providers {
+		err := s.RegisterResourceTemplate(
+			provider.URITemplate(),
+			provider.Name(),
+			provider.Description(),
+			provider.MimeType(),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *ResourceRegistry) list(ctx context.Context) *mcp.ListResourcesResponse {
+	res := &mcp.ListResourcesResponse{Resources: []*mcp.ResourceSchema{}}
+	for _, provider := range r.providers {
+		lister, ok := provider.(ResourceLister)
+		if !ok {
+			continue
+		}
+
+		entries, err := lister.List(ctx)
+		if err != nil {
+			logger.Errorf("[mcp] error listing %s resources: %v", provider.Name(), err)
+			continue
+		}
+		for _, entry := range entries {
+			res.Resources = append(res.Resources, &mcp.ResourceSchema{
+				Uri:         entry.URI,
+				Name:        entry.Name,
+				Description: &entry.Description,
+				MimeType:    ptr(provider.MimeType()),
+			})
+		}
+	}
+	return res
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func (r *ResourceRegistry) read(ctx context.Context, uri string) (*mcp.ResourceResponse, error) {
+	for _, provider := range r.providers {
+		if params, ok := matchTemplate(provider.URITemplate(), uri); ok {
+			return provider.Read(ctx, uri, params)
+		}
+	}
+	return nil, fmt.Errorf("unknown resource: %s", uri)
+}
+
+// Transport answers resources/list, and resources/read for templated URIs. The
+// MCP server only dispatches URIs registered one by one, and lists them the
+// same for every session, so both are served here before they reach it;
+// everything else passes through untouched. It has to wrap AuditTransport, so
+// resource reads are audited like tool calls.
+func (r *ResourceRegistry) Transport(next transport.Transport) transport.Transport {
+	return &resourceTransport{Transport: next, registry: r}
+}
+
+type resourceTransport struct {
+	transport.Transport
+	registry *ResourceRegistry
+}
+
+func (t *resourceTransport) SetMessageHandler(handler func(ctx context.Context, message *transport.BaseJsonRpcMessage)) {
+	t.Transport.SetMessageHandler(func(ctx context.Context, message *transport.BaseJsonRpcMessage) {
+		if message.Type == transport.BaseMessageTypeJSONRPCRequestType && message.JsonRpcRequest.Method == "resources/list" {
+			go func() {
+				t.reply(ctx, message.JsonRpcRequest.Id, "resources", t.registry.list(ctx), nil)
+			}()
+			return
+		}
+		if message.Type == transport.BaseMessageTypeJSONRPCRequestType && message.JsonRpcRequest.Method == "resources/read" {
+			var params struct {
+				URI string `json:"uri"`
+			}
+			if err := json.Unmarshal(message.JsonRpcRequest.Params, &params); err == nil && t.registry.matches(params.URI) {
+				go func() {
+					res, err := t.registry.read(ctx, params.URI)
+					t.reply(ctx, message.JsonRpcRequest.Id, "resource "+params.URI, res, err)
+				}()
+				return
+			}
+		}
+		handler(ctx, message)
+	})
+}
+
+// reply answers request id with res, or with err when it failed. what names
+// the request in the log.
+func (t *resourceTransport) reply(ctx context.Context, id transport.RequestId, what string, res any, err error) {
+	var reply *transport.BaseJsonRpcMessage
+	if err == nil {
+		var result json.RawMessage
+		if result, err = json.Marshal(res); err == nil {
+			reply = transport.NewBaseMessageResponse(&transport.BaseJSONRPCResponse{
+				Jsonrpc: "2.0",
+				Id:      id,
+				Result:  result,
+			})
+		}
+	}
+	if err != nil {
+		logger.Errorf("[mcp] error reading %s: %v", what, err)
+		reply = transport.NewBaseMessageError(&transport.BaseJSONRPCError{
+			Jsonrpc: "2.0",
+			Id:      id,
+			Error: transport.BaseJSONRPCErrorInner{
+				Code:    -32603,
+				Message: err.Error(),
+			},
+		})
+	}
+
+	if err := t.Send(ctx, reply); err != nil {
+		logger.Errorf("[mcp] error sending %s: %v", what, err)
+	}
+}
+
+func (r *ResourceRegistry) matches(uri string) bool {
+	for _, provider := range r.providers {
+		if _, ok := matchTemplate(provider.URITemplate(), uri); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchTemplate supports the two template forms used by the resources:
+// "{name}" path segments and a trailing "{?a,b}" query expansion.
+func matchTemplate(template, uri string) (map[string]string, bool) {
+	var queryVars []string
+	if i := strings.Index(template, "{?"); i >= 0 {
+		queryVars = strings.Split(strings.TrimSuffix(template[i+2:], "}"), ",")
+		template = template[:i]
+	}
+
+	path, rawQuery, _ := strings.Cut(uri, "?")
+
+	tSegments := strings.Split(template, "/")
+	uSegments := strings.Split(path, "/")
+	if len(tSegments) != len(uSegments) {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+	for i, segment := range tSegments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			value, err := url.PathUnescape(uSegments[i])
+			if err != nil || value == "" {
+				return nil, false
+			}
+			params[strings.Trim(segment, "{}")] = value
+			continue
+		}
+		if segment != uSegments[i] {
+			return nil, false
+		}
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, false
+	}
+	for _, name := range queryVars {
+		if value := query.Get(name); value != "" {
+			params[name] = value
+		}
+	}
+
+	return params, true
+}
@@ -0,0 +1,237 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	mcp "github.com/metoro-io/mcp-golang"
+	"github.com/metoro-io/mcp-golang/transport"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/audit"
+)
+
+// fakeTransport stands for the client side: tests hand it requests with
+// receive and read what the server sent back with replies.
+type fakeTransport struct {
+	handler func(ctx context.Context, message *transport.BaseJsonRpcMessage)
+
+	mu   sync.Mutex
+	sent []*transport.BaseJsonRpcMessage
+	got  chan struct{}
+}
+
+func newFakeTransport() *fakeTransport {
+	return &fakeTransport{got: make(chan struct{}, 64)}
+}
+
+func (f *fakeTransport) Start(context.Context) error { return nil }
+func (f *fakeTransport) Close() error                { return nil }
+func (f *fakeTransport) SetCloseHandler(func())      {}
+func (f *fakeTransport) SetErrorHandler(func(error)) {}
+
+func (f *fakeTransport) SetMessageHandler(handler func(ctx context.Context, message *transport.BaseJsonRpcMessage)) {
+	f.handler = handler
+}
+
+func (f *fakeTransport) Send(_ context.Context, message *transport.BaseJsonRpcMessage) error {
+	f.mu.Lock()
+	f.sent = append(f.sent, message)
+	f.mu.Unlock()
+	f.got <- struct{}{}
+	return nil
+}
+
+func (f *fakeTransport) receive(t *testing.T, ctx context.Context, id int, method string, params any) {
+	t.Helper()
+	raw, err := json.Marshal(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.handler(ctx, transport.NewBaseMessageRequest(&transport.BaseJSONRPCRequest{
+		Jsonrpc: "2.0",
+		Id:      transport.RequestId(id),
+		Method:  method,
+		Params:  raw,
+	}))
+}
+
+// reply waits for the next message the server sends.
+func (f *fakeTransport) reply(t *testing.T) *transport.BaseJsonRpcMessage {
+	t.Helper()
+	select {
+	case <-f.got:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no reply")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.sent[len(f.sent)-1]
+}
+
+type fakeResource struct {
+	template, tool string
+	read           func(params map[string]string) (*mcp.ResourceResponse, error)
+}
+
+func (r *fakeResource) URITemplate() string { return r.template }
+func (r *fakeResource) Name() string        { return r.tool }
+func (r *fakeResource) Description() string { return "" }
+func (r *fakeResource) MimeType() string    { return "application/json" }
+func (r *fakeResource) Tool() string        { return r.tool }
+
+func (r *fakeResource) Read(_ context.Context, uri string, params map[string]string) (*mcp.ResourceResponse, error) {
+	if r.read != nil {
+		return r.read(params)
+	}
+	data, _ := json.Marshal(params)
+	return mcp.NewResourceResponse(mcp.NewTextEmbeddedResource(uri, string(data), "application/json")), nil
+}
+
+func (r *fakeResource) List(context.Context) ([]ResourceEntry, error) {
+	return []ResourceEntry{{URI: strings.ReplaceAll(r.template, "{account_id}", "acc_1"), Name: r.tool}}, nil
+}
+
+func TestMatchTemplate(t *testing.T) {
+	tests := []struct {
+		template, uri string
+		want          map[string]string
+	}{
+		{"openfinance://items/{item_id}", "openfinance://items/item_1", map[string]string{"item_id": "item_1"}},
+		{"openfinance://items/{item_id}", "openfinance://items/a%2Fb", map[string]string{"item_id": "a/b"}},
+		{"openfinance://items/{item_id}", "openfinance://items/", nil},
+		{"openfinance://items/{item_id}", "openfinance://items/item_1/accounts", nil},
+		{"openfinance://items/{item_id}/accounts", "openfinance://items/item_1/accounts", map[string]string{"item_id": "item_1"}},
+		{"openfinance://accounts/{account_id}/transactions{?month}", "openfinance://accounts/acc_1/transactions", map[string]string{"account_id": "acc_1"}},
+		{"openfinance://accounts/{account_id}/transactions{?month}", "openfinance://accounts/acc_1/transactions?month=2026-09&x=1", map[string]string{"account_id": "acc_1", "month": "2026-09"}},
+		{"openfinance://accounts/{account_id}/bills", "openfinance://accounts/acc_1/transactions", nil},
+	}
+	for _, tt := range tests {
+		got, ok := matchTemplate(tt.template, tt.uri)
+		if ok != (tt.want != nil) || (ok && !reflect.DeepEqual(got, tt.want)) {
+			t.Errorf("matchTemplate(%q, %q) = %v, %v; want %v", tt.template, tt.uri, got, ok, tt.want)
+		}
+	}
+}
+
+func TestResourceRegistrySetPolicy(t *testing.T) {
+	registry := NewResourceRegistry(
+		&fakeResource{template: "openfinance://accounts/{account_id}", tool: "get_account_details"},
+		&fakeResource{template: "openfinance://accounts/{account_id}/transactions{?month}", tool: "get_account_transactions"},
+	)
+	policy := &ToolPolicy{Profile: "read-only", Deny: []string{"get_account_transactions"}}
+	tools := NewToolRegistry(
+		&fakeTool{name: "get_account_details", access: AccessRead},
+		&fakeTool{name: "get_account_transactions", access: AccessRead},
+	)
+	tools.SetPolicy(policy)
+	registry.SetPolicy(tools.Enabled)
+
+	if !registry.matches("openfinance://accounts/acc_1") {
+		t.Errorf("the account resource should stay enabled")
+	}
+	if registry.matches("openfinance://accounts/acc_1/transactions") {
+		t.Errorf("the transactions resource should follow its denied tool")
+	}
+	list := registry.list(context.Background())
+	if len(list.Resources) != 1 || list.Resources[0].Uri != "openfinance://accounts/acc_1" {
+		t.Errorf("list = %+v, want only the account", list.Resources)
+	}
+}
+
+func TestResourceTransport(t *testing.T) {
+	registry := NewResourceRegistry(
+		&fakeResource{template: "openfinance://accounts/{account_id}", tool: "get_account_details"},
+		&fakeResource{template: "openfinance://accounts/{account_id}/bills", tool: "get_account_bills", read: func(map[string]string) (*mcp.ResourceResponse, error) {
+			return nil, errors.New("upstream failed")
+		}},
+	)
+	base := newFakeTransport()
+	tr := registry.Transport(base)
+
+	var passed []string
+	tr.SetMessageHandler(func(_ context.Context, message *transport.BaseJsonRpcMessage) {
+		passed = append(passed, message.JsonRpcRequest.Method)
+	})
+
+	ctx := context.Background()
+	base.receive(t, ctx, 1, "resources/read", map[string]string{"uri": "openfinance://accounts/acc_1"})
+	if reply := base.reply(t); reply.Type != transport.BaseMessageTypeJSONRPCResponseType || !strings.Contains(string(reply.JsonRpcResponse.Result), `acc_1`) {
+		t.Errorf("read reply = %+v, want the resource", reply)
+	}
+
+	base.receive(t, ctx, 2, "resources/read", map[string]string{"uri": "openfinance://accounts/acc_1/bills"})
+	if reply := base.reply(t); reply.Type != transport.BaseMessageTypeJSONRPCErrorType || reply.JsonRpcError.Error.Message != "upstream failed" {
+		t.Errorf("failed read reply = %+v, want the error", reply)
+	}
+
+	base.receive(t, ctx, 3, "resources/list", struct{}{})
+	if reply := base.reply(t); !strings.Contains(string(reply.JsonRpcResponse.Result), `openfinance://accounts/acc_1`) {
+		t.Errorf("list reply = %s, want the listed account", reply.JsonRpcResponse.Result)
+	}
+
+	base.receive(t, ctx, 4, "resources/read", map[string]string{"uri": "file:///etc/passwd"})
+	base.receive(t, ctx, 5, "tools/list", struct{}{})
+	if strings.Join(passed, ",") != "resources/read,tools/list" {
+		t.Errorf("passed through %v, want the unknown URI and the other methods", passed)
+	}
+}
+
+func TestResourcesAreAudited(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log := audit.Open(audit.Options{Path: path})
+	defer log.Close()
+
+	registry := NewResourceRegistry(&fakeResource{template: "openfinance://accounts/{account_id}", tool: "get_account_details", read: func(map[string]string) (*mcp.ResourceResponse, error) {
+		return mcp.NewResourceResponse(mcp.NewTextEmbeddedResource("openfinance://accounts/acc_1", "{}", "application/json")), nil
+	}})
+	base := newFakeTransport()
+	tr := registry.Transport(AuditTransport(base, log))
+	tr.SetMessageHandler(func(context.Context, *transport.BaseJsonRpcMessage) {})
+
+	ctx := context.WithValue(context.Background(), sessionKey{}, "session-1")
+	base.receive(t, ctx, 1, "resources/read", map[string]string{"uri": "openfinance://accounts/acc_1"})
+	base.reply(t)
+	base.receive(t, ctx, 2, "resources/list", struct{}{})
+	base.reply(t)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("audit log has %d entries, want 2:\n%s", len(lines), data)
+	}
+	var entry audit.Entry
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Tool != "resources/read" || entry.Session != "session-1" || entry.Outcome != audit.OutcomeOK || !strings.Contains(string(entry.Arguments), "openfinance://accounts/acc_1") {
+		t.Errorf("read entry = %+v", entry)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Tool != "resources/list" {
+		t.Errorf("list entry tool = %q, want resources/list", entry.Tool)
+	}
+}
+
+type fakeTool struct {
+	name   string
+	access Access
+	handle ToolHandlerFunc
+}
+
+func (f *fakeTool) Name() string            { return f.name }
+func (f *fakeTool) Description() string     { return f.name }
+func (f *fakeTool) Access() Access          { return f.access }
+func (f *fakeTool) Handle() ToolHandlerFunc { return f.handle }
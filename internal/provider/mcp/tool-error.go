@@ -0,0 +1,117 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/metoro-io/mcp-golang/transport"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/logger"
+)
+
+type ErrorCode string
+
+const (
+	ErrorValidation  ErrorCode = "validation"   // bad arguments, retrying as is will fail again
+	ErrorNotFound    ErrorCode = "not_found"    // the item, account or bill does not exist
+	ErrorAuth        ErrorCode = "auth"         // Pluggy rejected the credentials or the consent
+	ErrorRateLimited ErrorCode = "rate_limited" // too many requests, retry later
+	ErrorTimeout     ErrorCode = "timeout"      // the tool ran out of time, retrying may succeed
+	ErrorUpstream    ErrorCode = "upstream"     // Pluggy or Redis failed
+	ErrorInternal    ErrorCode = "internal"     // a bug on our side
+)
+
+// ToolError is the error contract of every tool: handlers return it as their
+// error and the client gets it as an isError result with this JSON body.
+type ToolError struct {
+	Code      ErrorCode `json:"code"`
+	Message   string    `json:"message"`
+	Retryable bool      `json:"retryable"`
+	RequestID string    `json:"request_id,omitempty"`
+}
+
+// NewToolError scrubs message like a log line, since it often quotes an
+// upstream error.
+func NewToolError(code ErrorCode, message string) *ToolError {
+	return &ToolError{
+		Code:      code,
+		Message:   logger.Scrub(message),
+		Retryable: code == ErrorRateLimited || code == ErrorUpstream || code == ErrorTimeout,
+	}
+}
+
+// Error encodes the error as JSON, since its text is all the MCP server keeps
+// of a handler error.
+func (e *ToolError) Error() string {
+	data, _ := json.Marshal(e)
+	return string(data)
+}
+
+// handlerErrorPrefix is what the MCP server prepends to handler errors.
+const handlerErrorPrefix = "handler returned an error: "
+
+// Transport rewrites error results of tool calls into the ToolError contract,
+// dropping the server's prefix and wrapping errors that did not come from a
+// ToolError, such as arguments that failed to unmarshal. It also lets tools
+// report progress (see Progress).
+func (r *ToolRegistry) Transport(next transport.Transport) transport.Transport {
+	return &toolTransport{next}
+}
+
+type toolTransport struct {
+	transport.Transport
+}
+
+func (t *toolTransport) Send(ctx context.Context, message *transport.BaseJsonRpcMessage) error {
+	if message.Type == transport.BaseMessageTypeJSONRPCResponseType {
+		if result, ok := toolErrorResult(message.JsonRpcResponse.Result); ok {
+			message.JsonRpcResponse.Result = result
+		}
+	}
+	return t.Transport.Send(ctx, message)
+}
+
+type toolResult struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	IsError bool `json:"isError"`
+}
+
+func toolErrorResult(raw json.RawMessage) (json.RawMessage, bool) {
+	if !bytes.Contains(raw, []byte(`"isError":true`)) {
+		return nil, false
+	}
+
+	var result toolResult
+	if err := json.Unmarshal(raw, &result); err != nil || !result.IsError || len(result.Content) != 1 {
+		return nil, false
+	}
+
+	text := strings.TrimPrefix(result.Content[0].Text, handlerErrorPrefix)
+
+	var toolErr ToolError
+	if err := json.Unmarshal([]byte(text), &toolErr); err != nil || toolErr.Code == "" {
+		code := ErrorInternal
+		if strings.HasPrefix(text, "failed to unmarshal arguments") {
+			code = ErrorValidation
+		}
+		toolErr = *NewToolError(code, text)
+	}
+
+	body, err := json.Marshal(map[string]any{"error": toolErr})
+	if err != nil {
+		return nil, false
+	}
+	result.Content[0].Type = "text"
+	result.Content[0].Text = string(body)
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
@@ -0,0 +1,97 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNewToolError(t *testing.T) {
+	tests := []struct {
+		code          ErrorCode
+		wantRetryable bool
+	}{
+		{ErrorValidation, false},
+		{ErrorNotFound, false},
+		{ErrorAuth, false},
+		{ErrorRateLimited, true},
+		{ErrorTimeout, true},
+		{ErrorUpstream, true},
+		{ErrorInternal, false},
+	}
+	for _, tt := range tests {
+		if got := NewToolError(tt.code, "failed"); got.Retryable != tt.wantRetryable {
+			t.Errorf("NewToolError(%s).Retryable = %t, want %t", tt.code, got.Retryable, tt.wantRetryable)
+		}
+	}
+}
+
+func TestToolErrorResult(t *testing.T) {
+	result := func(text string) json.RawMessage {
+		data, _ := json.Marshal(map[string]any{
+			"content": []map[string]string{{"type": "text", "text": text}},
+			"isError": true,
+		})
+		return data
+	}
+	toolErr := NewToolError(ErrorNotFound, "Account not found")
+	toolErr.RequestID = "req-1"
+
+	tests := []struct {
+		name    string
+		raw     json.RawMessage
+		want    *ToolError
+		rewrite bool
+	}{
+		{
+			name:    "tool error",
+			raw:     result(handlerErrorPrefix + toolErr.Error()),
+			want:    toolErr,
+			rewrite: true,
+		},
+		{
+			name:    "arguments that failed to unmarshal",
+			raw:     result(handlerErrorPrefix + "failed to unmarshal arguments: json: cannot unmarshal number"),
+			want:    &ToolError{Code: ErrorValidation, Message: "failed to unmarshal arguments: json: cannot unmarshal number"},
+			rewrite: true,
+		},
+		{
+			name:    "plain error",
+			raw:     result(handlerErrorPrefix + "boom"),
+			want:    &ToolError{Code: ErrorInternal, Message: "boom"},
+			rewrite: true,
+		},
+		{
+			name: "success",
+			raw:  json.RawMessage(`{"content":[{"type":"text","text":"ok"}],"isError":false}`),
+		},
+		{
+			name: "several contents",
+			raw:  json.RawMessage(`{"content":[{"type":"text","text":"a"},{"type":"text","text":"b"}],"isError":true}`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := toolErrorResult(tt.raw)
+			if ok != tt.rewrite {
+				t.Fatalf("rewritten = %t, want %t", ok, tt.rewrite)
+			}
+			if !ok {
+				return
+			}
+
+			var res toolResult
+			if err := json.Unmarshal(got, &res); err != nil {
+				t.Fatal(err)
+			}
+			var body struct {
+				Error ToolError `json:"error"`
+			}
+			if !res.IsError || len(res.Content) != 1 || json.Unmarshal([]byte(res.Content[0].Text), &body) != nil {
+				t.Fatalf("result = %s, want a single isError text content", got)
+			}
+			if body.Error != *tt.want {
+				t.Errorf("error = %+v, want %+v", body.Error, *tt.want)
+			}
+		})
+	}
+}
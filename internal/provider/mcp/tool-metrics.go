@@ -0,0 +1,107 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	mcp "github.com/metoro-io/mcp-golang"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/metrics"
+)
+
+var (
+	toolCallsTotal = metrics.NewCounter("openfinance_tool_calls_total",
+		"Tool calls by tool and outcome (ok or error).", "tool", "outcome")
+	toolErrorsTotal = metrics.NewCounter("openfinance_tool_errors_total",
+		"Failed tool calls by tool and error code.", "tool", "code")
+	toolCallDuration = metrics.NewHistogram("openfinance_tool_call_duration_seconds",
+		"Latency of tool calls by tool.", metrics.DefaultBuckets, "tool")
+)
+
+// ToolStats are the counters Metrics keeps for one tool.
+type ToolStats struct {
+	Tool     string
+	Calls    int64
+	Errors   map[ErrorCode]int64 // failed calls by error code
+	Duration time.Duration       // total time spent in the tool
+	Max      time.Duration       // slowest call
+}
+
+// ToolMetrics counts the calls of every tool, fed by the Metrics middleware.
+type ToolMetrics struct {
+	mu    sync.Mutex
+	tools map[string]*ToolStats
+}
+
+func NewToolMetrics() *ToolMetrics {
+	return &ToolMetrics{tools: map[string]*ToolStats{}}
+}
+
+func (m *ToolMetrics) record(tool string, elapsed time.Duration, err error) {
+	code := ErrorCode("")
+	if err != nil {
+		code = ErrorInternal
+		var toolErr *ToolError
+		if errors.As(err, &toolErr) {
+			code = toolErr.Code
+		}
+	}
+
+	toolCallDuration.ObserveDuration(elapsed, tool)
+	if code == "" {
+		toolCallsTotal.Inc(tool, "ok")
+	} else {
+		toolCallsTotal.Inc(tool, "error")
+		toolErrorsTotal.Inc(tool, string(code))
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats, ok := m.tools[tool]
+	if !ok {
+		stats = &ToolStats{Tool: tool, Errors: map[ErrorCode]int64{}}
+		m.tools[tool] = stats
+	}
+	stats.Calls++
+	stats.Duration += elapsed
+	stats.Max = max(stats.Max, elapsed)
+
+	if code != "" {
+		stats.Errors[code]++
+	}
+}
+
+// Snapshot returns a copy of the counters, sorted by tool name.
+func (m *ToolMetrics) Snapshot() []ToolStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := make([]ToolStats, 0, len(m.tools))
+	for _, stats := range m.tools {
+		copied := *stats
+		copied.Errors = make(map[ErrorCode]int64, len(stats.Errors))
+		for code, n := range stats.Errors {
+			copied.Errors[code] = n
+		}
+		snapshot = append(snapshot, copied)
+	}
+	sort.Slice(snapshot, func(i, j int) bool { return snapshot[i].Tool < snapshot[j].Tool })
+	return snapshot
+}
+
+// Metrics records the calls, errors and durations of every tool in m, and in
+// the metrics exposed on /metrics.
+func Metrics(m *ToolMetrics) ToolMiddleware {
+	return func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, call *ToolCall) (*mcp.ToolResponse, error) {
+			start := time.Now()
+			res, err := next(ctx, call)
+			m.record(call.Tool.Name(), time.Since(start), err)
+			return res, err
+		}
+	}
+}
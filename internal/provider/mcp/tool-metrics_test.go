@@ -0,0 +1,59 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+func TestToolMetrics(t *testing.T) {
+	calls := []struct {
+		tool    string
+		elapsed time.Duration
+		err     error
+	}{
+		{"get_accounts", 10 * time.Millisecond, nil},
+		{"get_accounts", 30 * time.Millisecond, NewToolError(ErrorNotFound, "no such item")},
+		{"get_accounts", 20 * time.Millisecond, errors.New("unexpected")},
+		{"get_account_bills", 5 * time.Millisecond, nil},
+	}
+
+	m := NewToolMetrics()
+	for _, c := range calls {
+		m.record(c.tool, c.elapsed, c.err)
+	}
+
+	want := []ToolStats{
+		{Tool: "get_account_bills", Calls: 1, Errors: map[ErrorCode]int64{}, Duration: 5 * time.Millisecond, Max: 5 * time.Millisecond},
+		{Tool: "get_accounts", Calls: 3, Errors: map[ErrorCode]int64{ErrorNotFound: 1, ErrorInternal: 1}, Duration: 60 * time.Millisecond, Max: 30 * time.Millisecond},
+	}
+	snapshot := m.Snapshot()
+	if !reflect.DeepEqual(snapshot, want) {
+		t.Errorf("Snapshot() = %+v, want %+v", snapshot, want)
+	}
+
+	// The snapshot is a copy.
+	snapshot[1].Errors[ErrorTimeout] = 1
+	if _, ok := m.Snapshot()[1].Errors[ErrorTimeout]; ok {
+		t.Errorf("changing a snapshot changed the metrics")
+	}
+}
+
+func TestMetricsMiddleware(t *testing.T) {
+	m := NewToolMetrics()
+	handler := Metrics(m)(func(context.Context, *ToolCall) (*mcp.ToolResponse, error) {
+		return nil, NewToolError(ErrorUpstream, "Pluggy failed")
+	})
+	if _, err := handler(context.Background(), &ToolCall{Tool: &fakeTool{name: "get_accounts"}}); err == nil {
+		t.Fatal("the handler's error should be returned")
+	}
+
+	stats := m.Snapshot()
+	if len(stats) != 1 || stats[0].Calls != 1 || stats[0].Errors[ErrorUpstream] != 1 {
+		t.Errorf("Snapshot() = %+v, want one upstream error", stats)
+	}
+}
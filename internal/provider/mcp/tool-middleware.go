@@ -0,0 +1,172 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"time"
+
+	mcp "github.com/metoro-io/mcp-golang"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/logger"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/tracing"
+)
+
+// ToolCall is a tool invocation as middlewares see it: the tool and its
+// arguments, already decoded into the handler's argument struct.
+type ToolCall struct {
+	Tool      ToolProvider
+	Arguments any
+}
+
+type ToolHandler func(ctx context.Context, call *ToolCall) (*mcp.ToolResponse, error)
+
+// ToolMiddleware wraps the handler of every tool. Middlewares run in the order
+// they were added, the first one being the outermost.
+type ToolMiddleware func(next ToolHandler) ToolHandler
+
+// Use appends middlewares to the chain wrapping every tool handler.
+func (r *ToolRegistry) Use(middlewares ...ToolMiddleware) {
+	r.middlewares = append(r.middlewares, middlewares...)
+}
+
+var (
+	contextType  = reflect.TypeFor[context.Context]()
+	responseType = reflect.TypeFor[*mcp.ToolResponse]()
+	errorType    = reflect.TypeFor[error]()
+)
+
+// wrap returns a handler with the signature of the provider's, so the MCP
+// server still derives the input schema from its arguments, that runs the
+// middleware chain around it.
+func (r *ToolRegistry) wrap(provider ToolProvider) (ToolHandlerFunc, error) {
+	handler := reflect.ValueOf(provider.Handle())
+	t := handler.Type()
+	if t.Kind() != reflect.Func || t.NumIn() < 1 || t.NumIn() > 2 || t.NumOut() != 2 ||
+		(t.NumIn() == 2 && t.In(0) != contextType) || t.Out(0) != responseType || t.Out(1) != errorType {
+		return nil, fmt.Errorf("tool %s: handler must be func([context.Context,] Args) (*mcp.ToolResponse, error), got %s", provider.Name(), t)
+	}
+	if len(r.middlewares) == 0 {
+		return provider.Handle(), nil
+	}
+
+	next := func(ctx context.Context, call *ToolCall) (*mcp.ToolResponse, error) {
+		in := []reflect.Value{reflect.ValueOf(call.Arguments)}
+		if t.NumIn() == 2 {
+			in = append([]reflect.Value{reflect.ValueOf(ctx)}, in...)
+		}
+		out := handler.Call(in)
+		res, _ := out[0].Interface().(*mcp.ToolResponse)
+		err, _ := out[1].Interface().(error)
+		return res, err
+	}
+
+	for i := len(r.middlewares) - 1; i >= 0; i-- {
+		next = r.middlewares[i](next)
+	}
+
+	return reflect.MakeFunc(t, func(in []reflect.Value) []reflect.Value {
+		ctx, args := context.Background(), in[0]
+		if t.NumIn() == 2 {
+			ctx, args = in[0].Interface().(context.Context), in[1]
+		}
+
+		res, err := next(ctx, &ToolCall{Tool: provider, Arguments: args.Interface()})
+
+		errOut := reflect.Zero(errorType)
+		if err != nil {
+			errOut = reflect.ValueOf(&err).Elem()
+		}
+		return []reflect.Value{reflect.ValueOf(res), errOut}
+	}).Interface(), nil
+}
+
+// Recover turns a panicking handler into an internal error, so one bad tool
+// call does not bring the server down. Add it last, so the middlewares before
+// it see the panic as that error.
+func Recover() ToolMiddleware {
+	return func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, call *ToolCall) (res *mcp.ToolResponse, err error) {
+			defer func() {
+				if p := recover(); p != nil {
+					logger.Error("[mcp] tool panicked", "tool", call.Tool.Name(), "panic", p, "stack", string(debug.Stack()))
+					res, err = nil, NewToolError(ErrorInternal, fmt.Sprintf("The tool %s failed unexpectedly", call.Tool.Name()))
+				}
+			}()
+			return next(ctx, call)
+		}
+	}
+}
+
+// Trace makes every tool call the root span of a trace, the Pluggy requests,
+// Redis commands and rate limiter waits it causes being its children. Add it
+// first, so the span covers the other middlewares.
+func Trace() ToolMiddleware {
+	return func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, call *ToolCall) (*mcp.ToolResponse, error) {
+			ctx, span := tracing.StartRoot(ctx, "tool "+call.Tool.Name(), tracing.KindServer)
+			defer span.End()
+			span.SetAttributes("mcp.tool", call.Tool.Name(), "mcp.session", SessionID(ctx))
+
+			res, err := next(ctx, call)
+			var toolErr *ToolError
+			if errors.As(err, &toolErr) {
+				span.SetAttributes("mcp.error_code", string(toolErr.Code))
+				span.SetError(errors.New(toolErr.Message))
+			} else {
+				span.SetError(err)
+			}
+			return res, err
+		}
+	}
+}
+
+// LogCalls logs every tool call with its duration and outcome.
+func LogCalls() ToolMiddleware {
+	return func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, call *ToolCall) (*mcp.ToolResponse, error) {
+			start := time.Now()
+			res, err := next(ctx, call)
+			if err != nil {
+				logger.Info("[mcp] tool call failed", "tool", call.Tool.Name(), "duration", time.Since(start), "session", SessionID(ctx), "error", err)
+			} else {
+				logger.Info("[mcp] tool call succeeded", "tool", call.Tool.Name(), "duration", time.Since(start), "session", SessionID(ctx))
+			}
+			return res, err
+		}
+	}
+}
+
+// ToolTimeout is implemented by tools that legitimately run for longer than
+// the Timeout middleware allows, such as the ones waiting on Pluggy.
+type ToolTimeout interface {
+	Timeout() time.Duration
+}
+
+// DefaultToolTimeout is how long a tool call may take unless it says otherwise.
+const DefaultToolTimeout = time.Minute
+
+// Timeout cancels the context of tool calls running for longer than d, or than
+// the tool's own timeout, and reports them as timeout errors. Handlers stop
+// when they next use the context, e.g. for a Pluggy request.
+func Timeout(d time.Duration) ToolMiddleware {
+	return func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, call *ToolCall) (*mcp.ToolResponse, error) {
+			limit := d
+			if t, ok := call.Tool.(ToolTimeout); ok {
+				limit = max(limit, t.Timeout())
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, limit)
+			defer cancel()
+
+			res, err := next(ctx, call)
+			if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, NewToolError(ErrorTimeout, fmt.Sprintf("The tool %s did not finish within %s", call.Tool.Name(), limit))
+			}
+			return res, err
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	mcp "github.com/metoro-io/mcp-golang"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pluggy"
+)
+
+type slowTool struct {
+	fakeTool
+	timeout time.Duration
+}
+
+func (s *slowTool) Timeout() time.Duration { return s.timeout }
+
+type pluggyStub struct{}
+
+func (pluggyStub) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"results":[]}`)), Request: req}, nil
+}
+
+// limitedClient returns a Pluggy client that used up its rate limit, so the
+// next listing blocks until the limit resets.
+func limitedClient(t *testing.T) *pluggy.Client {
+	t.Helper()
+	client := pluggy.NewClient(nil, pluggy.Options{CassetteMode: "replay", CassetteDir: t.TempDir()})
+	client.Transport = pluggyStub{}
+	for client.RateLimit().Remaining > 0 {
+		if _, err := client.GetTransactions(context.Background(), "acc-1", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return client
+}
+
+func TestTimeout(t *testing.T) {
+	client := limitedClient(t)
+	failure := errors.New("bad arguments")
+
+	tests := []struct {
+		name     string
+		tool     ToolProvider
+		handler  ToolHandler
+		wantCode ErrorCode // empty when the handler's own outcome is kept
+		wantErr  error
+	}{
+		{
+			name: "finishes in time",
+			tool: &fakeTool{name: "get_accounts"},
+			handler: func(context.Context, *ToolCall) (*mcp.ToolResponse, error) {
+				return mcp.NewToolResponse(), nil
+			},
+		},
+		{
+			name: "fails in time",
+			tool: &fakeTool{name: "get_accounts"},
+			handler: func(context.Context, *ToolCall) (*mcp.ToolResponse, error) {
+				return nil, failure
+			},
+			wantErr: failure,
+		},
+		{
+			name: "blocked on the rate limit",
+			tool: &fakeTool{name: "get_account_transactions"},
+			handler: func(ctx context.Context, _ *ToolCall) (*mcp.ToolResponse, error) {
+				_, err := client.GetTransactions(ctx, "acc-1", nil)
+				return nil, err
+			},
+			wantCode: ErrorTimeout,
+		},
+		{
+			name: "longer tool timeout",
+			tool: &slowTool{fakeTool: fakeTool{name: "wait_item_updated"}, timeout: time.Second},
+			handler: func(ctx context.Context, _ *ToolCall) (*mcp.ToolResponse, error) {
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-time.After(100 * time.Millisecond):
+					return mcp.NewToolResponse(), nil
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			res, err := Timeout(20*time.Millisecond)(tt.handler)(context.Background(), &ToolCall{Tool: tt.tool})
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Fatalf("the call took %s", elapsed)
+			}
+
+			var toolErr *ToolError
+			switch {
+			case tt.wantCode != "":
+				if !errors.As(err, &toolErr) || toolErr.Code != tt.wantCode {
+					t.Errorf("error = %v, want a %s tool error", err, tt.wantCode)
+				}
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+			case err != nil || res == nil:
+				t.Errorf("= %v, %v; want the response", res, err)
+			}
+		})
+	}
+}
+
+func TestRecover(t *testing.T) {
+	handler := Recover()(func(context.Context, *ToolCall) (*mcp.ToolResponse, error) {
+		panic("boom")
+	})
+	_, err := handler(context.Background(), &ToolCall{Tool: &fakeTool{name: "get_accounts"}})
+	var toolErr *ToolError
+	if !errors.As(err, &toolErr) || toolErr.Code != ErrorInternal {
+		t.Errorf("error = %v, want an internal tool error", err)
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	var order []string
+	mark := func(name string) ToolMiddleware {
+		return func(next ToolHandler) ToolHandler {
+			return func(ctx context.Context, call *ToolCall) (*mcp.ToolResponse, error) {
+				order = append(order, name)
+				return next(ctx, call)
+			}
+		}
+	}
+
+	tool := &fakeTool{name: "get_accounts", handle: func(ctx context.Context, args struct{ ID string }) (*mcp.ToolResponse, error) {
+		order = append(order, "handler "+args.ID)
+		return mcp.NewToolResponse(), nil
+	}}
+	registry := NewToolRegistry(tool)
+	registry.Use(mark("first"), mark("second"))
+
+	wrapped, err := registry.wrap(tool)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := wrapped.(func(context.Context, struct{ ID string }) (*mcp.ToolResponse, error))
+	if _, err := handler(context.Background(), struct{ ID string }{"acc_1"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(order, ","); got != "first,second,handler acc_1" {
+		t.Errorf("order = %s", got)
+	}
+
+	bad := &fakeTool{name: "bad", handle: func(string) error { return nil }}
+	if _, err := registry.wrap(bad); err == nil {
+		t.Errorf("wrap should reject a handler with the wrong signature")
+	}
+}
@@ -0,0 +1,49 @@
+package mcp
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Access is what a tool can do with the user's data and credentials.
+type Access string
+
+const (
+	AccessRead      Access = "read"      // reads financial data
+	AccessWrite     Access = "write"     // creates or changes connections and data
+	AccessSensitive Access = "sensitive" // hands credentials to the model
+)
+
+const DefaultToolProfile = "read-only"
+
+var toolProfiles = map[string][]Access{
+	"read-only": {AccessRead},
+	"payments":  {AccessRead, AccessWrite},
+	"admin":     {AccessRead, AccessWrite, AccessSensitive},
+}
+
+// ToolPolicy decides which tools are registered: the ones whose access level
+// the profile grants plus the allowed ones, minus the denied ones. Allowing a
+// tool by name is how a single write or sensitive tool is enabled.
+type ToolPolicy struct {
+	Profile string
+	Allow   []string
+	Deny    []string
+}
+
+func NewToolPolicy(profile string, allow, deny []string) (*ToolPolicy, error) {
+	if profile == "" {
+		profile = DefaultToolProfile
+	}
+	if _, ok := toolProfiles[profile]; !ok {
+		return nil, fmt.Errorf("unknown tool profile %q, expected read-only, payments or admin", profile)
+	}
+	return &ToolPolicy{Profile: profile, Allow: allow, Deny: deny}, nil
+}
+
+func (p *ToolPolicy) Allows(tool ToolProvider) bool {
+	if slices.Contains(p.Deny, tool.Name()) {
+		return false
+	}
+	return slices.Contains(toolProfiles[p.Profile], tool.Access()) || slices.Contains(p.Allow, tool.Name())
+}
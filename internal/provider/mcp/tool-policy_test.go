@@ -0,0 +1,71 @@
+package mcp
+
+import "testing"
+
+func TestNewToolPolicy(t *testing.T) {
+	tests := []struct {
+		profile     string
+		wantProfile string
+		wantErr     bool
+	}{
+		{"", DefaultToolProfile, false},
+		{"read-only", "read-only", false},
+		{"payments", "payments", false},
+		{"admin", "admin", false},
+		{"root", "", true},
+	}
+	for _, tt := range tests {
+		policy, err := NewToolPolicy(tt.profile, nil, nil)
+		if (err != nil) != tt.wantErr || (err == nil && policy.Profile != tt.wantProfile) {
+			t.Errorf("NewToolPolicy(%q) = %+v, %v; want profile %q, error %t", tt.profile, policy, err, tt.wantProfile, tt.wantErr)
+		}
+	}
+}
+
+func TestToolPolicyAllows(t *testing.T) {
+	read := &fakeTool{name: "get_accounts", access: AccessRead}
+	write := &fakeTool{name: "pluggy_connect_url", access: AccessWrite}
+	sensitive := &fakeTool{name: "get_api_key", access: AccessSensitive}
+
+	tests := []struct {
+		name   string
+		policy ToolPolicy
+		want   map[*fakeTool]bool
+	}{
+		{"read-only", ToolPolicy{Profile: "read-only"}, map[*fakeTool]bool{read: true, write: false, sensitive: false}},
+		{"payments", ToolPolicy{Profile: "payments"}, map[*fakeTool]bool{read: true, write: true, sensitive: false}},
+		{"admin", ToolPolicy{Profile: "admin"}, map[*fakeTool]bool{read: true, write: true, sensitive: true}},
+		{"allow one tool", ToolPolicy{Profile: "read-only", Allow: []string{"pluggy_connect_url"}}, map[*fakeTool]bool{read: true, write: true, sensitive: false}},
+		{"deny one tool", ToolPolicy{Profile: "admin", Deny: []string{"get_api_key"}}, map[*fakeTool]bool{read: true, write: true, sensitive: false}},
+		{"deny wins over allow", ToolPolicy{Profile: "read-only", Allow: []string{"get_api_key"}, Deny: []string{"get_api_key", "get_accounts"}}, map[*fakeTool]bool{read: false, write: false, sensitive: false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for tool, want := range tt.want {
+				if got := tt.policy.Allows(tool); got != want {
+					t.Errorf("Allows(%s) = %t, want %t", tool.name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestToolRegistryEnabled(t *testing.T) {
+	registry := NewToolRegistry(
+		&fakeTool{name: "get_accounts", access: AccessRead},
+		&fakeTool{name: "pluggy_connect_url", access: AccessWrite},
+	)
+
+	// Without a policy only read tools are enabled.
+	if !registry.Enabled("get_accounts") || registry.Enabled("pluggy_connect_url") {
+		t.Errorf("default policy should only enable read tools")
+	}
+	if registry.Enabled("unknown") {
+		t.Errorf("unknown tools should never be enabled")
+	}
+
+	registry.SetPolicy(&ToolPolicy{Profile: "payments"})
+	if !registry.Enabled("pluggy_connect_url") {
+		t.Errorf("the payments profile should enable write tools")
+	}
+}
@@ -1,7 +1,11 @@
 package mcp
 
 import (
+	"slices"
+
 	mcp "github.com/metoro-io/mcp-golang"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/logger"
 )
 
 type ToolHandlerFunc interface{}
@@ -9,23 +13,67 @@ type ToolHandlerFunc interface{}
 type ToolProvider interface {
 	Name() string
 	Description() string
+	Access() Access
 	Handle() ToolHandlerFunc
 }
 
 type ToolRegistry struct {
-	handlers []ToolProvider
+	handlers    []ToolProvider
+	policy      *ToolPolicy
+	middlewares []ToolMiddleware
 }
 
 func NewToolRegistry(handlers ...ToolProvider) *ToolRegistry {
-	return &ToolRegistry{handlers}
+	return &ToolRegistry{handlers: handlers}
+}
+
+func (r *ToolRegistry) Add(handlers ...ToolProvider) {
+	r.handlers = append(r.handlers, handlers...)
+}
+
+// SetPolicy sets which tools Register registers. Without one, only read tools
+// are registered.
+func (r *ToolRegistry) SetPolicy(policy *ToolPolicy) {
+	r.policy = policy
+}
+
+func (r *ToolRegistry) currentPolicy() *ToolPolicy {
+	if r.policy == nil {
+		return &ToolPolicy{Profile: DefaultToolProfile}
+	}
+	return r.policy
+}
+
+// Enabled reports whether Register registers the tool called name.
+func (r *ToolRegistry) Enabled(name string) bool {
+	policy := r.currentPolicy()
+	return slices.ContainsFunc(r.handlers, func(p ToolProvider) bool { return p.Name() == name && policy.Allows(p) })
 }
 
 func (r *ToolRegistry) Register(s *mcp.Server) error {
+	policy := r.currentPolicy()
+
+	for _, name := range append(slices.Clone(policy.Allow), policy.Deny...) {
+		if !slices.ContainsFunc(r.handlers, func(p ToolProvider) bool { return p.Name() == name }) {
+			logger.Warnf("[mcp] unknown tool %q in the tool allow/deny lists", name)
+		}
+	}
+
 	for _, provider := range r.handlers {
-		err := s.RegisterTool(
+		if !policy.Allows(provider) {
+			logger.Infof("[mcp] tool %s (%s) disabled by the tool policy (profile: %s)", provider.Name(), provider.Access(), policy.Profile)
+			continue
+		}
+
+		handler, err := r.wrap(provider)
+		if err != nil {
+			return err
+		}
+
+		err = s.RegisterTool(
 			provider.Name(),
 			provider.Description(),
-			provider.Handle(),
+			handler,
 		)
 		if err != nil {
 			return err
@@ -0,0 +1,284 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// collector is a metric family written in the Prometheus text format.
+type collector interface {
+	name() string
+	write(w io.Writer)
+}
+
+var (
+	mu         sync.Mutex
+	collectors []collector
+)
+
+func register(c collector) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, existing := range collectors {
+		if existing.name() == c.name() {
+			panic("metrics: " + c.name() + " registered twice")
+		}
+	}
+	collectors = append(collectors, c)
+}
+
+// Write writes every registered metric in the Prometheus text format,
+// sorted by name.
+func Write(w io.Writer) {
+	mu.Lock()
+	sorted := slices.Clone(collectors)
+	mu.Unlock()
+
+	slices.SortFunc(sorted, func(a, b collector) int { return strings.Compare(a.name(), b.name()) })
+	for _, c := range sorted {
+		c.write(w)
+	}
+}
+
+// family holds the series of a metric, one per combination of label values.
+type family[S any] struct {
+	metric string
+	help   string
+	kind   string
+	labels []string
+
+	mu     sync.Mutex
+	series map[string]*S
+	values map[string][]string
+	newS   func() *S
+}
+
+func newFamily[S any](name, help, kind string, labels []string, newS func() *S) *family[S] {
+	return &family[S]{
+		metric: name,
+		help:   help,
+		kind:   kind,
+		labels: labels,
+		series: map[string]*S{},
+		values: map[string][]string{},
+		newS:   newS,
+	}
+}
+
+func (f *family[S]) name() string { return f.metric }
+
+func (f *family[S]) with(values []string) *S {
+	if len(values) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s takes %d label values, got %d", f.metric, len(f.labels), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, ok := f.series[key]
+	if !ok {
+		s = f.newS()
+		f.series[key] = s
+		f.values[key] = slices.Clone(values)
+	}
+	return s
+}
+
+// each calls fn with the label pairs of every series, in a stable order.
+func (f *family[S]) each(fn func(labels string, s *S)) {
+	f.mu.Lock()
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	f.mu.Unlock()
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		f.mu.Lock()
+		s, values := f.series[key], f.values[key]
+		f.mu.Unlock()
+		fn(labelPairs(f.labels, values), s)
+	}
+}
+
+func (f *family[S]) header(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", f.metric, helpEscaper.Replace(f.help), f.metric, f.kind)
+}
+
+// The text format escapes only backslashes and line feeds in help text, and
+// double quotes as well in label values.
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func labelPairs(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + `="` + labelEscaper.Replace(values[i]) + `"`
+	}
+	return strings.Join(pairs, ",")
+}
+
+func braces(labels string) string {
+	if labels == "" {
+		return ""
+	}
+	return "{" + labels + "}"
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+type value struct {
+	mu sync.Mutex
+	v  float64
+}
+
+func (v *value) add(delta float64) {
+	v.mu.Lock()
+	v.v += delta
+	v.mu.Unlock()
+}
+
+func (v *value) set(x float64) {
+	v.mu.Lock()
+	v.v = x
+	v.mu.Unlock()
+}
+
+func (v *value) get() float64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.v
+}
+
+// Counter is a value that only goes up, per combination of labels.
+type Counter struct {
+	*family[value]
+}
+
+func NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{newFamily(name, help, "counter", labels, func() *value { return &value{} })}
+	register(c)
+	return c
+}
+
+func (c *Counter) Inc(labels ...string) {
+	c.with(labels).add(1)
+}
+
+func (c *Counter) Add(delta float64, labels ...string) {
+	c.with(labels).add(delta)
+}
+
+func (c *Counter) write(w io.Writer) {
+	c.header(w)
+	c.each(func(labels string, v *value) {
+		fmt.Fprintf(w, "%s%s %s\n", c.metric, braces(labels), formatFloat(v.get()))
+	})
+}
+
+// Gauge is a value that goes up and down, per combination of labels.
+type Gauge struct {
+	*family[value]
+}
+
+func NewGauge(name, help string, labels ...string) *Gauge {
+	g := &Gauge{newFamily(name, help, "gauge", labels, func() *value { return &value{} })}
+	register(g)
+	return g
+}
+
+func (g *Gauge) Set(v float64, labels ...string) {
+	g.with(labels).set(v)
+}
+
+func (g *Gauge) write(w io.Writer) {
+	g.header(w)
+	g.each(func(labels string, v *value) {
+		fmt.Fprintf(w, "%s%s %s\n", g.metric, braces(labels), formatFloat(v.get()))
+	})
+}
+
+// DefaultBuckets suit latencies from a few milliseconds to a minute, in
+// seconds.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+type distribution struct {
+	mu     sync.Mutex
+	counts []uint64 // per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+// Histogram counts observations, such as latencies, in buckets, per
+// combination of labels.
+type Histogram struct {
+	*family[distribution]
+	buckets []float64
+}
+
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	h := &Histogram{buckets: buckets}
+	h.family = newFamily(name, help, "histogram", labels, func() *distribution {
+		return &distribution{counts: make([]uint64, len(buckets))}
+	})
+	register(h)
+	return h
+}
+
+func (h *Histogram) Observe(v float64, labels ...string) {
+	d := h.with(labels)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if i, _ := slices.BinarySearch(h.buckets, v); i < len(h.buckets) {
+		d.counts[i]++
+	}
+	d.count++
+	d.sum += v
+}
+
+// ObserveDuration observes d in seconds.
+func (h *Histogram) ObserveDuration(d time.Duration, labels ...string) {
+	h.Observe(d.Seconds(), labels...)
+}
+
+func (h *Histogram) write(w io.Writer) {
+	h.header(w)
+	h.each(func(labels string, d *distribution) {
+		d.mu.Lock()
+		counts, count, sum := slices.Clone(d.counts), d.count, d.sum
+		d.mu.Unlock()
+
+		sep := ""
+		if labels != "" {
+			sep = ","
+		}
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += counts[i]
+			fmt.Fprintf(w, "%s_bucket{%s%sle=\"%s\"} %d\n", h.metric, labels, sep, formatFloat(bound), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket{%s%sle=\"+Inf\"} %d\n", h.metric, labels, sep, count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.metric, braces(labels), formatFloat(sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.metric, braces(labels), count)
+	})
+}
@@ -0,0 +1,141 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		name    string
+		collect func() collector
+		want    string
+	}{
+		{
+			name: "counter without labels",
+			collect: func() collector {
+				c := NewCounter("test_plain_total", "Plain counter.")
+				c.Add(2.5)
+				c.Inc()
+				return c
+			},
+			want: `# HELP test_plain_total Plain counter.
+# TYPE test_plain_total counter
+test_plain_total 3.5
+`,
+		},
+		{
+			name: "counter label values are escaped",
+			collect: func() collector {
+				c := NewCounter("test_escaped_total", "Counts\nwith a \\ in help \"quoted\".", "path")
+				c.Inc(`C:\tmp`)
+				c.Inc("say \"hi\"\nbye")
+				c.Inc("olá\t✓")
+				return c
+			},
+			want: `# HELP test_escaped_total Counts\nwith a \\ in help "quoted".
+# TYPE test_escaped_total counter
+test_escaped_total{path="C:\\tmp"} 1
+test_escaped_total{path="olá	✓"} 1
+test_escaped_total{path="say \"hi\"\nbye"} 1
+`,
+		},
+		{
+			name: "gauge",
+			collect: func() collector {
+				g := NewGauge("test_gauge", "A gauge.", "a", "b")
+				g.Set(10, "x", "y")
+				g.Set(-1, "x", "z")
+				g.Set(7, "x", "y")
+				return g
+			},
+			want: `# HELP test_gauge A gauge.
+# TYPE test_gauge gauge
+test_gauge{a="x",b="y"} 7
+test_gauge{a="x",b="z"} -1
+`,
+		},
+		{
+			name: "histogram",
+			collect: func() collector {
+				h := NewHistogram("test_seconds", "A histogram.", []float64{0.1, 1}, "tool")
+				h.Observe(0.05, "get_accounts")
+				h.Observe(0.1, "get_accounts")
+				h.Observe(0.5, "get_accounts")
+				h.Observe(3, "get_accounts")
+				return h
+			},
+			want: `# HELP test_seconds A histogram.
+# TYPE test_seconds histogram
+test_seconds_bucket{tool="get_accounts",le="0.1"} 2
+test_seconds_bucket{tool="get_accounts",le="1"} 3
+test_seconds_bucket{tool="get_accounts",le="+Inf"} 4
+test_seconds_sum{tool="get_accounts"} 3.65
+test_seconds_count{tool="get_accounts"} 4
+`,
+		},
+		{
+			name: "histogram without labels",
+			collect: func() collector {
+				h := NewHistogram("test_plain_seconds", "Plain histogram.", []float64{1})
+				h.Observe(2)
+				return h
+			},
+			want: `# HELP test_plain_seconds Plain histogram.
+# TYPE test_plain_seconds histogram
+test_plain_seconds_bucket{le="1"} 0
+test_plain_seconds_bucket{le="+Inf"} 1
+test_plain_seconds_sum 2
+test_plain_seconds_count 1
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			tt.collect().write(&out)
+			if out.String() != tt.want {
+				t.Errorf("output:\n%s\nwant:\n%s", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterTwice(t *testing.T) {
+	NewGauge("test_registered_twice", "Once.")
+	defer func() {
+		if recover() == nil {
+			t.Errorf("registering a name twice should panic")
+		}
+	}()
+	NewCounter("test_registered_twice", "Twice.")
+}
+
+func TestWrongLabelCount(t *testing.T) {
+	c := NewCounter("test_label_count_total", "Labels.", "tool")
+	defer func() {
+		if recover() == nil {
+			t.Errorf("a wrong number of label values should panic")
+		}
+	}()
+	c.Inc("a", "b")
+}
+
+func TestHandler(t *testing.T) {
+	NewGauge("test_zz_last", "Sorted last.").Set(1)
+	NewGauge("test_aa_first", "Sorted first.").Set(1)
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if got := rec.Header().Get("Content-Type"); got != "text/plain; version=0.0.4; charset=utf-8" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	body := rec.Body.String()
+	first, last := strings.Index(body, "test_aa_first 1\n"), strings.Index(body, "test_zz_last 1\n")
+	if first < 0 || last < 0 || first > last {
+		t.Errorf("body should list every metric sorted by name:\n%s", body)
+	}
+}
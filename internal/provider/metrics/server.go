@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/logger"
+)
+
+// A scrape only writes what is already in memory, so it needs little time.
+const (
+	readTimeout  = 10 * time.Second
+	writeTimeout = 30 * time.Second
+	idleTimeout  = 2 * time.Minute
+)
+
+// Handler serves the registered metrics to a Prometheus scraper.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		Write(w)
+	})
+}
+
+// Serve binds addr and serves /metrics in the background.
+func Serve(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("metrics: error listening on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", Handler())
+
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       readTimeout,
+		WriteTimeout:      writeTimeout,
+		IdleTimeout:       idleTimeout,
+	}
+
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Errorf("[metrics] server stopped: %v", err)
+		}
+	}()
+
+	logger.Infof("[metrics] serving http://%s/metrics", listener.Addr())
+	return nil
+}
@@ -1,20 +1,20 @@
 package pluggy
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
-
-	"github.com/thunderjr/openfinance-mcp-server/internal/provider/logger"
 )
 
-func (c *Client) GetAccounts(itemID string) (*paginatedResponse[account], error) {
-	req, err := http.NewRequest("GET", "https://api.pluggy.ai/accounts?itemId="+itemID, nil)
+func (c *Client) GetAccounts(ctx context.Context, itemID string) (*PaginatedResponse[Account], error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.pluggy.ai/accounts?itemId="+itemID, nil)
 	if err != nil {
 		return nil, fmt.Errorf("pluggyClient.GetAccounts: error creating request: %w", err)
 	}
-	req.Header.Set("X-API-KEY", c.apiKey)
+	if err := c.authorize(req); err != nil {
+		return nil, err
+	}
 
 	res, err := c.Do(req)
 	if err != nil {
@@ -23,16 +23,10 @@ func (c *Client) GetAccounts(itemID string) (*paginatedResponse[account], error)
 	defer res.Body.Close()
 
 	if res.StatusCode != http.StatusOK {
-		bodyBytes, err := io.ReadAll(res.Body)
-		if err != nil {
-			return nil, fmt.Errorf("pluggyClient.GetAccounts: error reading response body: %w", err)
-		}
-		logger.Debug("body: \n", string(bodyBytes))
-
-		return nil, fmt.Errorf("pluggyClient.GetAccounts: request failed with status %d", res.StatusCode)
+		return nil, newAPIError("pluggyClient.GetAccounts", res)
 	}
 
-	var data paginatedResponse[account]
+	var data PaginatedResponse[Account]
 	if err := json.NewDecoder(res.Body).Decode(&data); err != nil {
 		return nil, fmt.Errorf("pluggyClient.GetAccounts: error decoding response: %w", err)
 	}
@@ -40,16 +34,18 @@ func (c *Client) GetAccounts(itemID string) (*paginatedResponse[account], error)
 	return &data, nil
 }
 
-func (c *Client) GetAccount(accountID string) (*account, error) {
+func (c *Client) GetAccount(ctx context.Context, accountID string) (*Account, error) {
 	if accountID == "" {
 		return nil, fmt.Errorf("pluggyClient.GetAccount: accountID is required")
 	}
 
-	req, err := http.NewRequest("GET", fmt.Sprintf("https://api.pluggy.ai/accounts/%s", accountID), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("https://api.pluggy.ai/accounts/%s", accountID), nil)
 	if err != nil {
 		return nil, fmt.Errorf("pluggyClient.GetAccount: error creating request: %w", err)
 	}
-	req.Header.Set("X-API-KEY", c.apiKey)
+	if err := c.authorize(req); err != nil {
+		return nil, err
+	}
 
 	res, err := c.Do(req)
 	if err != nil {
@@ -58,16 +54,10 @@ func (c *Client) GetAccount(accountID string) (*account, error) {
 	defer res.Body.Close()
 
 	if res.StatusCode != http.StatusOK {
-		bodyBytes, err := io.ReadAll(res.Body)
-		if err != nil {
-			return nil, fmt.Errorf("pluggyClient.GetAccount: error reading response body: %w", err)
-		}
-		logger.Debug("body: \n", string(bodyBytes))
-
-		return nil, fmt.Errorf("pluggyClient.GetAccount: error getting account: request failed with status %d", res.StatusCode)
+		return nil, newAPIError("pluggyClient.GetAccount", res)
 	}
 
-	var data account
+	var data Account
 	if err := json.NewDecoder(res.Body).Decode(&data); err != nil {
 		return nil, fmt.Errorf("pluggyClient.GetAccount: error decoding response: %w", err)
 	}
@@ -2,21 +2,22 @@ package pluggy
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 )
 
-func (c *Client) ApiKey() (string, error) {
+func (c *Client) ApiKey(ctx context.Context) (string, error) {
 	data, err := json.Marshal(map[string]string{
-		"clientId":     PLUGGY_CLIENT_ID,
-		"clientSecret": PLUGGY_CLIENT_SECRET,
+		"clientId":     c.opts.ClientID,
+		"clientSecret": c.opts.ClientSecret,
 	})
 	if err != nil {
 		return "", fmt.Errorf("[pluggy.ApiKey] error marshalling data: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", "https://api.pluggy.ai/auth", bytes.NewBuffer(data))
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.pluggy.ai/auth", bytes.NewBuffer(data))
 	if err != nil {
 		return "", fmt.Errorf("[pluggy.ApiKey] error creating request: %w", err)
 	}
@@ -31,7 +32,7 @@ func (c *Client) ApiKey() (string, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("[pluggy.ApiKey] unexpected status code: %d", resp.StatusCode)
+		return "", newAPIError("[pluggy.ApiKey]", resp)
 	}
 
 	var body struct {
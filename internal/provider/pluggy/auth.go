@@ -2,50 +2,168 @@ package pluggy
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/redis/go-redis/v9"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/encryption"
 )
 
 var (
-	AUTH_CACHE_API_KEY           = "pluggy:api_key"
-	AUTH_CACHE_CONNECT_TOKEN_KEY = "pluggy:connect_tokens"
+	AUTH_CACHE_PREFIX               = "pluggy:"
+	AUTH_CACHE_API_KEY              = "pluggy:api_key"
+	AUTH_CACHE_CONNECT_TOKEN_PREFIX = "pluggy:connect_token:"
 
-	PLUGGY_CLIENT_ID     = os.Getenv("PLUGGY_CLIENT_ID")
-	PLUGGY_CLIENT_SECRET = os.Getenv("PLUGGY_CLIENT_SECRET")
+	// Connect tokens used to live in a single hash with no expiry.
+	AUTH_CACHE_LEGACY_CONNECT_TOKENS_KEY = "pluggy:connect_tokens"
 )
 
+// auth persists credentials in Redis. Values are sealed with the keyring when
+// one is configured; a nil keyring stores them as plaintext.
 type auth struct {
-	cache *redis.Client
+	cache   *redis.Client
+	keyring *encryption.Keyring
+}
+
+func NewAuth(cache *redis.Client, keyring *encryption.Keyring) *auth {
+	return &auth{cache, keyring}
+}
+
+func (a *auth) get(ctx context.Context, key string) (string, error) {
+	res, err := a.cache.Get(ctx, key).Result()
+	if cache := cacheName(key); cache != "" && (err == nil || errors.Is(err, redis.Nil)) {
+		result := "hit"
+		if err != nil {
+			result = "miss"
+		}
+		cacheLookups.Inc(cache, result)
+	}
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			redisErrors.Inc("get")
+		}
+		return "", err
+	}
+	return a.keyring.Decrypt(res)
+}
+
+func (a *auth) set(ctx context.Context, key, value string, ttl time.Duration) error {
+	sealed, err := a.keyring.Encrypt(value)
+	if err != nil {
+		return err
+	}
+	if err := a.cache.Set(ctx, key, sealed, ttl).Err(); err != nil {
+		redisErrors.Inc("set")
+		return err
+	}
+	return nil
+}
+
+// getApiKey returns the cached API key and how long it has left, or an empty
+// key when none is cached.
+func (a *auth) getApiKey(ctx context.Context) (string, time.Duration, error) {
+	res, err := a.get(ctx, AUTH_CACHE_API_KEY)
+	if errors.Is(err, redis.Nil) {
+		return "", 0, nil
+	}
+	if err != nil {
+		return "", 0, fmt.Errorf("error getting cached Pluggy.ai api key: %w", err)
+	}
+
+	ttl, err := a.cache.TTL(ctx, AUTH_CACHE_API_KEY).Result()
+	if err != nil {
+		redisErrors.Inc("ttl")
+		return "", 0, fmt.Errorf("error getting cached Pluggy.ai api key expiry: %w", err)
+	}
+	return res, ttl, nil
 }
 
-func NewAuth(cache *redis.Client) *auth {
-	return &auth{cache}
+func (a *auth) setApiKey(ctx context.Context, k string) error {
+	return a.set(ctx, AUTH_CACHE_API_KEY, k, apiKeyLifetime)
 }
 
-func (a *auth) getApiKey() (string, error) {
-	res, err := a.cache.Get(context.TODO(), AUTH_CACHE_API_KEY).Result()
-	if err != nil && !errors.Is(err, redis.Nil) {
-		return "", fmt.Errorf("error getting cached Pluggy.ai api key: %w", err)
+func (a *auth) getConnectToken(ctx context.Context, key string) (*ConnectToken, error) {
+	res, err := a.get(ctx, AUTH_CACHE_CONNECT_TOKEN_PREFIX+key)
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting cached Pluggy.ai connect token: %w", err)
 	}
-	return res, nil
+
+	var token ConnectToken
+	if err := json.Unmarshal([]byte(res), &token); err != nil {
+		return nil, fmt.Errorf("error decoding cached Pluggy.ai connect token: %w", err)
+	}
+	return &token, nil
 }
 
-func (a *auth) setApiKey(k string) error {
-	return a.cache.Set(context.TODO(), AUTH_CACHE_API_KEY, k, time.Hour*2).Err()
+func (a *auth) setConnectToken(ctx context.Context, key string, token *ConnectToken) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	return a.set(ctx, AUTH_CACHE_CONNECT_TOKEN_PREFIX+key, string(data), time.Until(token.ExpiresAt))
 }
 
-func (a *auth) getConnectToken(itemID string) (string, error) {
-	res, err := a.cache.HGet(context.TODO(), AUTH_CACHE_CONNECT_TOKEN_KEY, itemID).Result()
-	if err != nil && !errors.Is(err, redis.Nil) {
-		return "", fmt.Errorf("error getting cached Pluggy.ai connect token: %w", err)
+func (a *auth) dropLegacyConnectTokens(ctx context.Context) error {
+	if err := a.cache.Del(ctx, AUTH_CACHE_LEGACY_CONNECT_TOKENS_KEY).Err(); err != nil {
+		redisErrors.Inc("delete")
+		return err
 	}
-	return res, nil
+	return nil
 }
 
-func (a *auth) setConnectToken(itemID, token string) error {
-	return a.cache.HSet(context.TODO(), AUTH_CACHE_CONNECT_TOKEN_KEY, itemID, token).Err()
+// Reencrypt seals every cached string under the primary key, covering both
+// plaintext values written before encryption was enabled and values sealed
+// with a rotated-out key. It returns how many entries were rewritten.
+func (a *auth) Reencrypt(ctx context.Context) (int, error) {
+	if a.keyring == nil {
+		return 0, errors.New("no encryption key configured")
+	}
+
+	migrated := 0
+	iter := a.cache.Scan(ctx, 0, AUTH_CACHE_PREFIX+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+
+		// Non-string entries, like the legacy connect token hash, are left
+		// for their owners to clean up.
+		kind, err := a.cache.Type(ctx, key).Result()
+		if err != nil {
+			return migrated, fmt.Errorf("error reading the type of %s: %w", key, err)
+		}
+		if kind != "string" {
+			continue
+		}
+
+		res, err := a.cache.Get(ctx, key).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return migrated, fmt.Errorf("error reading %s: %w", key, err)
+		}
+		if !a.keyring.NeedsMigration(res) {
+			continue
+		}
+
+		plaintext, err := a.keyring.Decrypt(res)
+		if err != nil {
+			return migrated, fmt.Errorf("error decrypting %s: %w", key, err)
+		}
+		sealed, err := a.keyring.Encrypt(plaintext)
+		if err != nil {
+			return migrated, fmt.Errorf("error encrypting %s: %w", key, err)
+		}
+		if err := a.cache.SetArgs(ctx, key, sealed, redis.SetArgs{KeepTTL: true, Mode: "XX"}).Err(); err != nil && !errors.Is(err, redis.Nil) {
+			return migrated, fmt.Errorf("error writing %s: %w", key, err)
+		}
+		migrated++
+	}
+
+	return migrated, iter.Err()
 }
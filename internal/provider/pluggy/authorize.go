@@ -0,0 +1,253 @@
+package pluggy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/logger"
+)
+
+// ErrNotAuthorized is returned by requests made while the client has no valid
+// API key, wrapping the reason of the last failed attempt.
+var ErrNotAuthorized = errors.New("pluggy: not authorized")
+
+const (
+	authorizeFirstRetry = 5 * time.Second
+	authorizeMaxRetry   = 5 * time.Minute
+
+	// Pluggy API keys last two hours. They are renewed a bit earlier, so
+	// requests in flight do not carry an expired one.
+	apiKeyLifetime = 2 * time.Hour
+	apiKeyRenewal  = 10 * time.Minute
+)
+
+// authState is the API key of a client and what renews it.
+type authState struct {
+	mu        sync.RWMutex
+	apiKey    string
+	expiresAt time.Time // zero for keys that do not expire, as in replay
+	err       error     // why there is no API key
+	renewErr  error     // why the last renewal failed while the key still serves
+
+	renewing sync.Mutex    // one renewal at a time
+	lost     chan struct{} // signalled when the key is dropped
+}
+
+func newAuthState() *authState {
+	return &authState{
+		err:  errors.New("authorization not attempted yet"),
+		lost: make(chan struct{}, 1),
+	}
+}
+
+func (s *authState) set(apiKey string, expiresAt time.Time, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.apiKey, s.expiresAt, s.err, s.renewErr = apiKey, expiresAt, err, nil
+}
+
+// keep records why renewing the API key failed, which leaves the key in use
+// until it expires.
+func (s *authState) keep(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.renewErr = err
+}
+
+func (s *authState) key() (string, time.Time) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.apiKey, s.expiresAt
+}
+
+// drop forgets apiKey, unless it was replaced already, and wakes up the
+// background renewal.
+func (s *authState) drop(apiKey string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.apiKey != apiKey {
+		return
+	}
+	s.apiKey, s.expiresAt, s.err, s.renewErr = "", time.Time{}, err, nil
+
+	select {
+	case s.lost <- struct{}{}:
+	default:
+	}
+}
+
+// Ready reports whether the client has a valid API key, or else why not.
+func (c *Client) Ready() error {
+	c.authState.mu.RLock()
+	defer c.authState.mu.RUnlock()
+	switch {
+	case c.authState.apiKey == "":
+		return fmt.Errorf("%w: %v", ErrNotAuthorized, c.authState.err)
+	case expired(c.authState.expiresAt):
+		return fmt.Errorf("%w: the API key expired", ErrNotAuthorized)
+	}
+	return nil
+}
+
+// RenewalError is why the last attempt to renew a still valid API key
+// failed, nil when it did not.
+func (c *Client) RenewalError() error {
+	c.authState.mu.RLock()
+	defer c.authState.mu.RUnlock()
+	return c.authState.renewErr
+}
+
+func expired(expiresAt time.Time) bool {
+	return !expiresAt.IsZero() && time.Now().After(expiresAt)
+}
+
+// authorize sets the API key on req. An expired key is dropped, which sends
+// the client back to degraded mode until it is renewed.
+func (c *Client) authorize(req *http.Request) error {
+	apiKey, expiresAt := c.authState.key()
+	if apiKey != "" && expired(expiresAt) {
+		c.authState.drop(apiKey, errors.New("the API key expired"))
+	}
+	if err := c.Ready(); err != nil {
+		return err
+	}
+	req.Header.Set("X-API-KEY", apiKey)
+	return nil
+}
+
+// Authorize gets an API key, from the Redis cache or else from Pluggy, unless
+// the client has a valid one. Redis being down only costs the cache: the key
+// is then asked to Pluggy.
+func (c *Client) Authorize(ctx context.Context) error {
+	return c.renew(ctx, "", false)
+}
+
+// renew replaces the API key old, which is about to expire or, when
+// rejected, was turned down by Pluggy, unless another request did already.
+// When that fails, a key Pluggy did not reject and that has not expired yet
+// keeps serving requests while keepAuthorized tries again.
+func (c *Client) renew(ctx context.Context, old string, rejected bool) error {
+	c.authState.renewing.Lock()
+	defer c.authState.renewing.Unlock()
+
+	if current, _ := c.authState.key(); current != old && c.Ready() == nil {
+		return nil
+	}
+
+	err := c.fetchApiKey(ctx, old)
+	if err == nil {
+		return nil
+	}
+
+	current, expiresAt := c.authState.key()
+	switch {
+	case current == "":
+		c.authState.set("", time.Time{}, err)
+	case rejected && current == old, expired(expiresAt):
+		c.authState.drop(current, err)
+	default:
+		c.authState.keep(err)
+	}
+	return err
+}
+
+// fetchApiKey gets a new API key, skipping a cached one that is the rejected
+// key or about to expire.
+func (c *Client) fetchApiKey(ctx context.Context, rejected string) error {
+	if !c.hasCredentials() {
+		return errors.New("Pluggy credentials are not configured, set PLUGGY_CLIENT_ID and PLUGGY_CLIENT_SECRET")
+	}
+
+	apiKey, ttl, err := c.auth.getApiKey(ctx)
+	if err != nil {
+		logger.Warn("[redis][pluggy] error reading the cached api key, asking Pluggy for one", "error", err)
+	}
+
+	if err == nil {
+		if err := c.auth.dropLegacyConnectTokens(ctx); err != nil {
+			logger.Errorf("[redis][pluggy] error dropping legacy connect tokens: %v", err)
+		}
+	}
+
+	if apiKey != "" && apiKey != rejected && ttl > apiKeyRenewal {
+		c.authState.set(apiKey, time.Now().Add(ttl), nil)
+		return nil
+	}
+
+	issuedAt := time.Now()
+	apiKey, err = c.ApiKey(ctx)
+	if err != nil {
+		return fmt.Errorf("error authorizing with Pluggy: %w", err)
+	}
+	if err := c.auth.setApiKey(ctx, apiKey); err != nil {
+		logger.Warn("[redis][pluggy] error caching the api key", "error", err)
+	}
+
+	c.authState.set(apiKey, issuedAt.Add(apiKeyLifetime), nil)
+	return nil
+}
+
+func (c *Client) hasCredentials() bool {
+	return c.opts.ClientID != "" && c.opts.ClientSecret != ""
+}
+
+// StartAuthorization authorizes the client and keeps it authorized in the
+// background until ctx is done: the API key is renewed before it expires,
+// and while the client has none, as when Pluggy or Redis is down, attempts
+// are retried backing off up to authorizeMaxRetry. It returns the error of
+// the first attempt. Missing credentials are not retried, they only come
+// with a restart.
+func (c *Client) StartAuthorization(ctx context.Context) error {
+	err := c.Authorize(ctx)
+	if !c.hasCredentials() {
+		return err
+	}
+
+	go c.keepAuthorized(ctx)
+	return err
+}
+
+func (c *Client) keepAuthorized(ctx context.Context) {
+	delay := authorizeFirstRetry
+	for {
+		// A key kept after a failed renewal is retried like a missing one,
+		// rather than at its renewal time, which has passed.
+		wait := delay
+		if c.Ready() == nil && c.RenewalError() == nil {
+			_, expiresAt := c.authState.key()
+			wait = time.Until(expiresAt) - apiKeyRenewal
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.authState.lost:
+		case <-time.After(wait):
+		}
+
+		// A request may have renewed the key in the meantime.
+		apiKey, expiresAt := c.authState.key()
+		if c.Ready() == nil && time.Until(expiresAt) > apiKeyRenewal {
+			delay = authorizeFirstRetry
+			continue
+		}
+
+		degraded := c.Ready() != nil
+		if err := c.renew(ctx, apiKey, false); err != nil {
+			delay = min(delay*2, authorizeMaxRetry)
+			logger.Warn("[pluggy] authorization failed, retrying", "error", err, "retry_in", delay)
+			continue
+		}
+
+		delay = authorizeFirstRetry
+		if degraded {
+			logger.Info("[pluggy] authorized, leaving degraded mode")
+		} else {
+			logger.Debug("[pluggy] API key renewed")
+		}
+	}
+}
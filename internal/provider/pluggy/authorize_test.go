@@ -0,0 +1,77 @@
+package pluggy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// flakyAuth fails the first POST /auth and hands out new-key afterwards.
+type flakyAuth struct {
+	calls atomic.Int32
+}
+
+func (f *flakyAuth) RoundTrip(req *http.Request) (*http.Response, error) {
+	status, body := http.StatusOK, `{"apiKey":"new-key"}`
+	if f.calls.Add(1) == 1 {
+		status, body = http.StatusServiceUnavailable, `{"code":503,"message":"Service unavailable"}`
+	}
+	return &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+}
+
+func TestRenewFailsOnce(t *testing.T) {
+	tests := []struct {
+		name      string
+		key       string
+		expiresIn time.Duration
+		rejected  bool
+		wantKept  bool // whether the key still serves after the failure
+	}{
+		{name: "about to expire", key: "old-key", expiresIn: 5 * time.Minute, wantKept: true},
+		{name: "rejected by Pluggy", key: "old-key", expiresIn: 5 * time.Minute, rejected: true},
+		{name: "expired", key: "old-key", expiresIn: -time.Minute},
+		{name: "no key yet"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+			client := NewClient(NewAuth(cache, nil), Options{ClientID: "id", ClientSecret: "secret"})
+			fake := &flakyAuth{}
+			client.Transport = fake
+			if tt.key != "" {
+				client.authState.set(tt.key, time.Now().Add(tt.expiresIn), nil)
+			}
+
+			if err := client.renew(context.Background(), tt.key, tt.rejected); err == nil {
+				t.Fatal("the first renewal should fail")
+			}
+			key, _ := client.authState.key()
+			if tt.wantKept {
+				if key != tt.key || client.Ready() != nil || client.RenewalError() == nil {
+					t.Errorf("key = %q, Ready() = %v, RenewalError() = %v; want the old key kept and the error recorded", key, client.Ready(), client.RenewalError())
+				}
+			} else if key != "" || !errors.Is(client.Ready(), ErrNotAuthorized) || client.RenewalError() != nil {
+				t.Errorf("key = %q, Ready() = %v, RenewalError() = %v; want no key and the client not authorized", key, client.Ready(), client.RenewalError())
+			}
+
+			if err := client.renew(context.Background(), key, false); err != nil {
+				t.Fatal(err)
+			}
+			key, expiresAt := client.authState.key()
+			if key != "new-key" || time.Until(expiresAt) < apiKeyLifetime-time.Minute || client.Ready() != nil || client.RenewalError() != nil {
+				t.Errorf("key = %q expiring in %v, Ready() = %v, RenewalError() = %v; want the new key", key, time.Until(expiresAt), client.Ready(), client.RenewalError())
+			}
+			if got := fake.calls.Load(); got != 2 {
+				t.Errorf("Pluggy was asked %d times, want 2", got)
+			}
+		})
+	}
+}
@@ -1,13 +1,11 @@
 package pluggy
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"time"
-
-	"github.com/thunderjr/openfinance-mcp-server/internal/provider/logger"
 )
 
 type FinanceCharge struct {
@@ -28,16 +26,18 @@ type Bill struct {
 	FinanceCharges          []FinanceCharge `json:"financeCharges"`
 }
 
-func (c *Client) GetBills(accountID string) (*paginatedResponse[Bill], error) {
+func (c *Client) GetBills(ctx context.Context, accountID string) (*PaginatedResponse[Bill], error) {
 	if accountID == "" {
 		return nil, fmt.Errorf("pluggyClient.GetBills: accountID is required")
 	}
 
-	req, err := http.NewRequest("GET", fmt.Sprintf("https://api.pluggy.ai/bills?accountId=%s", accountID), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("https://api.pluggy.ai/bills?accountId=%s", accountID), nil)
 	if err != nil {
 		return nil, fmt.Errorf("pluggyClient.GetBills: error creating request: %w", err)
 	}
-	req.Header.Set("X-API-KEY", c.apiKey)
+	if err := c.authorize(req); err != nil {
+		return nil, err
+	}
 
 	res, err := c.Do(req)
 	if err != nil {
@@ -46,16 +46,10 @@ func (c *Client) GetBills(accountID string) (*paginatedResponse[Bill], error) {
 	defer res.Body.Close()
 
 	if res.StatusCode != http.StatusOK {
-		bodyBytes, err := io.ReadAll(res.Body)
-		if err != nil {
-			return nil, fmt.Errorf("pluggyClient.GetBills: error reading response body: %w", err)
-		}
-		logger.Debug("body: \n", string(bodyBytes))
-
-		return nil, fmt.Errorf("pluggyClient.GetBills: request failed with status %d", res.StatusCode)
+		return nil, newAPIError("pluggyClient.GetBills", res)
 	}
 
-	var data paginatedResponse[Bill]
+	var data PaginatedResponse[Bill]
 	if err := json.NewDecoder(res.Body).Decode(&data); err != nil {
 		return nil, fmt.Errorf("pluggyClient.GetBills: error decoding response: %w", err)
 	}
@@ -63,16 +57,18 @@ func (c *Client) GetBills(accountID string) (*paginatedResponse[Bill], error) {
 	return &data, nil
 }
 
-func (c *Client) GetBill(billID string) (*Bill, error) {
+func (c *Client) GetBill(ctx context.Context, billID string) (*Bill, error) {
 	if billID == "" {
 		return nil, fmt.Errorf("pluggyClient.GetBill: billID is required")
 	}
 
-	req, err := http.NewRequest("GET", fmt.Sprintf("https://api.pluggy.ai/bills/%s", billID), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("https://api.pluggy.ai/bills/%s", billID), nil)
 	if err != nil {
 		return nil, fmt.Errorf("pluggyClient.GetBill: error creating request: %w", err)
 	}
-	req.Header.Set("X-API-KEY", c.apiKey)
+	if err := c.authorize(req); err != nil {
+		return nil, err
+	}
 
 	res, err := c.Do(req)
 	if err != nil {
@@ -81,13 +77,7 @@ func (c *Client) GetBill(billID string) (*Bill, error) {
 	defer res.Body.Close()
 
 	if res.StatusCode != http.StatusOK {
-		bodyBytes, err := io.ReadAll(res.Body)
-		if err != nil {
-			return nil, fmt.Errorf("pluggyClient.GetBill: error reading response body: %w", err)
-		}
-		logger.Debug("body: \n", string(bodyBytes))
-
-		return nil, fmt.Errorf("pluggyClient.GetBill: request failed with status %d", res.StatusCode)
+		return nil, newAPIError("pluggyClient.GetBill", res)
 	}
 
 	var data Bill
@@ -2,41 +2,113 @@ package pluggy
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/cassette"
 	"github.com/thunderjr/openfinance-mcp-server/internal/provider/logger"
 )
 
-func (c *Client) ConnectToken(itemID string) (string, error) {
-	data, err := json.Marshal(map[string]any{
-		"itemId": itemID,
-		"options": map[string]bool{
-			"avoidDuplicates": true,
-		},
-	})
+// Pluggy connect tokens are valid for 30 minutes. Cached tokens are dropped a
+// bit earlier so a reused token still leaves the user time to finish Connect.
+const (
+	connectTokenLifetime   = 30 * time.Minute
+	connectTokenReuseSlack = 5 * time.Minute
+)
+
+type ConnectTokenOptions struct {
+	ItemID           string `json:"itemId,omitempty"`           // empty for a new connection
+	WebhookURL       string `json:"webhookUrl,omitempty"`       // notified on item events
+	ClientUserID     string `json:"clientUserId,omitempty"`     // your own user identifier
+	OAuthRedirectURI string `json:"oauthRedirectUri,omitempty"` // redirect after OAuth connectors
+}
+
+type ConnectToken struct {
+	AccessToken string    `json:"accessToken"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+	Reused      bool      `json:"reused"`
+}
+
+// cacheKey identifies tokens that can be shared: the same item and the same
+// options always yield an interchangeable token, so options other than the
+// item are hashed into the key. Only tokens that update an existing item are
+// cached; a token for a new connection is not tied to anyone's item and is
+// never shared.
+func (o ConnectTokenOptions) cacheKey() string {
+	if o.WebhookURL == "" && o.ClientUserID == "" && o.OAuthRedirectURI == "" {
+		return o.ItemID
+	}
+
+	data, _ := json.Marshal(o)
+	sum := sha256.Sum256(data)
+	return o.ItemID + ":" + hex.EncodeToString(sum[:8])
+}
+
+func (c *Client) ConnectToken(ctx context.Context, opts ConnectTokenOptions) (*ConnectToken, error) {
+	cacheable := c.mode != cassette.ModeReplay && opts.ItemID != ""
+	key := opts.cacheKey()
+
+	if cacheable {
+		cached, err := c.auth.getConnectToken(ctx, key)
+		if err != nil {
+			logger.Errorf("[pluggy.ConnectToken] error reading connect token from cache: %v", err)
+		}
+		if cached != nil && time.Until(cached.ExpiresAt) > connectTokenReuseSlack {
+			cached.Reused = true
+			return cached, nil
+		}
+	}
+
+	options := map[string]any{
+		"avoidDuplicates": true,
+	}
+	if opts.WebhookURL != "" {
+		options["webhookUrl"] = opts.WebhookURL
+	}
+	if opts.ClientUserID != "" {
+		options["clientUserId"] = opts.ClientUserID
+	}
+	if opts.OAuthRedirectURI != "" {
+		options["oauthRedirectUri"] = opts.OAuthRedirectURI
+	}
+
+	payload := map[string]any{
+		"options": options,
+	}
+	if opts.ItemID != "" {
+		payload["itemId"] = opts.ItemID
+	}
+
+	data, err := json.Marshal(payload)
 	if err != nil {
-		return "", fmt.Errorf("[pluggy.ConnectToken] error marshalling data: %w", err)
+		return nil, fmt.Errorf("[pluggy.ConnectToken] error marshalling data: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", "https://api.pluggy.ai/connect_token", bytes.NewBuffer(data))
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.pluggy.ai/connect_token", bytes.NewBuffer(data))
 	if err != nil {
-		return "", fmt.Errorf("[pluggy.ConnectToken] error creating request: %w", err)
+		return nil, fmt.Errorf("[pluggy.ConnectToken] error creating request: %w", err)
+	}
+	if err := c.authorize(req); err != nil {
+		return nil, err
 	}
-	req.Header.Set("X-API-KEY", c.apiKey)
 
 	req.Header.Set("accept", "application/json")
 	req.Header.Set("content-type", "application/json")
 
+	issuedAt := time.Now()
 	resp, err := c.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("[pluggy.ConnectToken] error making request: %w", err)
+		return nil, fmt.Errorf("[pluggy.ConnectToken] error making request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("[pluggy.ConnectToken] unexpected status code: %d", resp.StatusCode)
+		return nil, newAPIError("[pluggy.ConnectToken]", resp)
 	}
 
 	var body struct {
@@ -44,12 +116,19 @@ func (c *Client) ConnectToken(itemID string) (string, error) {
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
-		return "", fmt.Errorf("[pluggy.ConnectToken] error decoding response: %w", err)
+		return nil, fmt.Errorf("[pluggy.ConnectToken] error decoding response: %w", err)
+	}
+
+	token := &ConnectToken{
+		AccessToken: body.AccessToken,
+		ExpiresAt:   issuedAt.Add(connectTokenLifetime),
 	}
 
-	if err := c.auth.setConnectToken(itemID, body.AccessToken); err != nil {
-		logger.Errorf("[pluggy.ConnectToken] error saving connect token to cache: \nToken: %s\n%v", body.AccessToken, err)
+	if cacheable {
+		if err := c.auth.setConnectToken(ctx, key, token); err != nil {
+			logger.Errorf("[pluggy.ConnectToken] error saving connect token to cache: %v", err)
+		}
 	}
 
-	return body.AccessToken, nil
+	return token, nil
 }
@@ -0,0 +1,51 @@
+package pluggy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConnectTokenCacheKey(t *testing.T) {
+	tests := []struct {
+		name string
+		opts ConnectTokenOptions
+		want string
+	}{
+		{"item only", ConnectTokenOptions{ItemID: "item-1"}, "item-1"},
+		{"item with webhook", ConnectTokenOptions{ItemID: "item-1", WebhookURL: "https://example.com/hook"}, "item-1:"},
+		{"item with client user", ConnectTokenOptions{ItemID: "item-1", ClientUserID: "user-1"}, "item-1:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.opts.cacheKey()
+			if strings.HasSuffix(tt.want, ":") {
+				if !strings.HasPrefix(got, tt.want) || len(got) == len(tt.want) {
+					t.Errorf("cacheKey() = %q, want %q followed by an options hash", got, tt.want)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("cacheKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConnectTokenCacheKeyOptions(t *testing.T) {
+	base := ConnectTokenOptions{ItemID: "item-1", WebhookURL: "https://example.com/hook"}
+	same := base
+	other := base
+	other.ClientUserID = "user-1"
+	otherItem := base
+	otherItem.ItemID = "item-2"
+
+	if base.cacheKey() != same.cacheKey() {
+		t.Errorf("equal options yield different keys")
+	}
+	if base.cacheKey() == other.cacheKey() {
+		t.Errorf("different options share the key %q", base.cacheKey())
+	}
+	if base.cacheKey() == otherItem.cacheKey() {
+		t.Errorf("different items share the key %q", base.cacheKey())
+	}
+}
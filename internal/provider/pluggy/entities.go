@@ -6,15 +6,17 @@ import (
 	"github.com/shopspring/decimal"
 )
 
-type itemResponse struct {
-	ID              string    `json:"id"`
-	Status          string    `json:"status"`
-	ExecutionStatus string    `json:"executionStatus"`
-	CreatedAt       time.Time `json:"createdAt"`
-	UpdatedAt       time.Time `json:"updatedAt"`
-	LastUpdatedAt   time.Time `json:"lastUpdatedAt"`
-	NextAutoSyncAt  time.Time `json:"nextAutoSyncAt"`
-	Products        []string  `json:"products"`
+type Item struct {
+	ID              string     `json:"id"`
+	Status          string     `json:"status"`
+	ExecutionStatus string     `json:"executionStatus"`
+	Error           *ItemError `json:"error,omitempty"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	UpdatedAt       time.Time  `json:"updatedAt"`
+	LastUpdatedAt   time.Time  `json:"lastUpdatedAt"`
+	NextAutoSyncAt  time.Time  `json:"nextAutoSyncAt"`
+	Products        []string   `json:"products"`
+	ClientUserID    string     `json:"clientUserId,omitempty"`
 	Connector       struct {
 		ID             int      `json:"id"`
 		Name           string   `json:"name"`
@@ -31,7 +33,13 @@ type itemResponse struct {
 	} `json:"connector"`
 }
 
-type account struct {
+// ItemError is why the last update of an item failed.
+type ItemError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type Account struct {
 	ID           string          `json:"id"`
 	Type         string          `json:"type"`    // "BANK" or "CREDIT"
 	Subtype      string          `json:"subtype"` // "CHECKING_ACCOUNT", "CREDIT_CARD"
@@ -0,0 +1,51 @@
+package pluggy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/logger"
+)
+
+// APIError is a Pluggy API response with an unexpected status.
+type APIError struct {
+	Op         string
+	StatusCode int
+	Message    string // Pluggy's own error message, when the body has one
+	RequestID  string // X-Request-Id of the response, to quote to Pluggy support
+}
+
+func newAPIError(op string, res *http.Response) *APIError {
+	apiErr := &APIError{
+		Op:         op,
+		StatusCode: res.StatusCode,
+		RequestID:  res.Header.Get("X-Request-Id"),
+	}
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, 64<<10))
+	if err != nil {
+		return apiErr
+	}
+
+	// The body may echo what the request carried, so only what Pluggy says
+	// about the error is kept. A code of another type leaves the message.
+	var payload struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	_ = json.Unmarshal(body, &payload)
+	apiErr.Message = payload.Message
+
+	logger.Debug("[pluggy] error response", "op", op, "status", res.StatusCode, "code", payload.Code, "message", payload.Message, "request_id", apiErr.RequestID)
+	return apiErr
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s: request failed with status %d", e.Op, e.StatusCode)
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
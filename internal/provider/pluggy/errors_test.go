@@ -0,0 +1,89 @@
+package pluggy
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/logger"
+)
+
+// logFile holds what the package logs during the tests.
+var logFile string
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "pluggy-test")
+	if err != nil {
+		panic(err)
+	}
+	logFile = filepath.Join(dir, "pluggy.log")
+	if err := logger.Init(logger.Options{File: logFile, Level: "debug", Format: "json"}); err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+	logger.Close()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestNewAPIError(t *testing.T) {
+
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		wantMessage string
+		wantError   string
+	}{
+		{
+			name:        "pluggy error",
+			status:      http.StatusBadRequest,
+			body:        `{"code":400,"message":"Invalid accountId","data":{"owner":"Maria da Silva","taxNumber":"12345678909"}}`,
+			wantMessage: "Invalid accountId",
+			wantError:   "op: request failed with status 400: Invalid accountId",
+		},
+		{
+			name:        "code of another type",
+			status:      http.StatusNotFound,
+			body:        `{"code":"ITEM_NOT_FOUND","message":"Item not found"}`,
+			wantMessage: "Item not found",
+			wantError:   "op: request failed with status 404: Item not found",
+		},
+		{
+			name:      "not json",
+			status:    http.StatusBadGateway,
+			body:      `<html>Maria da Silva</html>`,
+			wantError: "op: request failed with status 502",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := &http.Response{
+				StatusCode: tt.status,
+				Header:     http.Header{"X-Request-Id": {"req-42"}},
+				Body:       io.NopCloser(strings.NewReader(tt.body)),
+			}
+			err := newAPIError("op", res)
+			if err.Message != tt.wantMessage || err.RequestID != "req-42" || err.Error() != tt.wantError {
+				t.Errorf("newAPIError() = %+v (%q), want message %q and error %q", err, err.Error(), tt.wantMessage, tt.wantError)
+			}
+		})
+	}
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, leaked := range []string{"Maria", "taxNumber", "html"} {
+		if strings.Contains(string(data), leaked) {
+			t.Errorf("the log holds the response body (%q):\n%s", leaked, data)
+		}
+	}
+	if !strings.Contains(string(data), `"request_id":"req-42"`) || !strings.Contains(string(data), `"code":400`) {
+		t.Errorf("the log should hold the code and request ID:\n%s", data)
+	}
+}
@@ -1,47 +1,127 @@
 package pluggy
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/audit"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/cassette"
 	"github.com/thunderjr/openfinance-mcp-server/internal/provider/logger"
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/tracing"
 )
 
+// Options are the Pluggy credentials and the cassette settings of a Client.
+type Options struct {
+	ClientID     string
+	ClientSecret string
+	CassetteMode string // empty, record or replay
+	CassetteDir  string
+}
+
 type Client struct {
 	http.Client
-	apiKey      string
+	opts        Options
+	mode        cassette.Mode
 	auth        *auth
+	authState   *authState
 	rateLimiter *rateLimiter
 }
 
-func NewClient(auth *auth) *Client {
-	if PLUGGY_CLIENT_ID == "" || PLUGGY_CLIENT_SECRET == "" {
-		logger.Fatal("missing Pluggy.ai credentials")
-	}
-
-	apiKey, err := auth.getApiKey()
+// NewClient returns a client that is not authorized yet: call Authorize, or
+// StartAuthorization to keep retrying in the background. Until then requests
+// fail with ErrNotAuthorized.
+func NewClient(auth *auth, opts Options) *Client {
+	mode, err := cassette.ParseMode(opts.CassetteMode)
 	if err != nil {
-		logger.Fatal(err)
+		logger.Fatal("[pluggy] invalid cassette mode", "error", err)
 	}
 
 	client := &Client{
-		auth:   auth,
-		apiKey: apiKey,
+		auth:      auth,
+		opts:      opts,
+		mode:      mode,
+		authState: newAuthState(),
 		rateLimiter: &rateLimiter{
 			timestamp: time.Now(),
 		},
 	}
+	rateLimitRemaining.Set(maxRequests)
 
-	if apiKey == "" {
-		apiKey, err = client.ApiKey()
-		if err != nil {
-			logger.Fatalf("[pluggy] error authorizing: %v", err)
-		}
-		if err = auth.setApiKey(apiKey); err != nil {
-			logger.Fatalf("[redis][pluggy] error saving api key: %v", err)
+	if mode != cassette.ModeOff {
+		dir := opts.CassetteDir
+		if dir == "" {
+			dir = "cassettes"
 		}
+		logger.Infof("[pluggy] cassette %s mode enabled (dir: %s)", mode, dir)
+		client.Transport = cassette.NewTransport(mode, dir, http.DefaultTransport)
+	}
+
+	// Recorded requests have the api key scrubbed, so replay needs none and
+	// must not touch the real one in the cache.
+	if mode == cassette.ModeReplay {
+		client.authState.set("replay", time.Time{}, nil)
 	}
 
-	client.apiKey = apiKey
 	return client
 }
+
+// Do sends req. When Pluggy rejects its API key, which expired or was revoked,
+// the key is renewed and req sent once more. Requests are made with the
+// context of the tool call behind them, which attributes them to it in the
+// audit trail.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	res, err := c.send(req)
+	rejected := req.Header.Get("X-API-KEY")
+	if err != nil || res.StatusCode != http.StatusUnauthorized || rejected == "" || c.mode == cassette.ModeReplay {
+		return res, err
+	}
+	res.Body.Close()
+
+	logger.Warn("[pluggy] Pluggy rejected the API key, renewing it", "endpoint", endpoint(req))
+	// The renewed key serves every request, so it is not given up when
+	// this one is canceled.
+	if err := c.renew(context.WithoutCancel(req.Context()), rejected, true); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotAuthorized, err)
+	}
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.authorize(retry); err != nil {
+		return nil, err
+	}
+	return c.send(retry)
+}
+
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	name, start := endpoint(req), time.Now()
+	audit.Endpoint(req.Context(), name)
+
+	ctx, span := tracing.Start(req.Context(), "pluggy "+name, tracing.KindClient)
+	defer span.End()
+	span.SetAttributes("http.request.method", req.Method, "pluggy.endpoint", name)
+	if itemID := requestItemID(req); itemID != "" {
+		span.SetAttributes("pluggy.item_id_hash", tracing.Hash(itemID))
+	}
+
+	res, err := c.Client.Do(req.WithContext(ctx))
+	requestDuration.ObserveDuration(time.Since(start), name)
+
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(res.StatusCode)
+		span.SetAttributes("http.response.status_code", res.StatusCode)
+		if res.StatusCode >= 400 {
+			span.SetError(fmt.Errorf("pluggy answered %s", res.Status))
+		}
+	}
+	span.SetError(err)
+	requestsTotal.Inc(name, status)
+	return res, err
+}
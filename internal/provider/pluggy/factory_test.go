@@ -0,0 +1,146 @@
+package pluggy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/audit"
+)
+
+// fakePluggy answers every request with body and status, failing like a real
+// transport once the request's context is done.
+type fakePluggy struct {
+	status int
+	body   string
+
+	mu       sync.Mutex
+	requests []*http.Request
+}
+
+func (f *fakePluggy) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	f.requests = append(f.requests, req)
+	f.mu.Unlock()
+	return &http.Response{
+		StatusCode: f.status,
+		Status:     http.StatusText(f.status),
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(f.body)),
+		Request:    req,
+	}, nil
+}
+
+// newTestClient returns an authorized client whose requests go to fake.
+func newTestClient(fake http.RoundTripper) *Client {
+	client := NewClient(nil, Options{})
+	client.Transport = fake
+	client.authState.set("api-key", time.Now().Add(time.Hour), nil)
+	return client
+}
+
+func TestEndpoint(t *testing.T) {
+	tests := []struct {
+		method, url string
+		want        string
+	}{
+		{"GET", "https://api.pluggy.ai/accounts?itemId=8a1f-item", "GET /accounts"},
+		{"GET", "https://api.pluggy.ai/accounts/8a1f-account", "GET /accounts/{id}"},
+		{"PATCH", "https://api.pluggy.ai/items/8a1f-item", "PATCH /items/{id}"},
+		{"GET", "https://api.pluggy.ai/bills/8a1f-bill", "GET /bills/{id}"},
+		{"POST", "https://api.pluggy.ai/auth", "POST /auth"},
+	}
+	for _, tt := range tests {
+		req, err := http.NewRequest(tt.method, tt.url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := endpoint(req); got != tt.want {
+			t.Errorf("endpoint(%s %s) = %q, want %q", tt.method, tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestRequestsAreAudited(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		call func(ctx context.Context, c *Client) error
+		want []string
+	}{
+		{
+			name: "item",
+			body: `{"id":"8a1f-item"}`,
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.GetItem(ctx, "8a1f-item")
+				return err
+			},
+			want: []string{"GET /items/{id}"},
+		},
+		{
+			name: "accounts and account",
+			body: `{"results":[]}`,
+			call: func(ctx context.Context, c *Client) error {
+				if _, err := c.GetAccounts(ctx, "8a1f-item"); err != nil {
+					return err
+				}
+				_, err := c.GetAccount(ctx, "8a1f-account")
+				return err
+			},
+			want: []string{"GET /accounts", "GET /accounts/{id}"},
+		},
+		{
+			name: "bills",
+			body: `{"results":[]}`,
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.GetBills(ctx, "8a1f-account")
+				return err
+			},
+			want: []string{"GET /bills"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(&fakePluggy{status: http.StatusOK, body: tt.body})
+			call := &audit.Call{Tool: "test"}
+			if err := tt.call(audit.WithCall(context.Background(), call), client); err != nil {
+				t.Fatal(err)
+			}
+			if got := call.Endpoints(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("endpoints = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestsFollowTheirContext(t *testing.T) {
+	fake := &fakePluggy{status: http.StatusOK, body: `{"id":"8a1f-item"}`}
+	client := newTestClient(fake)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.GetItem(ctx, "8a1f-item"); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetItem with a canceled context = %v, want context.Canceled", err)
+	}
+	if len(fake.requests) != 0 {
+		t.Errorf("%d requests reached Pluggy, want none", len(fake.requests))
+	}
+
+	type key struct{}
+	ctx = context.WithValue(context.Background(), key{}, "call")
+	if _, err := client.GetItem(ctx, "8a1f-item"); err != nil {
+		t.Fatal(err)
+	}
+	if got := fake.requests[0].Context().Value(key{}); got != "call" {
+		t.Errorf("the request was sent with another context, value = %v", got)
+	}
+}
@@ -0,0 +1,43 @@
+package pluggy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// CheckApiKey makes the cheapest authenticated request, listing categories,
+// to tell whether Pluggy still accepts the client's API key.
+func (c *Client) CheckApiKey(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.pluggy.ai/categories", nil)
+	if err != nil {
+		return fmt.Errorf("pluggyClient.CheckApiKey: error creating request: %w", err)
+	}
+	if err := c.authorize(req); err != nil {
+		return err
+	}
+
+	res, err := c.Do(req)
+	if err != nil {
+		return fmt.Errorf("pluggyClient.CheckApiKey: error making request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return newAPIError("pluggyClient.CheckApiKey", res)
+	}
+	return nil
+}
+
+// RateLimit is the state of the client side limit on transaction and
+// investment requests, the only ones it covers.
+type RateLimit struct {
+	Limit     int           `json:"limit"`
+	Remaining int           `json:"remaining"`
+	ResetIn   time.Duration `json:"reset_in"`
+}
+
+func (c *Client) RateLimit() RateLimit {
+	return c.rateLimiter.status()
+}
@@ -1,6 +1,7 @@
 package pluggy
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -28,8 +29,10 @@ type InvestmentsFilter struct {
 
 }
 
-func (c *Client) GetInvestments(itemID string, query *InvestmentsFilter) (*paginatedResponse[Investment], error) {
-	c.rateLimiter.wait()
+func (c *Client) GetInvestments(ctx context.Context, itemID string, query *InvestmentsFilter) (*PaginatedResponse[Investment], error) {
+	if err := c.rateLimiter.wait(ctx); err != nil {
+		return nil, fmt.Errorf("pluggyClient.GetInvestments: waiting for the rate limit: %w", err)
+	}
 
 	q := url.Values{}
 	url := "https://api.pluggy.ai/investments"
@@ -49,12 +52,14 @@ func (c *Client) GetInvestments(itemID string, query *InvestmentsFilter) (*pagin
 	}
 
 	url = fmt.Sprintf("%s?%s", url, q.Encode())
-	logger.Debug("url", url)
-	req, err := http.NewRequest("GET", url, nil)
+	logger.Debug("[pluggy] investments request", "url", url)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("pluggy_client: error creating request: %w", err)
 	}
-	req.Header.Set("X-API-KEY", c.apiKey)
+	if err := c.authorize(req); err != nil {
+		return nil, err
+	}
 
 	res, err := c.Do(req)
 	if err != nil {
@@ -63,10 +68,10 @@ func (c *Client) GetInvestments(itemID string, query *InvestmentsFilter) (*pagin
 	defer res.Body.Close()
 
 	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("pluggy_client: error item request failed with status %d", res.StatusCode)
+		return nil, newAPIError("pluggy_client", res)
 	}
 
-	var data paginatedResponse[Investment]
+	var data PaginatedResponse[Investment]
 	if err := json.NewDecoder(res.Body).Decode(&data); err != nil {
 		return nil, fmt.Errorf("pluggy_client: error item decoding response: %w", err)
 	}
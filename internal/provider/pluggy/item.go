@@ -1,9 +1,12 @@
 package pluggy
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -17,31 +20,77 @@ const (
 	ItemStatusUpdated          ItemStatus = "UPDATED"
 )
 
-func (c *Client) WaitUpdated(itemID string) error {
-	status := ItemStatusUpdating
-	for status == ItemStatusUpdating {
-		res, err := c.GetItem(itemID)
+// WaitOptions control how WaitUpdated polls an item.
+type WaitOptions struct {
+	Timeout  time.Duration    // give up after this long, returning ErrStillUpdating
+	OnChange func(item *Item) // called with the first item and whenever its status changes
+}
+
+// ErrStillUpdating is returned by WaitUpdated with the last item seen when the
+// item is still updating once the timeout expires.
+var ErrStillUpdating = errors.New("pluggy.WaitUpdated: item is still updating")
+
+const (
+	waitFirstInterval = 2 * time.Second
+	waitMaxInterval   = 15 * time.Second
+)
+
+// WaitUpdated polls an item, backing off between requests, until it leaves the
+// UPDATING status, and returns it in that final state, which may be an error
+// state such as LOGIN_ERROR. It stops early when ctx is done.
+func (c *Client) WaitUpdated(ctx context.Context, itemID string, opts WaitOptions) (*Item, error) {
+	parent := ctx
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	var last *Item
+	interval := waitFirstInterval
+	for {
+		item, err := c.GetItem(ctx, itemID)
 		if err != nil {
-			return fmt.Errorf("pluggy.GetItem: error waiting updated: %w", err)
+			if ctx.Err() != nil && last != nil {
+				return waitStopped(parent, last)
+			}
+			return nil, fmt.Errorf("pluggy.WaitUpdated: %w", err)
 		}
 
-		status = ItemStatus(res.Status)
-		time.Sleep(5 * time.Second)
-	}
+		if opts.OnChange != nil && (last == nil || item.Status != last.Status || item.ExecutionStatus != last.ExecutionStatus) {
+			opts.OnChange(item)
+		}
+		last = item
+
+		if ItemStatus(item.Status) != ItemStatusUpdating {
+			return item, nil
+		}
 
-	if status != ItemStatusUpdated {
-		return fmt.Errorf("pluggy.WaitUpdated: item status is %s", status)
+		select {
+		case <-ctx.Done():
+			return waitStopped(parent, last)
+		case <-time.After(interval):
+		}
+		interval = min(interval*3/2, waitMaxInterval)
 	}
+}
 
-	return nil
+// waitStopped tells a canceled caller from an expired timeout.
+func waitStopped(parent context.Context, last *Item) (*Item, error) {
+	if parent.Err() != nil {
+		return nil, fmt.Errorf("pluggy.WaitUpdated: %w", parent.Err())
+	}
+	return last, ErrStillUpdating
 }
 
-func (c *Client) GetItem(id string) (*itemResponse, error) {
-	req, err := http.NewRequest("GET", fmt.Sprintf("https://api.pluggy.ai/items/%s", id), nil)
+func (c *Client) GetItem(ctx context.Context, id string) (*Item, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("https://api.pluggy.ai/items/%s", id), nil)
 	if err != nil {
 		return nil, fmt.Errorf("pluggy_client: error creating request: %w", err)
 	}
-	req.Header.Set("X-API-KEY", c.apiKey)
+	if err := c.authorize(req); err != nil {
+		return nil, err
+	}
 
 	res, err := c.Do(req)
 	if err != nil {
@@ -50,13 +99,43 @@ func (c *Client) GetItem(id string) (*itemResponse, error) {
 	defer res.Body.Close()
 
 	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("pluggy_client: error item request failed with status %d", res.StatusCode)
+		return nil, newAPIError("pluggy_client", res)
 	}
 
-	var data itemResponse
+	var data Item
 	if err := json.NewDecoder(res.Body).Decode(&data); err != nil {
 		return nil, fmt.Errorf("pluggy_client: error item decoding response: %w", err)
 	}
 
 	return &data, nil
 }
+
+// UpdateItem asks Pluggy to synchronize an item with its institution now. The
+// item comes back UPDATING; WaitUpdated follows it to the end.
+func (c *Client) UpdateItem(ctx context.Context, id string) (*Item, error) {
+	req, err := http.NewRequestWithContext(ctx, "PATCH", fmt.Sprintf("https://api.pluggy.ai/items/%s", id), strings.NewReader("{}"))
+	if err != nil {
+		return nil, fmt.Errorf("pluggyClient.UpdateItem: error creating request: %w", err)
+	}
+	if err := c.authorize(req); err != nil {
+		return nil, err
+	}
+	req.Header.Set("content-type", "application/json")
+
+	res, err := c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("pluggyClient.UpdateItem: error making request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, newAPIError("pluggyClient.UpdateItem", res)
+	}
+
+	var data Item
+	if err := json.NewDecoder(res.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("pluggyClient.UpdateItem: error decoding response: %w", err)
+	}
+
+	return &data, nil
+}
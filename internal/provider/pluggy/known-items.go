@@ -0,0 +1,67 @@
+package pluggy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var AUTH_CACHE_KNOWN_ITEMS_KEY = "pluggy:known_items"
+
+type KnownItem struct {
+	ID           string    `json:"id"`
+	RegisteredAt time.Time `json:"registeredAt"`
+}
+
+var knownItemsMu sync.Mutex
+
+// KnownItems lists the items registered with this server, e.g. by finishing
+// Pluggy Connect through the local widget host.
+func (c *Client) KnownItems(ctx context.Context) ([]KnownItem, error) {
+	res, err := c.auth.get(ctx, AUTH_CACHE_KNOWN_ITEMS_KEY)
+	if errors.Is(err, redis.Nil) {
+		return []KnownItem{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("pluggyClient.KnownItems: error reading known items: %w", err)
+	}
+
+	var items []KnownItem
+	if err := json.Unmarshal([]byte(res), &items); err != nil {
+		return nil, fmt.Errorf("pluggyClient.KnownItems: error decoding known items: %w", err)
+	}
+	return items, nil
+}
+
+func (c *Client) RegisterItem(ctx context.Context, itemID string) error {
+	if itemID == "" {
+		return fmt.Errorf("pluggyClient.RegisterItem: itemID is required")
+	}
+
+	knownItemsMu.Lock()
+	defer knownItemsMu.Unlock()
+
+	items, err := c.KnownItems(ctx)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if item.ID == itemID {
+			return nil
+		}
+	}
+
+	data, err := json.Marshal(append(items, KnownItem{ID: itemID, RegisteredAt: time.Now()}))
+	if err != nil {
+		return fmt.Errorf("pluggyClient.RegisterItem: error encoding known items: %w", err)
+	}
+	if err := c.auth.set(ctx, AUTH_CACHE_KNOWN_ITEMS_KEY, string(data), 0); err != nil {
+		return fmt.Errorf("pluggyClient.RegisterItem: error saving known items: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package pluggy
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/metrics"
+)
+
+var (
+	requestsTotal = metrics.NewCounter("openfinance_pluggy_requests_total",
+		"Pluggy API requests by endpoint and response status (error when no response came back).", "endpoint", "status")
+	requestDuration = metrics.NewHistogram("openfinance_pluggy_request_duration_seconds",
+		"Latency of Pluggy API requests by endpoint.", metrics.DefaultBuckets, "endpoint")
+
+	rateLimitRemaining = metrics.NewGauge("openfinance_pluggy_rate_limit_remaining",
+		"Transaction and investment requests left in the current hour before the rate limiter makes them wait; other Pluggy requests are not limited.")
+	rateLimitWait = metrics.NewHistogram("openfinance_pluggy_rate_limit_wait_seconds",
+		"Time transaction and investment requests spent waiting on the rate limiter.", []float64{0.001, 1, 10, 60, 300, 900, 1800, 3600})
+
+	cacheLookups = metrics.NewCounter("openfinance_cache_lookups_total",
+		"Lookups of cached Pluggy credentials in Redis by cache (api_key or connect_token) and result (hit or miss).", "cache", "result")
+	redisErrors = metrics.NewCounter("openfinance_redis_errors_total",
+		"Failed Redis operations by operation (get, set or delete).", "operation")
+)
+
+// endpoint names a request by its method and path with IDs replaced, so the
+// number of series stays bounded: GET /items/{id}.
+func endpoint(req *http.Request) string {
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	for i := 1; i < len(segments); i++ {
+		segments[i] = "{id}"
+	}
+	return req.Method + " /" + strings.Join(segments, "/")
+}
+
+// requestItemID is the item a request is about, from its /items/{id} path or
+// its itemId parameter.
+func requestItemID(req *http.Request) string {
+	if id, ok := strings.CutPrefix(req.URL.Path, "/items/"); ok {
+		return id
+	}
+	return req.URL.Query().Get("itemId")
+}
+
+// cacheName tells which cache a Redis key belongs to, empty for the keys
+// that are not a cache, such as the known items.
+func cacheName(key string) string {
+	switch {
+	case key == AUTH_CACHE_API_KEY:
+		return "api_key"
+	case strings.HasPrefix(key, AUTH_CACHE_CONNECT_TOKEN_PREFIX):
+		return "connect_token"
+	default:
+		return ""
+	}
+}
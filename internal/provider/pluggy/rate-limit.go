@@ -1,20 +1,38 @@
 package pluggy
 
 import (
+	"context"
 	"sync"
 	"time"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/tracing"
 )
 
 const maxRequests = 360
 const rateLimitDuration = time.Hour
 
+// rateLimiter caps the transaction and investment listings, which analyses
+// page through by the hundreds. Other requests do not go through it.
 type rateLimiter struct {
 	mu        sync.Mutex
 	timestamp time.Time
 	count     int
 }
 
-func (rl *rateLimiter) wait() {
+// wait blocks until a request is allowed, or returns ctx's error once it is
+// done.
+func (rl *rateLimiter) wait(ctx context.Context) (err error) {
+	start := time.Now()
+	_, span := tracing.Start(ctx, "pluggy rate limit wait", tracing.KindInternal)
+	defer func() {
+		remaining := rl.status().Remaining
+		rateLimitWait.ObserveDuration(time.Since(start))
+		rateLimitRemaining.Set(float64(remaining))
+		span.SetAttributes("pluggy.rate_limit.remaining", remaining)
+		span.SetError(err)
+		span.End()
+	}()
+
 	for {
 		rl.mu.Lock()
 		now := time.Now()
@@ -30,7 +48,7 @@ func (rl *rateLimiter) wait() {
 		if rl.count < maxRequests {
 			rl.count++
 			rl.mu.Unlock()
-			return
+			return nil
 		}
 
 		rl.mu.Unlock()
@@ -41,6 +59,25 @@ func (rl *rateLimiter) wait() {
 			sleepTime = time.Second
 		}
 
-		time.Sleep(sleepTime)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(sleepTime):
+		}
+	}
+}
+
+func (rl *rateLimiter) status() RateLimit {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	elapsed := time.Since(rl.timestamp)
+	if elapsed > rateLimitDuration {
+		return RateLimit{Limit: maxRequests, Remaining: maxRequests}
+	}
+	return RateLimit{
+		Limit:     maxRequests,
+		Remaining: maxRequests - rl.count,
+		ResetIn:   rateLimitDuration - elapsed,
 	}
 }
@@ -0,0 +1,57 @@
+package pluggy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterWait(t *testing.T) {
+	tests := []struct {
+		name      string
+		count     int
+		elapsed   time.Duration // since the window started
+		timeout   time.Duration
+		wantErr   error
+		wantCount int
+	}{
+		{"below the limit", 10, time.Minute, time.Second, nil, 11},
+		{"window over", maxRequests, rateLimitDuration + time.Second, time.Second, nil, 1},
+		{"limit reached", maxRequests, time.Minute, 50 * time.Millisecond, context.DeadlineExceeded, maxRequests},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rl := &rateLimiter{timestamp: time.Now().Add(-tt.elapsed), count: tt.count}
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := rl.wait(ctx)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("wait() = %v, want %v", err, tt.wantErr)
+			}
+			if waited := time.Since(start); waited > tt.timeout+500*time.Millisecond {
+				t.Errorf("wait() took %s, want it to stop with its context", waited)
+			}
+			if rl.count != tt.wantCount {
+				t.Errorf("count = %d, want %d", rl.count, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestGetTransactionsCanceledWhileLimited(t *testing.T) {
+	fake := &fakePluggy{}
+	client := newTestClient(fake)
+	client.rateLimiter = &rateLimiter{timestamp: time.Now(), count: maxRequests}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	if _, err := client.GetTransactions(ctx, "8a1f-account", nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetTransactions() = %v, want context.Canceled", err)
+	}
+	if len(fake.requests) != 0 {
+		t.Errorf("%d requests reached Pluggy, want none", len(fake.requests))
+	}
+}
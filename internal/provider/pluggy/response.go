@@ -1,6 +1,6 @@
 package pluggy
 
-type paginatedResponse[T any] struct {
+type PaginatedResponse[T any] struct {
 	Page       float64 `json:"page"`
 	Total      float64 `json:"total"`
 	TotalPages float64 `json:"totalPages"`
@@ -1,6 +1,7 @@
 package pluggy
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -18,8 +19,10 @@ type TransactionFilter struct {
 	CreatedAtFrom time.Time `json:"createdAtFrom,omitempty"` // ISO 8601
 }
 
-func (c *Client) GetTransactions(accountID string, query *TransactionFilter) (*paginatedResponse[Transaction], error) {
-	c.rateLimiter.wait()
+func (c *Client) GetTransactions(ctx context.Context, accountID string, query *TransactionFilter) (*PaginatedResponse[Transaction], error) {
+	if err := c.rateLimiter.wait(ctx); err != nil {
+		return nil, fmt.Errorf("pluggyClient.GetTransactions: waiting for the rate limit: %w", err)
+	}
 
 	q := url.Values{}
 	url := "https://api.pluggy.ai/transactions"
@@ -45,11 +48,13 @@ func (c *Client) GetTransactions(accountID string, query *TransactionFilter) (*p
 	}
 
 	url = fmt.Sprintf("%s?%s", url, q.Encode())
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("pluggy_client: error creating request: %w", err)
 	}
-	req.Header.Set("X-API-KEY", c.apiKey)
+	if err := c.authorize(req); err != nil {
+		return nil, err
+	}
 
 	res, err := c.Do(req)
 	if err != nil {
@@ -58,10 +63,10 @@ func (c *Client) GetTransactions(accountID string, query *TransactionFilter) (*p
 	defer res.Body.Close()
 
 	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("pluggy_client: error item request failed with status %d", res.StatusCode)
+		return nil, newAPIError("pluggy_client", res)
 	}
 
-	var data paginatedResponse[Transaction]
+	var data PaginatedResponse[Transaction]
 	if err := json.NewDecoder(res.Body).Decode(&data); err != nil {
 		return nil, fmt.Errorf("pluggy_client: error item decoding response: %w", err)
 	}
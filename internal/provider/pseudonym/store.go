@@ -0,0 +1,268 @@
+package pseudonym
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kind is the prefix of the pseudonyms of one kind of Pluggy identifier.
+type Kind string
+
+const (
+	Item        Kind = "item"
+	Account     Kind = "acc"
+	Transaction Kind = "tx"
+	Bill        Kind = "bill"
+	Investment  Kind = "inv"
+	// Connect is a Pluggy Connect session of the widget host, whose ID the
+	// model passes back to pluggy_connect_status.
+	Connect Kind = "connect"
+)
+
+// references are the JSON keys holding identifiers of other entities.
+var references = map[string]Kind{
+	"itemId":    Item,
+	"accountId": Account,
+	"billId":    Bill,
+}
+
+var aliasPattern = regexp.MustCompile(`^(item|acc|tx|bill|inv|connect)_[0-9]+$`)
+
+const (
+	// sessionTTL is how long the mapping of an idle session is kept.
+	sessionTTL = 24 * time.Hour
+	// sweepPeriod is how often the mappings of idle sessions are dropped.
+	sweepPeriod = 10 * time.Minute
+)
+
+// Store maps Pluggy identifiers to short pseudonyms (acc_1, tx_42), separately
+// for each MCP session, so identifiers in one transcript mean nothing to the
+// Pluggy API or to another session. The mapping only lives in memory.
+type Store struct {
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+type session struct {
+	aliases  map[string]string // identifier -> pseudonym
+	ids      map[string]string // pseudonym -> identifier
+	next     map[Kind]int
+	lastUsed time.Time
+	// replacer rewrites identifiers in text, built on first use and reset
+	// when an identifier is added.
+	replacer *strings.Replacer
+}
+
+// NewStore returns an empty store, which drops idle sessions in the background
+// for as long as the process runs.
+func NewStore() *Store {
+	s := &Store{sessions: map[string]*session{}}
+	go s.sweep()
+	return s
+}
+
+var store *Store
+
+// Enable turns pseudonymization on for the whole process.
+func Enable() {
+	store = NewStore()
+}
+
+func Enabled() bool {
+	return store != nil
+}
+
+func (s *Store) sweep() {
+	ticker := time.NewTicker(sweepPeriod)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		s.expire(now)
+	}
+}
+
+// expire drops the mappings of the sessions idle since before now-sessionTTL.
+func (s *Store) expire(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, sess := range s.sessions {
+		if now.Sub(sess.lastUsed) > sessionTTL {
+			delete(s.sessions, key)
+		}
+	}
+}
+
+// session returns the mapping of id, creating it if needed. Callers must hold
+// s.mu.
+func (s *Store) session(id string) *session {
+	now := time.Now()
+	sess, ok := s.sessions[id]
+	if !ok {
+		sess = &session{
+			aliases: map[string]string{},
+			ids:     map[string]string{},
+			next:    map[Kind]int{},
+		}
+		s.sessions[id] = sess
+	}
+	sess.lastUsed = now
+	return sess
+}
+
+func (sess *session) alias(kind Kind, id string) string {
+	if alias, ok := sess.aliases[id]; ok {
+		return alias
+	}
+	sess.next[kind]++
+	alias := string(kind) + "_" + strconv.Itoa(sess.next[kind])
+	sess.aliases[id] = alias
+	sess.ids[alias] = id
+	sess.replacer = nil
+	return alias
+}
+
+// Resolve returns the identifier behind a pseudonym of the session. Values that
+// are not pseudonyms, like an item ID typed by the user, are returned as is.
+func Resolve(sessionID, value string) (string, error) {
+	if store == nil || !aliasPattern.MatchString(value) {
+		return value, nil
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	id, ok := store.session(sessionID).ids[value]
+	if !ok {
+		return "", fmt.Errorf("unknown identifier %q, it may belong to another session", value)
+	}
+	return id, nil
+}
+
+// Alias returns the session's pseudonym for the identifier value, giving it one
+// if needed. Pseudonyms are returned as is.
+func Alias(sessionID string, kind Kind, value string) string {
+	if store == nil || value == "" || aliasPattern.MatchString(value) {
+		return value
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return store.session(sessionID).alias(kind, value)
+}
+
+// Apply returns a copy of v with identifiers replaced by pseudonyms: the "id"
+// of the entities, of the given kind, and references like "itemId" anywhere.
+// v is either an entity, a list of them or a page of results.
+func Apply[T any](sessionID string, kind Kind, v T) (T, error) {
+	if store == nil {
+		return v, nil
+	}
+
+	var out T
+	data, err := json.Marshal(v)
+	if err != nil {
+		return out, err
+	}
+
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return out, fmt.Errorf("pseudonym: error decoding value: %w", err)
+	}
+
+	store.mu.Lock()
+	sess := store.session(sessionID)
+	switch val := generic.(type) {
+	case map[string]any:
+		if results, ok := val["results"].([]any); ok {
+			sess.entities(kind, results)
+		} else {
+			sess.entity(kind, val)
+		}
+	case []any:
+		sess.entities(kind, val)
+	}
+	store.mu.Unlock()
+
+	if data, err = json.Marshal(generic); err != nil {
+		return out, err
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return out, fmt.Errorf("pseudonym: error decoding pseudonymized value: %w", err)
+	}
+	return out, nil
+}
+
+// Text replaces the identifiers the session already knows by their pseudonyms,
+// for free text such as error messages.
+func Text(sessionID, text string) string {
+	if store == nil {
+		return text
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.session(sessionID).text(text)
+}
+
+func (sess *session) text(text string) string {
+	if sess.replacer == nil {
+		ids := make([]string, 0, len(sess.aliases))
+		for id := range sess.aliases {
+			ids = append(ids, id)
+		}
+		// Longer identifiers first, so one that contains another wins.
+		sort.Slice(ids, func(i, j int) bool {
+			if len(ids[i]) != len(ids[j]) {
+				return len(ids[i]) > len(ids[j])
+			}
+			return ids[i] < ids[j]
+		})
+
+		pairs := make([]string, 0, len(ids)*2)
+		for _, id := range ids {
+			pairs = append(pairs, id, sess.aliases[id])
+		}
+		sess.replacer = strings.NewReplacer(pairs...)
+	}
+	return sess.replacer.Replace(text)
+}
+
+func (sess *session) entities(kind Kind, list []any) {
+	for _, item := range list {
+		if entity, ok := item.(map[string]any); ok {
+			sess.entity(kind, entity)
+		}
+	}
+}
+
+func (sess *session) entity(kind Kind, entity map[string]any) {
+	if id, ok := entity["id"].(string); ok && kind != "" && id != "" {
+		entity["id"] = sess.alias(kind, id)
+	}
+	sess.value(entity)
+}
+
+func (sess *session) value(v any) {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, child := range val {
+			if kind, ok := references[k]; ok {
+				if id, ok := child.(string); ok && id != "" {
+					val[k] = sess.alias(kind, id)
+				}
+				continue
+			}
+			sess.value(child)
+		}
+	case []any:
+		for _, child := range val {
+			sess.value(child)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package pseudonym
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func enable(t *testing.T) {
+	t.Helper()
+	previous := store
+	Enable()
+	t.Cleanup(func() { store = previous })
+}
+
+func TestAliasAndResolve(t *testing.T) {
+	enable(t)
+
+	tests := []struct {
+		session, kind, id string
+		want              string
+	}{
+		{"s1", "acc", "8a1f-account", "acc_1"},
+		{"s1", "acc", "8a1f-account", "acc_1"},
+		{"s1", "acc", "93bc-account", "acc_2"},
+		{"s1", "tx", "tx-uuid", "tx_1"},
+		{"s1", "connect", "c0ffee", "connect_1"},
+		{"s2", "acc", "93bc-account", "acc_1"},
+		{"s1", "acc", "acc_7", "acc_7"},
+		{"s1", "acc", "", ""},
+	}
+	for _, tt := range tests {
+		if got := Alias(tt.session, Kind(tt.kind), tt.id); got != tt.want {
+			t.Errorf("Alias(%s, %s, %q) = %q, want %q", tt.session, tt.kind, tt.id, got, tt.want)
+		}
+	}
+
+	resolves := []struct {
+		session, value string
+		want           string
+		wantErr        bool
+	}{
+		{"s1", "acc_2", "93bc-account", false},
+		{"s2", "acc_1", "93bc-account", false},
+		{"s1", "connect_1", "c0ffee", false},
+		{"s1", "8a1f-account", "8a1f-account", false},
+		{"s2", "tx_1", "", true},
+		{"s3", "acc_1", "", true},
+	}
+	for _, tt := range resolves {
+		got, err := Resolve(tt.session, tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("Resolve(%s, %q) = %q, %v; want %q, error %t", tt.session, tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	previous := store
+	store = nil
+	defer func() { store = previous }()
+
+	if got := Alias("s1", Account, "8a1f"); got != "8a1f" {
+		t.Errorf("Alias() = %q, want the identifier", got)
+	}
+	if got, err := Resolve("s1", "acc_1"); got != "acc_1" || err != nil {
+		t.Errorf("Resolve() = %q, %v; want the value", got, err)
+	}
+	if got := Text("s1", "item 8a1f failed"); got != "item 8a1f failed" {
+		t.Errorf("Text() = %q", got)
+	}
+}
+
+type testAccount struct {
+	ID     string `json:"id"`
+	ItemID string `json:"itemId"`
+	Name   string `json:"name"`
+	Bill   *struct {
+		BillID string `json:"billId"`
+	} `json:"bill,omitempty"`
+}
+
+type testPage struct {
+	Page    int           `json:"page"`
+	Results []testAccount `json:"results"`
+}
+
+func TestApply(t *testing.T) {
+	enable(t)
+
+	account := testAccount{ID: "acc-uuid-1", ItemID: "item-uuid", Name: "Conta"}
+	account.Bill = &struct {
+		BillID string `json:"billId"`
+	}{"bill-uuid"}
+
+	got, err := Apply("s1", Account, account)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != "acc_1" || got.ItemID != "item_1" || got.Bill.BillID != "bill_1" || got.Name != "Conta" {
+		t.Errorf("Apply(entity) = %+v", got)
+	}
+
+	page, err := Apply("s1", Account, testPage{Page: 1, Results: []testAccount{{ID: "acc-uuid-2", ItemID: "item-uuid"}, {ID: "acc-uuid-1"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Results[0].ID != "acc_2" || page.Results[0].ItemID != "item_1" || page.Results[1].ID != "acc_1" {
+		t.Errorf("Apply(page) = %+v", page)
+	}
+
+	list, err := Apply("s2", Account, []testAccount{{ID: "acc-uuid-2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list[0].ID != "acc_1" {
+		t.Errorf("Apply(list) in another session = %+v, want acc_1", list)
+	}
+
+	// Without a kind only references are replaced.
+	noKind, err := Apply("s1", "", testAccount{ID: "raw", ItemID: "item-uuid"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if noKind.ID != "raw" || noKind.ItemID != "item_1" {
+		t.Errorf("Apply without kind = %+v", noKind)
+	}
+}
+
+func TestText(t *testing.T) {
+	enable(t)
+	Alias("s1", Item, "item-uuid")
+	Alias("s1", Account, "item-uuid-account")
+
+	tests := []struct {
+		session, in, want string
+	}{
+		{"s1", "GET /items/item-uuid failed", "GET /items/item_1 failed"},
+		{"s1", "account item-uuid-account of item-uuid", "account acc_1 of item_1"},
+		{"s2", "GET /items/item-uuid failed", "GET /items/item-uuid failed"},
+	}
+	for _, tt := range tests {
+		if got := Text(tt.session, tt.in); got != tt.want {
+			t.Errorf("Text(%s, %q) = %q, want %q", tt.session, tt.in, got, tt.want)
+		}
+	}
+
+	// The cached replacer is rebuilt once the session learns an identifier.
+	Alias("s1", Bill, "bill-uuid")
+	if got := Text("s1", "bill-uuid of item-uuid"); got != "bill_1 of item_1" {
+		t.Errorf("Text() after a new alias = %q", got)
+	}
+}
+
+func TestExpire(t *testing.T) {
+	enable(t)
+	Alias("old", Account, "acc-uuid")
+	Alias("recent", Account, "acc-uuid")
+
+	store.mu.Lock()
+	store.sessions["old"].lastUsed = time.Now().Add(-sessionTTL - time.Minute)
+	store.mu.Unlock()
+
+	store.expire(time.Now())
+
+	if _, err := Resolve("old", "acc_1"); err == nil || !strings.Contains(err.Error(), "another session") {
+		t.Errorf("the idle session should have been dropped, Resolve error = %v", err)
+	}
+	if got, err := Resolve("recent", "acc_1"); err != nil || got != "acc-uuid" {
+		t.Errorf("the recent session should be kept, Resolve = %q, %v", got, err)
+	}
+}
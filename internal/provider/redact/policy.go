@@ -0,0 +1,140 @@
+package redact
+
+import (
+	"crypto/rand"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// FieldType is a kind of personal data found in Pluggy payloads.
+type FieldType string
+
+const (
+	FieldName          FieldType = "name"           // payer, receiver and owner names
+	FieldDocument      FieldType = "document"       // CPF/CNPJ of account holders and counterparties
+	FieldAccountNumber FieldType = "account_number" // account, branch, routing and card numbers
+)
+
+var fieldTypes = []FieldType{FieldName, FieldDocument, FieldAccountNumber}
+
+type Action string
+
+const (
+	ActionKeep Action = "keep" // the value as is
+	ActionMask Action = "mask" // only the last digits or the initials
+	ActionHash Action = "hash" // a keyed hash, equal values still match
+	ActionDrop Action = "drop" // the field is removed
+)
+
+var presets = map[string]map[FieldType]Action{
+	"off": {
+		FieldName:          ActionKeep,
+		FieldDocument:      ActionKeep,
+		FieldAccountNumber: ActionKeep,
+	},
+	// Holder names are kept, as over stdio the model works for the account
+	// holder, who already knows them.
+	"standard": {
+		FieldName:          ActionKeep,
+		FieldDocument:      ActionMask,
+		FieldAccountNumber: ActionMask,
+	},
+	"strict": {
+		FieldName:          ActionHash,
+		FieldDocument:      ActionHash,
+		FieldAccountNumber: ActionHash,
+	},
+}
+
+// Policy tells what to do with each field type, globally and per tool.
+type Policy struct {
+	Preset string
+	fields map[FieldType]Action
+	tools  map[string]map[FieldType]Action
+	salt   []byte
+}
+
+// NewPolicy builds a policy from a preset (off, standard or strict) and
+// comma separated rules overriding it, either for every tool
+// ("document=drop") or for one ("get_account_details.account_number=keep").
+// Hashes are keyed with salt, or with a random key when it is empty, in which
+// case they only match within the same process. Mask and hash only apply to
+// string values; other values of a masked or hashed field are dropped.
+func NewPolicy(preset, rules, salt string) (*Policy, error) {
+	base, ok := presets[preset]
+	if !ok {
+		return nil, fmt.Errorf("unknown redaction preset %q, expected off, standard or strict", preset)
+	}
+
+	p := &Policy{
+		Preset: preset,
+		fields: make(map[FieldType]Action, len(base)),
+		tools:  map[string]map[FieldType]Action{},
+		salt:   []byte(salt),
+	}
+	for field, action := range base {
+		p.fields[field] = action
+	}
+
+	if len(p.salt) == 0 {
+		p.salt = make([]byte, 32)
+		if _, err := rand.Read(p.salt); err != nil {
+			return nil, fmt.Errorf("error generating redaction salt: %w", err)
+		}
+	}
+
+	for _, rule := range strings.Split(rules, ",") {
+		if rule = strings.TrimSpace(rule); rule == "" {
+			continue
+		}
+		if err := p.addRule(rule); err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+func (p *Policy) addRule(rule string) error {
+	target, value, ok := strings.Cut(rule, "=")
+	if !ok {
+		return fmt.Errorf("invalid redaction rule %q, expected [tool.]field=action", rule)
+	}
+
+	action := Action(strings.TrimSpace(value))
+	switch action {
+	case ActionKeep, ActionMask, ActionHash, ActionDrop:
+	default:
+		return fmt.Errorf("invalid redaction action %q in rule %q, expected keep, mask, hash or drop", action, rule)
+	}
+
+	tool, field := "", strings.TrimSpace(target)
+	if i := strings.LastIndex(field, "."); i >= 0 {
+		tool, field = field[:i], field[i+1:]
+	}
+	if !isFieldType(FieldType(field)) {
+		return fmt.Errorf("invalid redaction field %q in rule %q, expected name, document or account_number", field, rule)
+	}
+
+	if tool == "" {
+		p.fields[FieldType(field)] = action
+		return nil
+	}
+	if p.tools[tool] == nil {
+		p.tools[tool] = map[FieldType]Action{}
+	}
+	p.tools[tool][FieldType(field)] = action
+	return nil
+}
+
+func (p *Policy) action(scope string, field FieldType) Action {
+	if action, ok := p.tools[scope][field]; ok {
+		return action
+	}
+	return p.fields[field]
+}
+
+func isFieldType(field FieldType) bool {
+	return slices.Contains(fieldTypes, field)
+}
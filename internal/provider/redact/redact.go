@@ -0,0 +1,157 @@
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// fields maps JSON keys to the personal data they hold wherever they appear.
+var fields = map[string]FieldType{
+	"taxNumber":      FieldDocument,
+	"accountNumber":  FieldAccountNumber,
+	"branchNumber":   FieldAccountNumber,
+	"routingNumber":  FieldAccountNumber,
+	"transferNumber": FieldAccountNumber,
+	"cardNumber":     FieldAccountNumber,
+	"owner":          FieldName,
+	"client_user_id": FieldName, // tool argument, kept in the audit trail
+}
+
+// nestedFields are only personal data below the given parent key, where the
+// generic field name would otherwise match too much.
+var nestedFields = map[string]map[string]FieldType{
+	"payer":          {"name": FieldName},
+	"receiver":       {"name": FieldName},
+	"documentNumber": {"value": FieldDocument},
+}
+
+var policy, _ = NewPolicy("standard", "", "")
+
+// SetPolicy replaces the policy applied by Apply and JSON, which is the
+// standard preset until then.
+func SetPolicy(p *Policy) {
+	policy = p
+}
+
+// Apply returns a copy of v with personal data redacted as the policy says
+// for scope, the tool or resource producing it.
+func Apply[T any](scope string, v T) (T, error) {
+	var out T
+	data, err := JSON(scope, v)
+	if err != nil {
+		return out, err
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return out, fmt.Errorf("redact: error decoding redacted value: %w", err)
+	}
+	return out, nil
+}
+
+// JSON marshals v with personal data redacted as the policy says for scope.
+func JSON(scope string, v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, fmt.Errorf("redact: error decoding value: %w", err)
+	}
+
+	return json.Marshal(policy.value(scope, "", generic))
+}
+
+func (p *Policy) value(scope, parent string, v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		// Pluggy accounts and investments carry their number in a plain
+		// "number" field, told apart by the itemId and subtype next to it.
+		_, hasItem := val["itemId"]
+		_, hasSubtype := val["subtype"]
+
+		for k, child := range val {
+			field, ok := fields[k]
+			if !ok {
+				field, ok = nestedFields[parent][k]
+			}
+			if !ok && k == "number" && hasItem && hasSubtype {
+				field, ok = FieldAccountNumber, true
+			}
+
+			switch {
+			case child == nil:
+			case ok:
+				if redacted, keep := p.redact(scope, field, child); keep {
+					val[k] = redacted
+				} else {
+					delete(val, k)
+				}
+			default:
+				val[k] = p.value(scope, k, child)
+			}
+		}
+		return val
+	case []any:
+		for i, child := range val {
+			val[i] = p.value(scope, parent, child)
+		}
+		return val
+	default:
+		return v
+	}
+}
+
+// redact returns the redacted value, or false when the field must be dropped.
+// Only strings are masked or hashed: any other value would come back as a
+// string that no longer decodes into the entity Apply returns, so it is
+// dropped instead.
+func (p *Policy) redact(scope string, field FieldType, v any) (any, bool) {
+	action := p.action(scope, field)
+	s, ok := v.(string)
+	switch {
+	case action == ActionKeep:
+		return v, true
+	case action == ActionDrop || !ok:
+		return nil, false
+	case s == "":
+		return v, true
+	}
+
+	switch action {
+	case ActionHash:
+		return p.hash(s), true
+	default:
+		return mask(field, s), true
+	}
+}
+
+func (p *Policy) hash(s string) string {
+	mac := hmac.New(sha256.New, p.salt)
+	mac.Write([]byte(s))
+	return "hash_" + hex.EncodeToString(mac.Sum(nil))[:12]
+}
+
+// mask keeps the initials of names and the last digits of numbers.
+func mask(field FieldType, s string) string {
+	if field == FieldName {
+		words := strings.Fields(s)
+		for i, word := range words {
+			r, _ := utf8.DecodeRuneInString(word)
+			words[i] = string(r) + "***"
+		}
+		return strings.Join(words, " ")
+	}
+
+	runes := []rune(s)
+	visible := 4
+	if len(runes) <= 6 {
+		visible = len(runes) / 3
+	}
+	return "***" + string(runes[len(runes)-visible:])
+}
@@ -0,0 +1,263 @@
+package redact
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/pluggy"
+)
+
+func TestNewPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		preset  string
+		rules   string
+		want    map[string]Action // "[scope.]field" -> action
+		wantErr string
+	}{
+		{
+			name:   "standard",
+			preset: "standard",
+			want:   map[string]Action{"name": ActionKeep, "document": ActionMask, "account_number": ActionMask},
+		},
+		{
+			name:   "global and tool rules",
+			preset: "strict",
+			rules:  " name=mask , get_account_details.account_number=keep",
+			want: map[string]Action{
+				"name":                               ActionMask,
+				"document":                           ActionHash,
+				"account_number":                     ActionHash,
+				"get_account_details.account_number": ActionKeep,
+				"get_account_details.document":       ActionHash,
+			},
+		},
+		{name: "unknown preset", preset: "lax", wantErr: `unknown redaction preset "lax"`},
+		{name: "no action", preset: "off", rules: "name", wantErr: `invalid redaction rule "name"`},
+		{name: "unknown action", preset: "off", rules: "name=blur", wantErr: `invalid redaction action "blur"`},
+		{name: "unknown field", preset: "off", rules: "tool.email=drop", wantErr: `invalid redaction field "email"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewPolicy(tt.preset, tt.rules, "salt")
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for target, want := range tt.want {
+				scope, field := "", target
+				if i := strings.LastIndex(target, "."); i >= 0 {
+					scope, field = target[:i], target[i+1:]
+				}
+				if got := p.action(scope, FieldType(field)); got != want {
+					t.Errorf("action(%q) = %s, want %s", target, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestJSON(t *testing.T) {
+	input := map[string]any{
+		"itemId":  "item-1",
+		"subtype": "CHECKING_ACCOUNT",
+		"number":  "0001/12345-6",
+		"name":    "Conta Corrente",
+		"owner":   "Maria da Silva",
+		"paymentData": map[string]any{
+			"payer":    map[string]any{"name": "Joao Souza", "documentNumber": map[string]any{"type": "CPF", "value": "12345678909"}},
+			"receiver": map[string]any{"name": "Padaria Central", "accountNumber": 123456},
+		},
+		"taxNumber":  "",
+		"cardNumber": nil,
+	}
+
+	tests := []struct {
+		name   string
+		preset string
+		rules  string
+		want   map[string]any // dotted path -> value, nil when dropped
+	}{
+		{
+			name:   "standard",
+			preset: "standard",
+			want: map[string]any{
+				"number":                                 "***45-6",
+				"name":                                   "Conta Corrente",
+				"owner":                                  "Maria da Silva",
+				"paymentData.payer.name":                 "Joao Souza",
+				"paymentData.payer.documentNumber.value": "***8909",
+				"paymentData.payer.documentNumber.type":  "CPF",
+				"paymentData.receiver.accountNumber":     nil,
+				"taxNumber":                              "",
+			},
+		},
+		{
+			name:   "mask names",
+			preset: "standard",
+			rules:  "name=mask",
+			want:   map[string]any{"owner": "M*** d*** S***", "paymentData.payer.name": "J*** S***", "name": "Conta Corrente"},
+		},
+		{
+			name:   "drop",
+			preset: "off",
+			rules:  "document=drop,account_number=drop",
+			want:   map[string]any{"number": nil, "paymentData.payer.documentNumber.value": nil, "owner": "Maria da Silva"},
+		},
+		{
+			name:   "keep numbers as they are",
+			preset: "off",
+			want:   map[string]any{"paymentData.receiver.accountNumber": float64(123456), "number": "0001/12345-6"},
+		},
+		{
+			name:   "other tool",
+			preset: "standard",
+			rules:  "get_account_details.account_number=keep",
+			want:   map[string]any{"number": "***45-6"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewPolicy(tt.preset, tt.rules, "salt")
+			if err != nil {
+				t.Fatal(err)
+			}
+			SetPolicy(p)
+
+			data, err := JSON("get_accounts", input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got map[string]any
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatal(err)
+			}
+			for path, want := range tt.want {
+				if value, _ := lookup(got, path); !reflect.DeepEqual(value, want) {
+					t.Errorf("%s = %#v, want %#v", path, value, want)
+				}
+			}
+		})
+	}
+}
+
+func TestHash(t *testing.T) {
+	p, _ := NewPolicy("strict", "", "salt")
+	other, _ := NewPolicy("strict", "", "pepper")
+
+	if p.hash("Maria") != p.hash("Maria") {
+		t.Errorf("equal values should hash the same")
+	}
+	if p.hash("Maria") == p.hash("Joao") {
+		t.Errorf("different values should hash differently")
+	}
+	if p.hash("Maria") == other.hash("Maria") {
+		t.Errorf("hashes should depend on the salt")
+	}
+	if h := p.hash("Maria"); !strings.HasPrefix(h, "hash_") || len(h) != len("hash_")+12 {
+		t.Errorf("hash = %q, want hash_ and 12 hex digits", h)
+	}
+}
+
+func TestMask(t *testing.T) {
+	tests := []struct {
+		field FieldType
+		in    string
+		want  string
+	}{
+		{FieldName, "Maria da Silva", "M*** d*** S***"},
+		{FieldName, "Élio", "É***"},
+		{FieldDocument, "123.456.789-09", "***9-09"},
+		{FieldAccountNumber, "1234", "***4"},
+		{FieldAccountNumber, "12", "***"},
+	}
+	for _, tt := range tests {
+		if got := mask(tt.field, tt.in); got != tt.want {
+			t.Errorf("mask(%s, %q) = %q, want %q", tt.field, tt.in, got, tt.want)
+		}
+	}
+}
+
+// Apply decodes the redacted value back into the entity, so every strict
+// redaction of Pluggy entities has to decode.
+func TestApplyPluggyEntities(t *testing.T) {
+	p, _ := NewPolicy("strict", "", "salt")
+	SetPolicy(p)
+
+	var account pluggy.Account
+	if err := json.Unmarshal([]byte(`{"id":"acc-1","itemId":"item-1","subtype":"CHECKING_ACCOUNT","number":"0001/12345-6","taxNumber":"12345678909","owner":"Maria"}`), &account); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Apply("get_account_details", account)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(got.Number, "hash_") || !strings.HasPrefix(got.TaxNumber, "hash_") {
+		t.Errorf("account = %+v, want hashed number and tax number", got)
+	}
+
+	var investment pluggy.Investment
+	if err := json.Unmarshal([]byte(`{"id":"inv-1","itemId":"item-1","subtype":"CDB","number":12345,"owner":"Maria"}`), &investment); err != nil {
+		t.Fatal(err)
+	}
+	inv, err := Apply("get_item_investments", investment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inv.Number != nil || !strings.HasPrefix(inv.Owner, "hash_") {
+		t.Errorf("investment number = %v, owner = %q; want the number dropped and the owner hashed", inv.Number, inv.Owner)
+	}
+}
+
+func lookup(v map[string]any, path string) (any, bool) {
+	var cur any = v
+	for _, key := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// Masked and hashed values come back as strings, so every redacted field of
+// the Pluggy entities has to hold a string for Apply to decode them.
+func TestRedactedFieldsAreStrings(t *testing.T) {
+	var walk func(typ reflect.Type, parent, path string)
+	walk = func(typ reflect.Type, parent, path string) {
+		for typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Slice {
+			typ = typ.Elem()
+		}
+		if typ.Kind() != reflect.Struct || (typ.Name() != "" && typ.PkgPath() != reflect.TypeOf(pluggy.Account{}).PkgPath()) {
+			return
+		}
+		for i := range typ.NumField() {
+			f := typ.Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			_, redacted := fields[name]
+			if _, ok := nestedFields[parent][name]; ok {
+				redacted = true
+			}
+			if name == "number" {
+				redacted = true
+			}
+			if redacted && f.Type.Kind() != reflect.String && f.Type.Kind() != reflect.Interface {
+				t.Errorf("%s.%s is a %s, redacted fields must be strings", path, name, f.Type)
+			}
+			walk(f.Type, name, path+"."+name)
+		}
+	}
+	for _, v := range []any{pluggy.Account{}, pluggy.Transaction{}, pluggy.Investment{}, pluggy.Bill{}, pluggy.Item{}} {
+		walk(reflect.TypeOf(v), "", reflect.TypeOf(v).Name())
+	}
+}
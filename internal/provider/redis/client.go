@@ -2,7 +2,6 @@ package redis
 
 import (
 	"fmt"
-	"os"
 	"sync"
 
 	"github.com/redis/go-redis/v9"
@@ -10,21 +9,34 @@ import (
 
 type Client = redis.Client
 
-var instance *redis.Client
+// Options tell where Redis is.
+type Options struct {
+	Host     string
+	Port     int
+	Password string
+	DB       int
+}
+
+var (
+	instance *redis.Client
+	once     sync.Once
+	options  = Options{Host: "127.0.0.1", Port: 6379}
+)
+
+// Configure sets the options Instance connects with. It has to be called
+// before the first call to Instance.
+func Configure(opts Options) {
+	options = opts
+}
 
 func Instance() *redis.Client {
-	var once sync.Once
 	once.Do(func() {
-		host := os.Getenv("REDIS_HOST")
-		if host == "" {
-			host = "127.0.0.1"
-		}
-
 		instance = redis.NewClient(&redis.Options{
-			Addr:     fmt.Sprintf("%s:%d", host, 6379),
-			Password: os.Getenv("REDIS_PASSWORD"),
-			DB:       0,
+			Addr:     fmt.Sprintf("%s:%d", options.Host, options.Port),
+			Password: options.Password,
+			DB:       options.DB,
 		})
+		instance.AddHook(tracingHook{})
 	})
 	return instance
 }
@@ -0,0 +1,37 @@
+package redis
+
+import (
+	"context"
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/tracing"
+)
+
+// tracingHook makes every Redis command run within a traced call a span.
+type tracingHook struct{}
+
+func (tracingHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (tracingHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		ctx, span := tracing.Start(ctx, "redis "+cmd.Name(), tracing.KindClient)
+		defer span.End()
+		span.SetAttributes("db.system", "redis", "db.operation", cmd.Name())
+
+		err := next(ctx, cmd)
+		if !errors.Is(err, redis.Nil) {
+			span.SetError(err)
+		}
+		return err
+	}
+}
+
+func (tracingHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+var _ redis.Hook = tracingHook{}
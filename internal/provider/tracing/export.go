@@ -0,0 +1,264 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/logger"
+)
+
+const (
+	batchSize     = 256
+	flushInterval = 5 * time.Second
+	queueLimit    = 4096
+
+	shutdownTimeout = 5 * time.Second
+)
+
+// Options tell where spans are exported to.
+type Options struct {
+	Endpoint    string // OTLP/HTTP collector, e.g. http://localhost:4318; empty disables tracing
+	ServiceName string
+}
+
+// exporter batches ended spans and posts them to an OTLP/HTTP collector as
+// JSON.
+type exporter struct {
+	url     string
+	service string
+	client  *http.Client
+
+	mu    sync.Mutex
+	queue []*Span
+	kick  chan struct{}
+	done  chan struct{}
+	stop  chan struct{}
+}
+
+var (
+	exporterMu sync.RWMutex
+	active     *exporter
+)
+
+func current() *exporter {
+	exporterMu.RLock()
+	defer exporterMu.RUnlock()
+	return active
+}
+
+// Init starts exporting spans as opts say. Without an endpoint, spans are
+// not even created.
+func Init(opts Options) {
+	if opts.Endpoint == "" {
+		return
+	}
+
+	exp := newExporter(opts)
+	go exp.run()
+
+	exporterMu.Lock()
+	active = exp
+	exporterMu.Unlock()
+	logger.Info("[tracing] exporting spans", "url", exp.url)
+}
+
+func newExporter(opts Options) *exporter {
+	return &exporter{
+		url:     strings.TrimSuffix(opts.Endpoint, "/") + "/v1/traces",
+		service: opts.ServiceName,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		kick:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		stop:    make(chan struct{}),
+	}
+}
+
+// Shutdown exports the spans still queued, waiting at most shutdownTimeout,
+// and stops the exporter.
+func Shutdown() {
+	exporterMu.Lock()
+	exp := active
+	active = nil
+	exporterMu.Unlock()
+	if exp == nil {
+		return
+	}
+
+	close(exp.stop)
+	select {
+	case <-exp.done:
+	case <-time.After(shutdownTimeout):
+	}
+}
+
+func (e *exporter) add(s *Span) {
+	e.mu.Lock()
+	if len(e.queue) >= queueLimit {
+		// A collector that is down must not grow the queue forever.
+		e.mu.Unlock()
+		droppedSpans.Inc("queue_full")
+		return
+	}
+	e.queue = append(e.queue, s)
+	full := len(e.queue) >= batchSize
+	e.mu.Unlock()
+
+	if full {
+		select {
+		case e.kick <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (e *exporter) run() {
+	defer close(e.done)
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-e.kick:
+		case <-e.stop:
+			e.flush()
+			return
+		}
+		e.flush()
+	}
+}
+
+func (e *exporter) flush() {
+	e.mu.Lock()
+	spans := e.queue
+	e.queue = nil
+	e.mu.Unlock()
+
+	for len(spans) > 0 {
+		n := min(len(spans), batchSize)
+		if err := e.post(spans[:n]); err != nil {
+			logger.Warn("[tracing] error exporting spans", "spans", n, "error", err)
+			droppedSpans.Add(float64(n), "export_failed")
+		}
+		spans = spans[n:]
+	}
+}
+
+func (e *exporter) post(spans []*Span) error {
+	body, err := json.Marshal(e.payload(spans))
+	if err != nil {
+		return err
+	}
+
+	res, err := e.client.Post(e.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("collector answered %s", res.Status)
+	}
+	return nil
+}
+
+// The OTLP JSON encoding of a trace export request.
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpSpan struct {
+		TraceID           string          `json:"traceId"`
+		SpanID            string          `json:"spanId"`
+		ParentSpanID      string          `json:"parentSpanId,omitempty"`
+		Name              string          `json:"name"`
+		Kind              SpanKind        `json:"kind"`
+		StartTimeUnixNano string          `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+		Attributes        []otlpAttribute `json:"attributes,omitempty"`
+		Status            otlpStatus      `json:"status"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code"` // 1 ok, 2 error
+		Message string `json:"message,omitempty"`
+	}
+	otlpAttribute struct {
+		Key   string         `json:"key"`
+		Value map[string]any `json:"value"`
+	}
+)
+
+func (e *exporter) payload(spans []*Span) otlpRequest {
+	out := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		s.mu.Lock()
+		span := otlpSpan{
+			TraceID:           hex.EncodeToString(s.traceID[:]),
+			SpanID:            hex.EncodeToString(s.spanID[:]),
+			Name:              s.name,
+			Kind:              s.kind,
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+			Status:            otlpStatus{Code: 1},
+		}
+		if s.parent != [8]byte{} {
+			span.ParentSpanID = hex.EncodeToString(s.parent[:])
+		}
+		for _, a := range s.attrs {
+			span.Attributes = append(span.Attributes, otlpAttr(a.key, a.value))
+		}
+		if s.failed {
+			span.Status = otlpStatus{Code: 2, Message: logger.Scrub(s.errorMsg)}
+		}
+		s.mu.Unlock()
+		out = append(out, span)
+	}
+
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource: otlpResource{Attributes: []otlpAttribute{otlpAttr("service.name", e.service)}},
+		ScopeSpans: []otlpScopeSpans{{
+			Scope: otlpScope{Name: "github.com/thunderjr/openfinance-mcp-server"},
+			Spans: out,
+		}},
+	}}}
+}
+
+func otlpAttr(key string, v any) otlpAttribute {
+	var value map[string]any
+	switch v := v.(type) {
+	case string:
+		value = map[string]any{"stringValue": v}
+	case bool:
+		value = map[string]any{"boolValue": v}
+	case int:
+		value = map[string]any{"intValue": strconv.Itoa(v)}
+	case int64:
+		value = map[string]any{"intValue": strconv.FormatInt(v, 10)}
+	case float64:
+		value = map[string]any{"doubleValue": v}
+	default:
+		value = map[string]any{"stringValue": fmt.Sprint(v)}
+	}
+	return otlpAttribute{Key: key, Value: value}
+}
@@ -0,0 +1,176 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/thunderjr/openfinance-mcp-server/internal/provider/metrics"
+)
+
+func TestPayload(t *testing.T) {
+	start := time.Unix(1700000000, 5)
+	span := func(parent [8]byte, attrs []attribute, err error) *Span {
+		s := &Span{
+			traceID: [16]byte{0x0a, 15: 0x0b},
+			spanID:  [8]byte{0x01, 7: 0x02},
+			parent:  parent,
+			name:    "get_accounts",
+			kind:    KindServer,
+			start:   start,
+			end:     start.Add(time.Second),
+			attrs:   attrs,
+		}
+		s.SetError(err)
+		return s
+	}
+
+	tests := []struct {
+		name string
+		span *Span
+		want string
+	}{
+		{
+			name: "root",
+			span: span([8]byte{}, nil, nil),
+			want: `{"traceId":"0a00000000000000000000000000000b","spanId":"0100000000000002","name":"get_accounts","kind":2,"startTimeUnixNano":"1700000000000000005","endTimeUnixNano":"1700000001000000005","status":{"code":1}}`,
+		},
+		{
+			name: "child with attributes",
+			span: span([8]byte{0x03}, []attribute{{"s", "x"}, {"b", true}, {"i", 3}, {"i64", int64(4)}, {"f", 1.5}, {"d", time.Second}}, nil),
+			want: `{"traceId":"0a00000000000000000000000000000b","spanId":"0100000000000002","parentSpanId":"0300000000000000","name":"get_accounts","kind":2,"startTimeUnixNano":"1700000000000000005","endTimeUnixNano":"1700000001000000005",` +
+				`"attributes":[{"key":"s","value":{"stringValue":"x"}},{"key":"b","value":{"boolValue":true}},{"key":"i","value":{"intValue":"3"}},{"key":"i64","value":{"intValue":"4"}},{"key":"f","value":{"doubleValue":1.5}},{"key":"d","value":{"stringValue":"1s"}}],"status":{"code":1}}`,
+		},
+		{
+			name: "failed with a scrubbed message",
+			span: span([8]byte{}, nil, errors.New(`pluggy answered {"apiKey":"secret"}`)),
+			want: `{"traceId":"0a00000000000000000000000000000b","spanId":"0100000000000002","name":"get_accounts","kind":2,"startTimeUnixNano":"1700000000000000005","endTimeUnixNano":"1700000001000000005","status":{"code":2,"message":"pluggy answered {\"apiKey\":\"[REDACTED]\"}"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exp := newExporter(Options{Endpoint: "http://collector", ServiceName: "test-service"})
+			body, err := json.Marshal(exp.payload([]*Span{tt.span}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := `{"resourceSpans":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"test-service"}}]},` +
+				`"scopeSpans":[{"scope":{"name":"github.com/thunderjr/openfinance-mcp-server"},"spans":[` + tt.want + `]}]}]}`
+			if string(body) != want {
+				t.Errorf("payload:\n%s\nwant:\n%s", body, want)
+			}
+		})
+	}
+}
+
+// collector records the spans posted to it and answers with status.
+type collector struct {
+	status int
+
+	mu    sync.Mutex
+	spans []map[string]any
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ResourceSpans []struct {
+			ScopeSpans []struct {
+				Spans []map[string]any `json:"spans"`
+			} `json:"scopeSpans"`
+		} `json:"resourceSpans"`
+	}
+	body, _ := io.ReadAll(r.Body)
+	if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/json" || json.Unmarshal(body, &req) != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	c.mu.Lock()
+	for _, rs := range req.ResourceSpans {
+		for _, ss := range rs.ScopeSpans {
+			c.spans = append(c.spans, ss.Spans...)
+		}
+	}
+	c.mu.Unlock()
+	w.WriteHeader(c.status)
+}
+
+// dropped reads the count of spans dropped for reason from the metrics.
+func dropped(t *testing.T, reason string) float64 {
+	t.Helper()
+	var out strings.Builder
+	metrics.Write(&out)
+	prefix := `openfinance_tracing_spans_dropped_total{reason="` + reason + `"} `
+	for _, line := range strings.Split(out.String(), "\n") {
+		if v, ok := strings.CutPrefix(line, prefix); ok {
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return n
+		}
+	}
+	return 0
+}
+
+func TestExport(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		wantSpans   int
+		wantDropped float64
+	}{
+		{name: "accepted", status: http.StatusOK, wantSpans: 2},
+		{name: "collector failing", status: http.StatusServiceUnavailable, wantDropped: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &collector{status: tt.status}
+			server := httptest.NewServer(c)
+			defer server.Close()
+			before := dropped(t, "export_failed")
+
+			Init(Options{Endpoint: server.URL + "/", ServiceName: "test"})
+			ctx, root := StartRoot(context.Background(), "tool", KindServer)
+			_, child := Start(ctx, "GET /accounts", KindClient)
+			child.End()
+			root.End()
+			Shutdown()
+
+			if got := dropped(t, "export_failed") - before; got != tt.wantDropped {
+				t.Errorf("dropped %v spans, want %v", got, tt.wantDropped)
+			}
+			if tt.wantSpans == 0 {
+				return
+			}
+			if len(c.spans) != tt.wantSpans {
+				t.Fatalf("collector got %d spans, want %d", len(c.spans), tt.wantSpans)
+			}
+			got, parent := c.spans[0], c.spans[1]
+			if got["traceId"] != parent["traceId"] || got["parentSpanId"] != parent["spanId"] || parent["parentSpanId"] != nil {
+				t.Errorf("spans = %v, want the request under the tool call", c.spans)
+			}
+		})
+	}
+}
+
+func TestQueueLimit(t *testing.T) {
+	exp := newExporter(Options{Endpoint: "http://collector"})
+	before := dropped(t, "queue_full")
+	for range queueLimit + 3 {
+		exp.add(&Span{})
+	}
+	if len(exp.queue) != queueLimit {
+		t.Errorf("queue holds %d spans, want %d", len(exp.queue), queueLimit)
+	}
+	if got := dropped(t, "queue_full") - before; got != 3 {
+		t.Errorf("dropped %v spans, want 3", got)
+	}
+}
@@ -0,0 +1,6 @@
+package tracing
+
+import "github.com/thunderjr/openfinance-mcp-server/internal/provider/metrics"
+
+var droppedSpans = metrics.NewCounter("openfinance_tracing_spans_dropped_total",
+	"Ended spans that never reached the collector by reason (queue_full when the export queue was full, export_failed when the collector did not take them).", "reason")
@@ -0,0 +1,115 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+type SpanKind int
+
+// Span kinds as numbered by OTLP.
+const (
+	KindInternal SpanKind = 1
+	KindServer   SpanKind = 2
+	KindClient   SpanKind = 3
+)
+
+// Span is one timed operation of a trace. A nil *Span is a disabled span,
+// so callers never check whether tracing is on.
+type Span struct {
+	traceID [16]byte
+	spanID  [8]byte
+	parent  [8]byte
+	name    string
+	kind    SpanKind
+	start   time.Time
+
+	mu       sync.Mutex
+	end      time.Time
+	attrs    []attribute
+	failed   bool
+	errorMsg string
+}
+
+type attribute struct {
+	key   string
+	value any
+}
+
+type spanKey struct{}
+
+// StartRoot starts a new trace, such as for an MCP tool call. It returns a
+// nil span when tracing is disabled.
+func StartRoot(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	if exp := current(); exp == nil {
+		return ctx, nil
+	}
+
+	span := &Span{name: name, kind: kind, start: time.Now()}
+	rand.Read(span.traceID[:])
+	rand.Read(span.spanID[:])
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// Start starts a child of the span in ctx. Work done outside a trace, such
+// as at startup, is not traced: without a parent it returns a nil span.
+func Start(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	parent, _ := ctx.Value(spanKey{}).(*Span)
+	if parent == nil || current() == nil {
+		return ctx, nil
+	}
+
+	span := &Span{traceID: parent.traceID, parent: parent.spanID, name: name, kind: kind, start: time.Now()}
+	rand.Read(span.spanID[:])
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// SetAttributes adds key/value pairs, with string, bool, integer or float
+// values.
+func (s *Span) SetAttributes(kv ...any) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i+1 < len(kv); i += 2 {
+		if key, ok := kv[i].(string); ok {
+			s.attrs = append(s.attrs, attribute{key, kv[i+1]})
+		}
+	}
+}
+
+// SetError marks the span as failed when err is not nil.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failed, s.errorMsg = true, err.Error()
+}
+
+// End finishes the span and queues it for export.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.end = time.Now()
+	s.mu.Unlock()
+
+	if exp := current(); exp != nil {
+		exp.add(s)
+	}
+}
+
+// Hash pseudonymizes an identifier for a span attribute, so traces can be
+// correlated by item without holding the ID.
+func Hash(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:6])
+}
@@ -0,0 +1,68 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestDisabled(t *testing.T) {
+	tests := []struct {
+		name  string
+		start func() *Span
+	}{
+		{"root without an exporter", func() *Span {
+			_, s := StartRoot(context.Background(), "tool", KindServer)
+			return s
+		}},
+		{"child without a parent", func() *Span {
+			active = newExporter(Options{Endpoint: "http://collector"})
+			defer func() { active = nil }()
+			_, s := Start(context.Background(), "GET /items", KindClient)
+			return s
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := tt.start()
+			if s != nil {
+				t.Fatalf("span = %+v, want nil", s)
+			}
+			// A nil span takes every call.
+			s.SetAttributes("k", "v")
+			s.SetError(errors.New("failed"))
+			s.End()
+		})
+	}
+}
+
+func TestSpan(t *testing.T) {
+	exp := newExporter(Options{Endpoint: "http://collector"})
+	active = exp
+	defer func() { active = nil }()
+
+	ctx, root := StartRoot(context.Background(), "tool", KindServer)
+	_, child := Start(ctx, "redis get", KindClient)
+	child.SetAttributes("db.system", "redis", 42, "ignored", "odd")
+	child.SetError(nil)
+	child.End()
+
+	if child.traceID != root.traceID || child.parent != root.spanID || child.spanID == root.spanID {
+		t.Errorf("child = %x/%x under %x, want it in the root's trace under the root", child.traceID, child.parent, root.spanID)
+	}
+	if len(child.attrs) != 1 || child.attrs[0] != (attribute{"db.system", "redis"}) {
+		t.Errorf("attrs = %v, want only the string keyed pair", child.attrs)
+	}
+	if child.failed || child.end.IsZero() {
+		t.Errorf("child failed = %t, ended = %v; want a successful ended span", child.failed, child.end)
+	}
+	if len(exp.queue) != 1 || exp.queue[0] != child {
+		t.Errorf("queue = %v, want the ended child", exp.queue)
+	}
+}
+
+func TestHash(t *testing.T) {
+	if a, b := Hash("item-1"), Hash("item-1"); a != b || len(a) != 12 || a == Hash("item-2") {
+		t.Errorf("Hash = %q, %q; want stable 12 character hashes", a, b)
+	}
+}